@@ -0,0 +1,344 @@
+// Package approval puts a person in the loop before costly write tool calls.
+// Clients that support MCP elicitation are asked to confirm a summary of the
+// change on the spot; for other clients the call is parked in a queue until
+// someone approves or rejects it, or it expires.
+package approval
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mcp-bedrock-go/auth"
+	"mcp-bedrock-go/format"
+	"mcp-bedrock-go/internal/logging"
+	"mcp-bedrock-go/odoo"
+)
+
+var logger = logging.For("approval")
+
+// Status of a queued action.
+type Status string
+
+const (
+	Pending  Status = "pending"
+	Approved Status = "approved"
+	Rejected Status = "rejected"
+	Expired  Status = "expired"
+)
+
+// Action is a tool call waiting for a decision.
+type Action struct {
+	ID        string         `json:"id"`
+	Tool      string         `json:"tool"`
+	Summary   string         `json:"summary"`
+	Args      map[string]any `json:"args"`
+	Requester string         `json:"requester,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	ExpiresAt time.Time      `json:"expires_at"`
+	Status    Status         `json:"status"`
+	DecidedBy string         `json:"decided_by,omitempty"`
+	Reason    string         `json:"reason,omitempty"`
+
+	run    server.ToolHandlerFunc
+	req    mcp.CallToolRequest
+	client *odoo.Client // the requester's Odoo user, nil for the service account
+}
+
+// Rules says which calls need approval: tool → argument → threshold. A call
+// needs approval when any listed numeric argument reaches its threshold; a
+// tool with no thresholds always needs approval.
+type Rules map[string]map[string]float64
+
+// Needs reports whether the call needs a person to confirm it.
+func (r Rules) Needs(tool string, args map[string]any) bool {
+	th, ok := r[tool]
+	if !ok {
+		return false
+	}
+	if len(th) == 0 {
+		return true
+	}
+	for arg, limit := range th {
+		if v, ok := number(args[arg]); ok && v >= limit {
+			return true
+		}
+	}
+	return false
+}
+
+func number(v any) (float64, bool) {
+	switch t := v.(type) {
+	case float64:
+		return t, true
+	case int:
+		return float64(t), true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(t), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// ErrNotFound is returned for unknown or already decided action IDs.
+var ErrNotFound = errors.New("no pending action with that id")
+
+// Queue gates write tools and holds the actions awaiting approval.
+type Queue struct {
+	Rules             Rules
+	TTL               time.Duration // how long an action waits before it expires
+	AllowSelfApproval bool          // let the requester approve their own action
+
+	// Decided, when set, is told how each action ended: the result of an
+	// approved call, or nil for a rejected, expired or failed one.
+	Decided func(id string, res *mcp.CallToolResult)
+
+	mu      sync.Mutex
+	actions map[string]*Action
+}
+
+// NewQueue creates a queue.
+func NewQueue(rules Rules, ttl time.Duration, allowSelf bool) *Queue {
+	return &Queue{Rules: rules, TTL: ttl, AllowSelfApproval: allowSelf, actions: map[string]*Action{}}
+}
+
+// Middleware confirms gated calls through elicitation when the client
+// supports it and queues them otherwise. Dry runs pass straight through.
+func (q *Queue) Middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+		if req.GetBool("dry_run", false) || !q.Rules.Needs(req.Params.Name, args) {
+			return next(ctx, req)
+		}
+		summary := Summarize(req.Params.Name, args)
+
+		if supportsElicitation(ctx) {
+			ok, err := confirm(ctx, summary)
+			if err == nil {
+				if !ok {
+					return mcp.NewToolResultError("Cancelled: the user did not confirm " + summary), nil
+				}
+				return next(ctx, req)
+			}
+			logger.WarnCtxf(ctx, "approval: elicitation failed, queueing instead: %v", err)
+		}
+
+		a := q.add(ctx, req, next, summary)
+		res := format.Result(map[string]any{
+			"status":     Pending,
+			"action_id":  a.ID,
+			"summary":    a.Summary,
+			"expires_at": a.ExpiresAt,
+			"message":    "This change needs approval. A supervisor can call approve_action or reject_action with this action_id.",
+		})
+		res.Meta = mcp.NewMetaFromMap(map[string]any{"pending_action": a.ID})
+		return res, nil
+	}
+}
+
+func supportsElicitation(ctx context.Context) bool {
+	sess, ok := server.ClientSessionFromContext(ctx).(server.SessionWithClientInfo)
+	return ok && sess.GetClientCapabilities().Elicitation != nil
+}
+
+// confirm asks the user to accept the summary. It returns an error only when
+// the question could not be asked.
+func confirm(ctx context.Context, summary string) (bool, error) {
+	s := server.ServerFromContext(ctx)
+	if s == nil {
+		return false, errors.New("no server in context")
+	}
+	res, err := s.RequestElicitation(ctx, mcp.ElicitationRequest{
+		Params: mcp.ElicitationParams{
+			Message: "Please confirm this change to Odoo:\n" + summary,
+			RequestedSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"confirm": map[string]any{"type": "boolean", "title": "Apply this change"},
+				},
+				"required": []string{"confirm"},
+			},
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	if res.Action != mcp.ElicitationResponseActionAccept {
+		return false, nil
+	}
+	content, _ := res.Content.(map[string]any)
+	yes, _ := content["confirm"].(bool)
+	return yes, nil
+}
+
+// Summarize renders a call as "tool: k=v, k=v" with sorted keys.
+func Summarize(tool string, args map[string]any) string {
+	keys := make([]string, 0, len(args))
+	for k := range args {
+		if k != "dry_run" && k != "idempotency_key" && k != format.Arg {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s=%v", k, args[k])
+	}
+	return tool + ": " + strings.Join(parts, ", ")
+}
+
+func (q *Queue) add(ctx context.Context, req mcp.CallToolRequest, run server.ToolHandlerFunc, summary string) *Action {
+	now := time.Now()
+	a := &Action{
+		ID:        newID(),
+		Tool:      req.Params.Name,
+		Summary:   summary,
+		Args:      req.GetArguments(),
+		CreatedAt: now,
+		ExpiresAt: now.Add(q.TTL),
+		Status:    Pending,
+		run:       run,
+		req:       req,
+		client:    odoo.ClientFromContext(ctx),
+	}
+	if p := auth.FromContext(ctx); p != nil {
+		a.Requester = p.Subject
+	}
+	q.mu.Lock()
+	q.actions[a.ID] = a
+	q.mu.Unlock()
+	logger.InfoCtxf(ctx, "approval: queued %s (%s) for %s", a.ID, a.Summary, a.Requester)
+	return a
+}
+
+// Pending returns the actions still waiting, oldest first.
+func (q *Queue) Pending() []Action {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := time.Now()
+	var out []Action
+	for _, a := range q.actions {
+		if a.Status == Pending && now.Before(a.ExpiresAt) {
+			out = append(out, *a)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out
+}
+
+// take marks a pending action decided and returns it.
+func (q *Queue) take(ctx context.Context, id string, status Status, reason string) (*Action, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	a, ok := q.actions[id]
+	if !ok || a.Status != Pending {
+		return nil, ErrNotFound
+	}
+	if time.Now().After(a.ExpiresAt) {
+		a.Status = Expired
+		q.decided(a.ID, nil)
+		return nil, fmt.Errorf("action %s expired at %s", id, a.ExpiresAt.Format(time.RFC3339))
+	}
+	var who string
+	if p := auth.FromContext(ctx); p != nil {
+		who = p.Subject
+	}
+	if status == Approved && !q.AllowSelfApproval {
+		// without both identities nobody can tell the approver is someone else
+		switch {
+		case a.Requester == "" || who == "":
+			return nil, fmt.Errorf("action %s cannot be approved without authentication: enable auth so the approver can be told apart from the requester, or set approval.allow_self_approval", id)
+		case who == a.Requester:
+			return nil, fmt.Errorf("action %s was requested by %s and needs someone else to approve it", id, who)
+		}
+	}
+	a.Status, a.DecidedBy, a.Reason = status, who, reason
+	return a, nil
+}
+
+// Approve runs the queued call and returns its result. The call runs as the
+// requester's Odoo user, as it would have without approval: the approver
+// allows the change but does not make it.
+func (q *Queue) Approve(ctx context.Context, id string) (*Action, *mcp.CallToolResult, error) {
+	a, err := q.take(ctx, id, Approved, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	logger.InfoCtxf(ctx, "approval: %s approved by %s", a.ID, a.DecidedBy)
+	res, err := a.run(odoo.WithClient(ctx, a.client), a.req)
+	if err != nil || res == nil || res.IsError {
+		q.decided(a.ID, nil)
+	} else {
+		q.decided(a.ID, res)
+	}
+	return a, res, err
+}
+
+// Reject drops the queued call.
+func (q *Queue) Reject(ctx context.Context, id, reason string) (*Action, error) {
+	a, err := q.take(ctx, id, Rejected, reason)
+	if err != nil {
+		return nil, err
+	}
+	logger.InfoCtxf(ctx, "approval: %s rejected by %s: %s", a.ID, a.DecidedBy, reason)
+	q.decided(a.ID, nil)
+	return a, nil
+}
+
+// decided passes the outcome of an action to Decided, if set.
+func (q *Queue) decided(id string, res *mcp.CallToolResult) {
+	if q.Decided != nil {
+		q.Decided(id, res)
+	}
+}
+
+// Sweep expires overdue actions and forgets decided ones older than TTL.
+func (q *Queue) Sweep() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := time.Now()
+	n := 0
+	for id, a := range q.actions {
+		switch {
+		case a.Status == Pending && now.After(a.ExpiresAt):
+			a.Status = Expired
+			q.decided(id, nil)
+			n++
+		case a.Status != Pending && now.After(a.ExpiresAt.Add(q.TTL)):
+			delete(q.actions, id)
+		}
+	}
+	return n
+}
+
+// Run sweeps the queue every interval until ctx is cancelled.
+func (q *Queue) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if n := q.Sweep(); n > 0 {
+				logger.InfoCtxf(ctx, "approval: %d actions expired", n)
+			}
+		}
+	}
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
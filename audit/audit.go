@@ -0,0 +1,321 @@
+// Package audit keeps an append-only record of every tool call: who made
+// it, from which session, with which arguments, what it created in Odoo,
+// which model and prompt template answered, how long it took and how it
+// ended. Entries are JSON lines chained by SHA-256, each hashing the one
+// before it, so editing or deleting a line breaks the chain and Verify
+// reports where.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"mcp-bedrock-go/internal/logging"
+)
+
+var logger = logging.For("audit")
+
+// Outcome values.
+const (
+	OK      = "ok"      // the tool returned a result
+	Error   = "error"   // the tool reported an error (including denials and invalid arguments)
+	Pending = "pending" // the call was queued for approval
+	Failure = "failure" // the handler failed
+)
+
+// Entry is one audited tool call.
+type Entry struct {
+	Seq        int64          `json:"seq"`
+	Time       time.Time      `json:"time"`
+	Subject    string         `json:"subject,omitempty"`
+	Roles      []string       `json:"roles,omitempty"`
+	AuthMethod string         `json:"auth_method,omitempty"`
+	SessionID  string         `json:"session_id,omitempty"`
+	RequestID  string         `json:"request_id,omitempty"`
+	TraceID    string         `json:"trace_id,omitempty"`
+	Tool       string         `json:"tool"`
+	Args       map[string]any `json:"args,omitempty"`
+	Outcome    string         `json:"outcome"`
+	Error      string         `json:"error,omitempty"`
+	Records    []Record       `json:"records,omitempty"`  // Odoo records the call created
+	Model      string         `json:"model,omitempty"`    // LLM that answered
+	Template   string         `json:"template,omitempty"` // prompt template it was given
+	ActionID   string         `json:"action_id,omitempty"`
+	Replayed   bool           `json:"replayed,omitempty"` // result came from an idempotency key
+	LatencyMS  float64        `json:"latency_ms"`
+	Prev       string         `json:"prev"`
+	Hash       string         `json:"hash"`
+}
+
+// Record is an Odoo record created by a call.
+type Record struct {
+	Model string `json:"model"`
+	ID    int    `json:"id"`
+	Name  string `json:"name,omitempty"`
+}
+
+// Log appends entries to a JSONL file.
+type Log struct {
+	path string
+
+	mu   sync.Mutex
+	seq  int64
+	last string // hash of the last entry
+	size int64  // file size after our last write
+}
+
+// Open opens (or creates) the log in path and finds the end of its chain.
+func Open(path string) (*Log, error) {
+	l := &Log{path: path}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	f.Close()
+	if err := l.syncTail(); err != nil {
+		return nil, err
+	}
+	logger.Infof("audit: %s at seq %d", path, l.seq)
+	return l, nil
+}
+
+// syncTail reads the last entry when the file grew behind our back (another
+// process, such as a CLI call, appended to it). Callers hold l.mu or own l,
+// and when appending also the file lock.
+func (l *Log) syncTail() error {
+	st, err := os.Stat(l.path)
+	if err != nil {
+		return err
+	}
+	if st.Size() == l.size {
+		return nil
+	}
+	var last *Entry
+	err = l.scan(func(e *Entry, _ []byte) error {
+		last = e
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if last != nil {
+		l.seq, l.last = last.Seq, last.Hash
+	}
+	l.size = st.Size()
+	return nil
+}
+
+// Append completes e with its sequence number and chain hashes and writes it.
+// An exclusive lock on the file keeps other processes (a CLI call next to
+// the server) from appending between reading the chain's end and writing.
+func (l *Log) Append(e *Entry) (err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	if err := lockFile(f); err != nil {
+		return fmt.Errorf("lock %s: %w", l.path, err)
+	}
+	defer unlockFile(f)
+	if err := l.syncTail(); err != nil {
+		return err
+	}
+	e.Seq = l.seq + 1
+	e.Prev = l.last
+	e.Hash = ""
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if e.Hash, err = entryHash(b); err != nil {
+		return err
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	n, err := f.Write(append(line, '\n'))
+	if err != nil {
+		return err
+	}
+	l.seq, l.last = e.Seq, e.Hash
+	l.size += int64(n)
+	return nil
+}
+
+// entryHash hashes an entry's JSON without its hash field. The JSON is
+// decoded into a map and re-encoded, which sorts the keys and keeps numbers
+// as written, so the hash can be recomputed from the stored line alone.
+func entryHash(line []byte) (string, error) {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	var m map[string]any
+	if err := dec.Decode(&m); err != nil {
+		return "", err
+	}
+	delete(m, "hash")
+	canon, err := json.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(canon)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// scan calls fn for every entry in file order with its raw line.
+func (l *Log) scan(fn func(e *Entry, line []byte) error) error {
+	f, err := os.Open(l.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	lineNo := 0
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			lineNo++
+			var e Entry
+			if jerr := json.Unmarshal(line, &e); jerr != nil {
+				return fmt.Errorf("%s line %d: %w", l.path, lineNo, jerr)
+			}
+			if ferr := fn(&e, bytes.TrimSpace(line)); ferr != nil {
+				return ferr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Verification is the outcome of walking the chain.
+type Verification struct {
+	Entries  int    `json:"entries"`
+	OK       bool   `json:"ok"`
+	BrokenAt int64  `json:"broken_at,omitempty"` // seq of the first entry that does not verify
+	Problem  string `json:"problem,omitempty"`
+}
+
+// Verify recomputes every hash and checks each entry points at the one
+// before it.
+func (l *Log) Verify() (Verification, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	v := Verification{OK: true}
+	prev, seq := "", int64(0)
+	errBroken := errors.New("broken")
+	err := l.scan(func(e *Entry, line []byte) error {
+		v.Entries++
+		problem := ""
+		switch h, err := entryHash(line); {
+		case err != nil:
+			problem = err.Error()
+		case h != e.Hash:
+			problem = "content does not match its hash"
+		case e.Prev != prev:
+			problem = "previous hash does not match the entry before it"
+		case e.Seq != seq+1:
+			problem = fmt.Sprintf("sequence jumps from %d to %d", seq, e.Seq)
+		}
+		if problem != "" {
+			v.OK, v.BrokenAt, v.Problem = false, e.Seq, problem
+			return errBroken
+		}
+		prev, seq = e.Hash, e.Seq
+		return nil
+	})
+	var syntax *json.SyntaxError
+	var typ *json.UnmarshalTypeError
+	switch {
+	case err == nil, errors.Is(err, errBroken):
+	case errors.As(err, &syntax), errors.As(err, &typ):
+		// a line that no longer parses was edited too
+		v.OK, v.BrokenAt, v.Problem = false, seq+1, err.Error()
+	default:
+		return v, err
+	}
+	return v, nil
+}
+
+// Filter selects entries; zero fields match everything.
+type Filter struct {
+	Tool      string
+	Subject   string
+	SessionID string
+	Outcome   string
+	Record    string // a created record's id or name, or an argument value
+	Since     time.Time
+	Until     time.Time
+	Limit     int // most recent first; 0 for all
+}
+
+// Query returns the matching entries, most recent first.
+func (l *Log) Query(f Filter) ([]Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var out []Entry
+	err := l.scan(func(e *Entry, _ []byte) error {
+		if f.matches(e) {
+			out = append(out, *e)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	if f.Limit > 0 && len(out) > f.Limit {
+		out = out[:f.Limit]
+	}
+	return out, nil
+}
+
+func (f Filter) matches(e *Entry) bool {
+	switch {
+	case f.Tool != "" && e.Tool != f.Tool,
+		f.Subject != "" && e.Subject != f.Subject,
+		f.SessionID != "" && e.SessionID != f.SessionID,
+		f.Outcome != "" && e.Outcome != f.Outcome,
+		!f.Since.IsZero() && e.Time.Before(f.Since),
+		!f.Until.IsZero() && e.Time.After(f.Until):
+		return false
+	}
+	if f.Record == "" {
+		return true
+	}
+	for _, r := range e.Records {
+		if strconv.Itoa(r.ID) == f.Record || strings.EqualFold(r.Name, f.Record) {
+			return true
+		}
+	}
+	for _, v := range e.Args {
+		if strings.EqualFold(fmt.Sprint(v), f.Record) {
+			return true
+		}
+	}
+	return false
+}
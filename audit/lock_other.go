@@ -0,0 +1,11 @@
+//go:build !unix
+
+package audit
+
+import "os"
+
+// Without flock the log assumes a single writing process: run CLI calls
+// against the server (--server) instead of in-process while it serves.
+func lockFile(*os.File) error { return nil }
+
+func unlockFile(*os.File) {}
@@ -0,0 +1,107 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mcp-bedrock-go/approval"
+	"mcp-bedrock-go/auth"
+	"mcp-bedrock-go/internal/logging"
+	"mcp-bedrock-go/internal/tracing"
+)
+
+// Middleware records every tool call after it returns. It runs inside
+// authentication, so the principal is known, and outside validation and
+// authorization, so rejected calls are recorded with the arguments as sent.
+// A failure to write the log is logged; it does not fail the call.
+func (l *Log) Middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		res, err := next(ctx, req)
+
+		e := &Entry{
+			Time:      start.UTC(),
+			Tool:      req.Params.Name,
+			Args:      req.GetArguments(),
+			RequestID: logging.RequestID(ctx),
+			TraceID:   tracing.TraceID(ctx),
+			LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+		}
+		if p := auth.FromContext(ctx); p != nil {
+			e.Subject, e.Roles, e.AuthMethod = p.Subject, p.Roles, p.Method
+		}
+		if sess := server.ClientSessionFromContext(ctx); sess != nil {
+			e.SessionID = sess.SessionID()
+		}
+		describe(e, res, err)
+		if aerr := l.Append(e); aerr != nil {
+			logger.ErrorCtxf(ctx, "audit: %s call not recorded: %v", e.Tool, aerr)
+		}
+		return res, err
+	}
+}
+
+// describe fills the outcome and what the result says about records,
+// models and approvals.
+func describe(e *Entry, res *mcp.CallToolResult, err error) {
+	switch {
+	case err != nil:
+		e.Outcome, e.Error = Failure, err.Error()
+		return
+	case res == nil:
+		e.Outcome = Failure
+		return
+	}
+	text := ""
+	if len(res.Content) > 0 {
+		if tc, ok := res.Content[0].(mcp.TextContent); ok {
+			text = tc.Text
+		}
+	}
+	e.Outcome = OK
+	if res.IsError {
+		e.Outcome = Error
+		e.Error = clip(text, 1000)
+	} else {
+		var body struct {
+			Status   string `json:"status"`
+			ActionID string `json:"action_id"`
+		}
+		raw := []byte(text)
+		if res.StructuredContent != nil {
+			// the text may be in another output_format
+			raw, _ = json.Marshal(res.StructuredContent)
+		}
+		if json.Unmarshal(raw, &body) == nil && body.Status == string(approval.Pending) {
+			e.Outcome, e.ActionID = Pending, body.ActionID
+		}
+	}
+
+	if res.Meta == nil {
+		return
+	}
+	meta := res.Meta.AdditionalFields
+	e.Model, _ = meta["model"].(string)
+	e.Template, _ = meta["template"].(string)
+	if id, ok := meta["approved_action"].(string); ok {
+		e.ActionID = id
+	}
+	e.Replayed, _ = meta["idempotent_replay"].(bool)
+	// records are []Record from the handler, or decoded JSON on a replay
+	if b, err := json.Marshal(meta["records"]); err == nil {
+		_ = json.Unmarshal(b, &e.Records)
+	}
+}
+
+// clip shortens s to n runes, so a cut never splits a UTF-8 sequence.
+func clip(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n]) + "…"
+}
@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+)
+
+// APIKey maps one static key to the principal it authenticates.
+type APIKey struct {
+	Key     string
+	Subject string
+	Roles   []string
+}
+
+// StaticKeys authenticates the X-API-Key header against a fixed list.
+type StaticKeys struct {
+	keys []hashedKey
+}
+
+type hashedKey struct {
+	sum [32]byte
+	p   Principal
+}
+
+// NewStaticKeys builds the authenticator. Keys are kept only as SHA-256
+// digests and compared in constant time.
+func NewStaticKeys(keys []APIKey) *StaticKeys {
+	s := &StaticKeys{}
+	for _, k := range keys {
+		s.keys = append(s.keys, hashedKey{
+			sum: sha256.Sum256([]byte(k.Key)),
+			p:   Principal{Subject: k.Subject, Roles: k.Roles, Method: "api_key"},
+		})
+	}
+	return s
+}
+
+// Authenticate implements Authenticator.
+func (s *StaticKeys) Authenticate(r *http.Request) (*Principal, error) {
+	key := r.Header.Get("X-API-Key")
+	if key == "" {
+		return nil, ErrNoCredentials
+	}
+	sum := sha256.Sum256([]byte(key))
+	for _, k := range s.keys {
+		if subtle.ConstantTimeCompare(sum[:], k.sum[:]) == 1 {
+			p := k.p
+			return &p, nil
+		}
+	}
+	return nil, errors.New("unknown api key")
+}
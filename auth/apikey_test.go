@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestStaticKeys(t *testing.T) {
+	s := NewStaticKeys([]APIKey{
+		{Key: "key-alice-0123456789", Subject: "alice", Roles: []string{"admin"}},
+		{Key: "key-bob-0123456789", Subject: "bob", Roles: []string{"viewer"}},
+	})
+	request := func(key string) *http.Request {
+		r, _ := http.NewRequest(http.MethodGet, "/mcp", nil)
+		if key != "" {
+			r.Header.Set("X-API-Key", key)
+		}
+		return r
+	}
+
+	p, err := s.Authenticate(request("key-bob-0123456789"))
+	if err != nil || p.Subject != "bob" || p.Method != "api_key" || !p.HasRole("viewer") {
+		t.Fatalf("got %+v, %v; want bob", p, err)
+	}
+	// each call gets its own copy
+	p.Subject = "changed"
+	if p2, _ := s.Authenticate(request("key-bob-0123456789")); p2.Subject != "bob" {
+		t.Fatalf("stored principal modified: %+v", p2)
+	}
+
+	for _, key := range []string{"key-bob-012345678", "key-bob-0123456789x", "KEY-BOB-0123456789"} {
+		if p, err := s.Authenticate(request(key)); err == nil {
+			t.Errorf("%q: authenticated as %+v", key, p)
+		}
+	}
+	if _, err := s.Authenticate(request("")); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("no key: got %v, want ErrNoCredentials", err)
+	}
+}
@@ -0,0 +1,166 @@
+// Package auth authenticates HTTP/SSE callers with static API keys,
+// HMAC-signed bearer tokens or JWTs checked against a local JWKS file, and
+// carries the resulting Principal into the MCP session context.
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mcp-bedrock-go/internal/logging"
+)
+
+var logger = logging.For("auth")
+
+// Principal is an authenticated caller.
+type Principal struct {
+	Subject string         `json:"subject"`
+	Name    string         `json:"name,omitempty"`
+	Roles   []string       `json:"roles,omitempty"`
+	Method  string         `json:"method"` // api_key, hmac, jwt, stdio, cli
+	Claims  map[string]any `json:"claims,omitempty"`
+}
+
+// HasRole reports whether the principal carries role.
+func (p *Principal) HasRole(role string) bool {
+	if p == nil {
+		return false
+	}
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrNoCredentials means the request carried nothing this authenticator
+// understands; the next one in the chain is tried.
+var ErrNoCredentials = errors.New("no credentials")
+
+// Authenticator validates the credentials on a request.
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns ctx carrying p.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal on ctx. It falls back to the principal
+// recorded for the MCP session, so handlers see the caller even when the
+// transport did not forward request values.
+func FromContext(ctx context.Context) *Principal {
+	if p, ok := ctx.Value(principalKey{}).(*Principal); ok {
+		return p
+	}
+	if sess := server.ClientSessionFromContext(ctx); sess != nil {
+		return sessions.get(sess.SessionID())
+	}
+	return nil
+}
+
+// sessionPrincipals remembers who opened each MCP session.
+type sessionPrincipals struct {
+	mu sync.RWMutex
+	m  map[string]*Principal
+}
+
+var sessions = &sessionPrincipals{m: map[string]*Principal{}}
+
+func (s *sessionPrincipals) get(id string) *Principal {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m[id]
+}
+
+// Hooks records the principal of each MCP session when it registers and
+// forgets it on unregister.
+func Hooks(h *server.Hooks) {
+	h.AddOnRegisterSession(func(ctx context.Context, sess server.ClientSession) {
+		if p, ok := ctx.Value(principalKey{}).(*Principal); ok {
+			sessions.mu.Lock()
+			sessions.m[sess.SessionID()] = p
+			sessions.mu.Unlock()
+		}
+	})
+	h.AddOnUnregisterSession(func(ctx context.Context, sess server.ClientSession) {
+		sessions.mu.Lock()
+		delete(sessions.m, sess.SessionID())
+		sessions.mu.Unlock()
+	})
+}
+
+// ToolMiddleware rejects calls whose request principal differs from the one
+// that opened the session (a session ID reused with someone else's
+// credentials) and logs who is calling which tool.
+func ToolMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		reqP, _ := ctx.Value(principalKey{}).(*Principal)
+		var sessID string
+		if sess := server.ClientSessionFromContext(ctx); sess != nil {
+			sessID = sess.SessionID()
+			if owner := sessions.get(sessID); owner != nil && reqP != nil && owner.Subject != reqP.Subject {
+				logger.ErrorCtxf(ctx, "auth: session %s owned by %s used by %s", sessID, owner.Subject, reqP.Subject)
+				return mcp.NewToolResultError("session belongs to another principal"), nil
+			}
+		}
+		who := "anonymous"
+		if p := FromContext(ctx); p != nil {
+			who = p.Subject + " (" + p.Method + ")"
+		}
+		logger.InfoCtxf(ctx, "tool call %s by %s session=%s", req.Params.Name, who, sessID)
+		return next(ctx, req)
+	}
+}
+
+// Middleware authenticates every request except the exempt paths. The first
+// authenticator that recognises the credentials decides; requests with no
+// usable credentials get 401.
+func Middleware(next http.Handler, exempt []string, authenticators ...Authenticator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, e := range exempt {
+			if r.URL.Path == e {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+		for _, a := range authenticators {
+			p, err := a.Authenticate(r)
+			if errors.Is(err, ErrNoCredentials) {
+				continue
+			}
+			if err != nil {
+				logger.InfoCtxf(r.Context(), "auth: rejected %s %s from %s: %v", r.Method, r.URL.Path, r.RemoteAddr, err)
+				unauthorized(w, "invalid credentials")
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), p)))
+			return
+		}
+		unauthorized(w, "authentication required")
+	})
+}
+
+func unauthorized(w http.ResponseWriter, msg string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="mcp"`)
+	http.Error(w, msg, http.StatusUnauthorized)
+}
+
+// bearer extracts the token from "Authorization: Bearer <token>".
+func bearer(r *http.Request) string {
+	h := r.Header.Get("Authorization")
+	if len(h) > 7 && strings.EqualFold(h[:7], "bearer ") {
+		return strings.TrimSpace(h[7:])
+	}
+	return ""
+}
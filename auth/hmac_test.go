@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHMACTokens(t *testing.T) {
+	h := NewHMACTokens("s3cret-s3cret", "mcp")
+	h.now = func() time.Time { return testNow }
+	sign := func(c TokenClaims) string {
+		tok, err := h.Sign(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tok
+	}
+	valid := sign(TokenClaims{Subject: "bob", Roles: []string{"viewer"}, ExpiresAt: testNow.Add(time.Hour).Unix()})
+	body, sig, _ := strings.Cut(strings.TrimPrefix(valid, hmacPrefix), ".")
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"bob","roles":["admin"],"iss":"mcp","exp":` +
+		strconv.FormatInt(testNow.Add(time.Hour).Unix(), 10) + `}`))
+	other := NewHMACTokens("another-secret", "mcp")
+	foreign, _ := other.Sign(TokenClaims{Subject: "bob", ExpiresAt: testNow.Add(time.Hour).Unix()})
+
+	tests := []struct {
+		name, token, wantErr string
+	}{
+		{"valid", valid, ""},
+		{"tampered payload", hmacPrefix + forged + "." + sig, "bad token signature"},
+		{"tampered signature", hmacPrefix + body + "." + sig[:len(sig)-2] + "AA", "bad token signature"},
+		{"other secret", foreign, "bad token signature"},
+		{"no signature", hmacPrefix + body, "malformed token"},
+		{"expired", sign(TokenClaims{Subject: "bob", ExpiresAt: testNow.Add(-time.Second).Unix()}), "token expired"},
+		{"expires now", sign(TokenClaims{Subject: "bob", ExpiresAt: testNow.Unix()}), "token expired"},
+		{"no exp", sign(TokenClaims{Subject: "bob"}), "token expired"},
+		{"wrong issuer", sign(TokenClaims{Subject: "bob", Issuer: "else", ExpiresAt: testNow.Add(time.Hour).Unix()}), "unexpected issuer"},
+		{"no subject", sign(TokenClaims{ExpiresAt: testNow.Add(time.Hour).Unix()}), "token has no subject"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := h.Authenticate(bearerRequest(tt.token))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got %v, %v; want error containing %q", p, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Subject != "bob" || p.Method != "hmac" || !p.HasRole("viewer") {
+				t.Fatalf("got principal %+v", p)
+			}
+		})
+	}
+
+	for _, tok := range []string{"", "a.b.c"} {
+		if _, err := h.Authenticate(bearerRequest(tok)); !errors.Is(err, ErrNoCredentials) {
+			t.Errorf("%q: got %v, want ErrNoCredentials", tok, err)
+		}
+	}
+}
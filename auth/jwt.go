@@ -0,0 +1,283 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// JWTOptions configures JWT verification.
+type JWTOptions struct {
+	JWKSFile   string // local JWKS document with the issuer's public keys
+	Issuer     string // required iss, if set
+	Audience   string // required aud, if set
+	RolesClaim string // claim holding the roles; default "roles"
+	Leeway     time.Duration
+}
+
+// JWTVerifier authenticates "Authorization: Bearer <jwt>" tokens signed with
+// RS256/384/512 or ES256/384 by a key in the JWKS file.
+type JWTVerifier struct {
+	opts JWTOptions
+	keys map[string]crypto.PublicKey // by kid
+	now  func() time.Time
+}
+
+// NewJWTVerifier loads the JWKS file.
+func NewJWTVerifier(opts JWTOptions) (*JWTVerifier, error) {
+	if opts.RolesClaim == "" {
+		opts.RolesClaim = "roles"
+	}
+	if opts.Leeway == 0 {
+		opts.Leeway = 30 * time.Second
+	}
+	raw, err := os.ReadFile(opts.JWKSFile)
+	if err != nil {
+		return nil, fmt.Errorf("read jwks: %w", err)
+	}
+	keys, err := parseJWKS(raw)
+	if err != nil {
+		return nil, fmt.Errorf("jwks %s: %w", opts.JWKSFile, err)
+	}
+	return &JWTVerifier{opts: opts, keys: keys, now: time.Now}, nil
+}
+
+// Authenticate implements Authenticator.
+func (v *JWTVerifier) Authenticate(r *http.Request) (*Principal, error) {
+	tok := bearer(r)
+	if tok == "" || strings.HasPrefix(tok, hmacPrefix) || strings.Count(tok, ".") != 2 {
+		return nil, ErrNoCredentials
+	}
+	parts := strings.Split(tok, ".")
+
+	var hdr struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &hdr); err != nil {
+		return nil, fmt.Errorf("jwt header: %w", err)
+	}
+	key, err := v.key(hdr.Kid)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("jwt signature: %w", err)
+	}
+	if err := verifySig(hdr.Alg, key, []byte(parts[0]+"."+parts[1]), sig); err != nil {
+		return nil, err
+	}
+
+	var claims map[string]any
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("jwt claims: %w", err)
+	}
+	if err := v.checkClaims(claims); err != nil {
+		return nil, err
+	}
+	sub, _ := claims["sub"].(string)
+	if sub == "" {
+		return nil, errors.New("jwt has no sub")
+	}
+	name, _ := claims["name"].(string)
+	return &Principal{
+		Subject: sub,
+		Name:    name,
+		Roles:   stringList(claims[v.opts.RolesClaim]),
+		Method:  "jwt",
+		Claims:  claims,
+	}, nil
+}
+
+func (v *JWTVerifier) key(kid string) (crypto.PublicKey, error) {
+	if k, ok := v.keys[kid]; ok {
+		return k, nil
+	}
+	// A single key without kid matches tokens without kid.
+	if kid == "" && len(v.keys) == 1 {
+		for _, k := range v.keys {
+			return k, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown jwt key %q", kid)
+}
+
+func (v *JWTVerifier) checkClaims(c map[string]any) error {
+	now := v.now()
+	exp, ok := c["exp"].(float64)
+	if !ok {
+		return errors.New("jwt has no exp")
+	}
+	if now.After(time.Unix(int64(exp), 0).Add(v.opts.Leeway)) {
+		return errors.New("jwt expired")
+	}
+	if nbf, ok := c["nbf"].(float64); ok && now.Add(v.opts.Leeway).Before(time.Unix(int64(nbf), 0)) {
+		return errors.New("jwt not yet valid")
+	}
+	if v.opts.Issuer != "" && c["iss"] != v.opts.Issuer {
+		return fmt.Errorf("unexpected jwt issuer %v", c["iss"])
+	}
+	if v.opts.Audience != "" {
+		found := false
+		for _, a := range stringList(c["aud"]) {
+			if a == v.opts.Audience {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("jwt audience does not include %q", v.opts.Audience)
+		}
+	}
+	return nil
+}
+
+func verifySig(alg string, key crypto.PublicKey, signed, sig []byte) error {
+	var h crypto.Hash
+	switch alg {
+	case "RS256", "ES256":
+		h = crypto.SHA256
+	case "RS384", "ES384":
+		h = crypto.SHA384
+	case "RS512":
+		h = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported jwt alg %q", alg)
+	}
+	digest := hashBytes(h, signed)
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(alg, "RS") {
+			return fmt.Errorf("alg %s does not match RSA key", alg)
+		}
+		if err := rsa.VerifyPKCS1v15(k, h, digest, sig); err != nil {
+			return errors.New("bad jwt signature")
+		}
+	case *ecdsa.PublicKey:
+		if !strings.HasPrefix(alg, "ES") {
+			return fmt.Errorf("alg %s does not match EC key", alg)
+		}
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return errors.New("bad jwt signature")
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(k, digest, r, s) {
+			return errors.New("bad jwt signature")
+		}
+	default:
+		return errors.New("unsupported key type")
+	}
+	return nil
+}
+
+func hashBytes(h crypto.Hash, b []byte) []byte {
+	switch h {
+	case crypto.SHA384:
+		s := sha512.Sum384(b)
+		return s[:]
+	case crypto.SHA512:
+		s := sha512.Sum512(b)
+		return s[:]
+	default:
+		s := sha256.Sum256(b)
+		return s[:]
+	}
+}
+
+// parseJWKS reads RSA and EC P-256/P-384 public keys from a JWKS document.
+func parseJWKS(raw []byte) (map[string]crypto.PublicKey, error) {
+	var doc struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	keys := map[string]crypto.PublicKey{}
+	for _, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		switch k.Kty {
+		case "RSA":
+			n, err1 := base64.RawURLEncoding.DecodeString(k.N)
+			e, err2 := base64.RawURLEncoding.DecodeString(k.E)
+			if err := errors.Join(err1, err2); err != nil {
+				return nil, fmt.Errorf("key %q: %w", k.Kid, err)
+			}
+			keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		case "EC":
+			var curve elliptic.Curve
+			switch k.Crv {
+			case "P-256":
+				curve = elliptic.P256()
+			case "P-384":
+				curve = elliptic.P384()
+			default:
+				return nil, fmt.Errorf("key %q: unsupported curve %q", k.Kid, k.Crv)
+			}
+			x, err1 := base64.RawURLEncoding.DecodeString(k.X)
+			y, err2 := base64.RawURLEncoding.DecodeString(k.Y)
+			if err := errors.Join(err1, err2); err != nil {
+				return nil, fmt.Errorf("key %q: %w", k.Kid, err)
+			}
+			keys[k.Kid] = &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no usable signing keys")
+	}
+	return keys, nil
+}
+
+func decodeSegment(seg string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// stringList accepts a claim that is either a string or a list of strings.
+func stringList(v any) []string {
+	switch t := v.(type) {
+	case string:
+		if t == "" {
+			return nil
+		}
+		return []string{t}
+	case []any:
+		out := make([]string, 0, len(t))
+		for _, x := range t {
+			if s, ok := x.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testNow = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+// jwtKeys are one RSA and one P-256 key, published in a JWKS file as kid
+// "rsa" and "ec".
+type jwtKeys struct {
+	rsa  *rsa.PrivateKey
+	ec   *ecdsa.PrivateKey
+	jwks string
+}
+
+func newJWTKeys(t *testing.T) *jwtKeys {
+	t.Helper()
+	rk, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ek, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b64 := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	doc, _ := json.Marshal(map[string]any{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa", "use": "sig", "n": b64(rk.N.Bytes()), "e": b64(big.NewInt(int64(rk.E)).Bytes())},
+		{"kty": "EC", "kid": "ec", "crv": "P-256", "x": b64(ek.X.FillBytes(make([]byte, 32))), "y": b64(ek.Y.FillBytes(make([]byte, 32)))},
+		{"kty": "RSA", "kid": "enc", "use": "enc", "n": b64(rk.N.Bytes()), "e": "AQAB"},
+	}})
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, doc, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return &jwtKeys{rsa: rk, ec: ek, jwks: path}
+}
+
+// sign builds a token with the given header alg and kid, signed by key.
+func (k *jwtKeys) sign(t *testing.T, alg, kid string, key crypto.Signer, claims map[string]any) string {
+	t.Helper()
+	hdr, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	body, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(hdr) + "." + base64.RawURLEncoding.EncodeToString(body)
+	digest := hashBytes(crypto.SHA256, []byte(signed))
+	var sig []byte
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		s, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig = s
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func bearerRequest(tok string) *http.Request {
+	r, _ := http.NewRequest(http.MethodGet, "/mcp", nil)
+	if tok != "" {
+		r.Header.Set("Authorization", "Bearer "+tok)
+	}
+	return r
+}
+
+// claims returns valid claims with the given overrides; a nil value
+// removes the claim.
+func claims(over map[string]any) map[string]any {
+	c := map[string]any{
+		"sub":   "alice",
+		"name":  "Alice",
+		"iss":   "https://idp.example",
+		"aud":   []string{"other", "mcp"},
+		"exp":   testNow.Add(time.Hour).Unix(),
+		"roles": []string{"planner"},
+	}
+	for k, v := range over {
+		if v == nil {
+			delete(c, k)
+		} else {
+			c[k] = v
+		}
+	}
+	return c
+}
+
+func TestJWTVerifier(t *testing.T) {
+	k := newJWTKeys(t)
+	v, err := NewJWTVerifier(JWTOptions{JWKSFile: k.jwks, Issuer: "https://idp.example", Audience: "mcp", Leeway: 30 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.now = func() time.Time { return testNow }
+	if _, ok := v.keys["enc"]; ok {
+		t.Fatal("encryption key loaded as a signing key")
+	}
+
+	valid := k.sign(t, "RS256", "rsa", k.rsa, claims(nil))
+	parts := strings.Split(valid, ".")
+	forged, _ := json.Marshal(claims(map[string]any{"sub": "mallory", "roles": []string{"admin"}}))
+	tampered := parts[0] + "." + base64.RawURLEncoding.EncodeToString(forged) + "." + parts[2]
+
+	ecValid := k.sign(t, "ES256", "ec", k.ec, claims(nil))
+	ecParts := strings.Split(ecValid, ".")
+	ecSig, _ := base64.RawURLEncoding.DecodeString(ecParts[2])
+	shortSig := ecParts[0] + "." + ecParts[1] + "." + base64.RawURLEncoding.EncodeToString(ecSig[:63])
+	longSig := ecParts[0] + "." + ecParts[1] + "." + base64.RawURLEncoding.EncodeToString(append(ecSig, 0))
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr string // "" for success
+	}{
+		{"rs256", valid, ""},
+		{"es256", ecValid, ""},
+		{"tampered payload", tampered, "bad jwt signature"},
+		{"ES256 header with RSA kid", k.sign(t, "ES256", "rsa", k.ec, claims(nil)), "does not match RSA key"},
+		{"RS256 header with EC kid", k.sign(t, "RS256", "ec", k.rsa, claims(nil)), "does not match EC key"},
+		{"EC signature too short", shortSig, "bad jwt signature"},
+		{"EC signature too long", longSig, "bad jwt signature"},
+		{"alg none", k.sign(t, "none", "rsa", k.rsa, claims(nil)), "unsupported jwt alg"},
+		{"unknown kid", k.sign(t, "RS256", "gone", k.rsa, claims(nil)), "unknown jwt key"},
+		{"no kid with several keys", k.sign(t, "RS256", "", k.rsa, claims(nil)), "unknown jwt key"},
+		{"expired within leeway", k.sign(t, "RS256", "rsa", k.rsa, claims(map[string]any{"exp": testNow.Add(-20 * time.Second).Unix()})), ""},
+		{"expired beyond leeway", k.sign(t, "RS256", "rsa", k.rsa, claims(map[string]any{"exp": testNow.Add(-time.Minute).Unix()})), "jwt expired"},
+		{"no exp", k.sign(t, "RS256", "rsa", k.rsa, claims(map[string]any{"exp": nil})), "jwt has no exp"},
+		{"nbf within leeway", k.sign(t, "RS256", "rsa", k.rsa, claims(map[string]any{"nbf": testNow.Add(20 * time.Second).Unix()})), ""},
+		{"nbf beyond leeway", k.sign(t, "RS256", "rsa", k.rsa, claims(map[string]any{"nbf": testNow.Add(time.Minute).Unix()})), "jwt not yet valid"},
+		{"wrong iss", k.sign(t, "RS256", "rsa", k.rsa, claims(map[string]any{"iss": "https://evil.example"})), "unexpected jwt issuer"},
+		{"no iss", k.sign(t, "RS256", "rsa", k.rsa, claims(map[string]any{"iss": nil})), "unexpected jwt issuer"},
+		{"aud as string", k.sign(t, "RS256", "rsa", k.rsa, claims(map[string]any{"aud": "mcp"})), ""},
+		{"wrong aud", k.sign(t, "RS256", "rsa", k.rsa, claims(map[string]any{"aud": []string{"other"}})), "audience does not include"},
+		{"no sub", k.sign(t, "RS256", "rsa", k.rsa, claims(map[string]any{"sub": nil})), "jwt has no sub"},
+		{"garbage header", "!!.e30.sig", "jwt header"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := v.Authenticate(bearerRequest(tt.token))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got %v, %v; want error containing %q", p, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Subject != "alice" || p.Name != "Alice" || p.Method != "jwt" || !p.HasRole("planner") {
+				t.Fatalf("got principal %+v", p)
+			}
+		})
+	}
+}
+
+func TestJWTVerifierPassesOtherCredentials(t *testing.T) {
+	k := newJWTKeys(t)
+	v, err := NewJWTVerifier(JWTOptions{JWKSFile: k.jwks})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tok := range []string{"", "opaque-token", hmacPrefix + "a.b"} {
+		if _, err := v.Authenticate(bearerRequest(tok)); !errors.Is(err, ErrNoCredentials) {
+			t.Errorf("%q: got %v, want ErrNoCredentials", tok, err)
+		}
+	}
+}
+
+func TestJWTSingleKeyWithoutKid(t *testing.T) {
+	rk, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b64 := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	doc, _ := json.Marshal(map[string]any{"keys": []map[string]string{{"kty": "RSA", "n": b64(rk.N.Bytes()), "e": "AQAB"}}})
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, doc, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	v, err := NewJWTVerifier(JWTOptions{JWKSFile: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.now = func() time.Time { return testNow }
+	k := &jwtKeys{}
+	if _, err := v.Authenticate(bearerRequest(k.sign(t, "RS256", "", rk, claims(nil)))); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseJWKS(t *testing.T) {
+	tests := []struct {
+		name, doc, wantErr string
+	}{
+		{"not json", `{`, "unexpected"},
+		{"no keys", `{"keys":[]}`, "no usable signing keys"},
+		{"only encryption keys", `{"keys":[{"kty":"RSA","use":"enc","n":"AQAB","e":"AQAB"}]}`, "no usable signing keys"},
+		{"unsupported curve", `{"keys":[{"kty":"EC","kid":"k","crv":"P-521","x":"AQ","y":"AQ"}]}`, "unsupported curve"},
+		{"bad base64", `{"keys":[{"kty":"RSA","kid":"k","n":"!!","e":"AQAB"}]}`, "key \"k\""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseJWKS([]byte(tt.doc)); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -4,12 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	bedrockruntime "github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
 
 	"mcp-bedrock-go/internal/logging"
+	"mcp-bedrock-go/internal/metrics"
+	"mcp-bedrock-go/internal/tracing"
 )
 
+var logger = logging.For("bedrock")
+
 // Client wraps the AWS Bedrock runtime client with a small helper.
 type Client struct {
 	inner *bedrockruntime.Client
@@ -40,27 +45,32 @@ func (c *Client) GenerateText(ctx context.Context, modelID string, prompt any) (
 		bodyBytes = b
 	}
 
-	logging.Debugf("Bedrock InvokeModel model=%s prompt=%s", modelID, string(bodyBytes))
+	ctx, span := startSpan(ctx, "generate", modelID)
+	defer span.Finish()
+	logger.DebugContext(ctx, "bedrock invoke", "model", modelID, "prompt", string(bodyBytes))
+	start := time.Now()
 	resp, err := c.inner.InvokeModel(ctx, &bedrockruntime.InvokeModelInput{
 		ModelId:     &modelID,
 		ContentType: awsString("application/json"),
 		Accept:      awsString("application/json"),
 		Body:        bodyBytes,
 	})
+	observe(span, modelID, "generate", start, err)
 	if err != nil {
-		logging.Errorf("Bedrock InvokeModel error: %v", err)
+		logger.ErrorCtxf(ctx, "Bedrock InvokeModel error: %v", err)
 		return "", err
 	}
 	// Best-effort: return raw body if we can't parse a structured response
-	logging.Debugf("Bedrock response (raw): %s", string(resp.Body))
+	logger.DebugContext(ctx, "bedrock response", "model", modelID, "response", string(resp.Body))
 	var out any
 	_ = json.Unmarshal(resp.Body, &out)
 	switch v := out.(type) {
 	case map[string]any:
+		countTokens(span, modelID, v)
 		// try to extract common fields
 		if gen, ok := v["generation"]; ok {
 			if s, ok := gen.(string); ok {
-				logging.Debugf("Bedrock generation extracted: %s", s)
+				logger.DebugContext(ctx, "bedrock generation extracted", "model", modelID, "response", s)
 				return s, nil
 			}
 		}
@@ -70,6 +80,45 @@ func (c *Client) GenerateText(ctx context.Context, modelID string, prompt any) (
 
 func awsString(s string) *string { return &s }
 
+// startSpan opens the client span around one InvokeModel call.
+func startSpan(ctx context.Context, operation, modelID string) (context.Context, *tracing.Span) {
+	ctx, span := tracing.Start(ctx, "bedrock "+operation+" "+modelID, tracing.KindClient)
+	span.SetAttr("gen_ai.system", "aws.bedrock")
+	span.SetAttr("gen_ai.operation.name", operation)
+	span.SetAttr("gen_ai.request.model", modelID)
+	return ctx, span
+}
+
+// observe records the latency and outcome of one InvokeModel call.
+func observe(span *tracing.Span, modelID, operation string, start time.Time, err error) {
+	outcome := "ok"
+	if err != nil {
+		outcome = string(Classify(err))
+		span.SetAttr("error.type", outcome)
+		span.RecordError(err)
+	}
+	metrics.BedrockDuration.Observe(time.Since(start).Seconds(), modelID, operation, outcome)
+}
+
+// countTokens records the token usage a response body reports. Llama uses
+// prompt_token_count/generation_token_count, Titan inputTextTokenCount and
+// Anthropic a usage object.
+func countTokens(span *tracing.Span, modelID string, body map[string]any) {
+	add := func(direction string, v any) {
+		if n, ok := v.(float64); ok && n > 0 {
+			metrics.BedrockTokens.Add(n, modelID, direction)
+			span.SetAttr("gen_ai.usage."+direction+"_tokens", int(n))
+		}
+	}
+	add("input", body["prompt_token_count"])
+	add("output", body["generation_token_count"])
+	add("input", body["inputTextTokenCount"])
+	if u, ok := body["usage"].(map[string]any); ok {
+		add("input", u["input_tokens"])
+		add("output", u["output_tokens"])
+	}
+}
+
 // FormatSystemPrompt wraps the user's message into the system prompt template
 // requested by the user. It returns a single string which should be passed
 // to GenerateText.
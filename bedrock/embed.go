@@ -0,0 +1,62 @@
+package bedrock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bedrockruntime "github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+)
+
+// Embedder produces vectors with a Titan text embedding model, e.g.
+// "amazon.titan-embed-text-v2:0". It satisfies the embedding provider
+// interfaces used by the search packages.
+type Embedder struct {
+	client  *Client
+	modelID string
+}
+
+// NewEmbedder returns an embedding provider backed by c.
+func NewEmbedder(c *Client, modelID string) *Embedder {
+	return &Embedder{client: c, modelID: modelID}
+}
+
+// Embed returns one vector per text. Titan accepts a single input per call,
+// so texts are embedded sequentially.
+func (e *Embedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, 0, len(texts))
+	for _, t := range texts {
+		body, err := json.Marshal(map[string]any{"inputText": t})
+		if err != nil {
+			return nil, err
+		}
+		sctx, span := startSpan(ctx, "embed", e.modelID)
+		start := time.Now()
+		resp, err := e.client.inner.InvokeModel(sctx, &bedrockruntime.InvokeModelInput{
+			ModelId:     &e.modelID,
+			ContentType: awsString("application/json"),
+			Accept:      awsString("application/json"),
+			Body:        body,
+		})
+		observe(span, e.modelID, "embed", start, err)
+		if err != nil {
+			span.Finish()
+			logger.ErrorCtxf(ctx, "Bedrock embed error model=%s: %v", e.modelID, err)
+			return nil, err
+		}
+		var parsed struct {
+			Embedding  []float32 `json:"embedding"`
+			TokenCount float64   `json:"inputTextTokenCount"`
+		}
+		if err := json.Unmarshal(resp.Body, &parsed); err != nil {
+			span.RecordError(err)
+			span.Finish()
+			return nil, fmt.Errorf("decode embedding: %w", err)
+		}
+		countTokens(span, e.modelID, map[string]any{"inputTextTokenCount": parsed.TokenCount})
+		span.Finish()
+		out = append(out, parsed.Embedding)
+	}
+	return out, nil
+}
@@ -0,0 +1,289 @@
+package bedrock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+
+	"mcp-bedrock-go/internal/tracing"
+)
+
+// Generator is anything that can turn a prompt into text for a given model.
+// *Client satisfies it; tests and offline tooling can plug in their own.
+type Generator interface {
+	GenerateText(ctx context.Context, modelID string, prompt any) (string, error)
+}
+
+// ErrorClass groups Bedrock errors by how the fallback chain should react.
+type ErrorClass string
+
+const (
+	ClassThrottling   ErrorClass = "throttling"
+	ClassValidation   ErrorClass = "validation"
+	ClassAccessDenied ErrorClass = "access_denied"
+	ClassUnavailable  ErrorClass = "unavailable"
+	ClassOther        ErrorClass = "other"
+)
+
+// Classes lists every ErrorClass, for configuration.
+var Classes = []ErrorClass{ClassThrottling, ClassValidation, ClassAccessDenied, ClassUnavailable, ClassOther}
+
+// Classify maps an InvokeModel error to an ErrorClass.
+func Classify(err error) ErrorClass {
+	if err == nil {
+		return ""
+	}
+	var (
+		throttle *types.ThrottlingException
+		quota    *types.ServiceQuotaExceededException
+		invalid  *types.ValidationException
+		denied   *types.AccessDeniedException
+		notFound *types.ResourceNotFoundException
+		notReady *types.ModelNotReadyException
+		timeout  *types.ModelTimeoutException
+		unavail  *types.ServiceUnavailableException
+		internal *types.InternalServerException
+	)
+	switch {
+	case errors.As(err, &throttle), errors.As(err, &quota):
+		return ClassThrottling
+	case errors.As(err, &invalid):
+		return ClassValidation
+	case errors.As(err, &denied):
+		return ClassAccessDenied
+	case errors.As(err, &notFound), errors.As(err, &notReady), errors.As(err, &timeout),
+		errors.As(err, &unavail), errors.As(err, &internal):
+		return ClassUnavailable
+	}
+	return ClassOther
+}
+
+// Action tells the chain what to do after a failed attempt.
+type Action string
+
+const (
+	// ActionRetry retries the same model (up to Policy.Retries) before falling back.
+	ActionRetry Action = "retry"
+	// ActionFallback moves on to the next model in the chain.
+	ActionFallback Action = "fallback"
+	// ActionFail stops and returns the error to the caller.
+	ActionFail Action = "fail"
+)
+
+// Actions lists every Action, for configuration.
+var Actions = []Action{ActionRetry, ActionFallback, ActionFail}
+
+// Policy describes how the chain reacts to one ErrorClass.
+type Policy struct {
+	Action  Action
+	Retries int           // extra attempts on the same model when Action is retry
+	Backoff time.Duration // wait between retries, doubled after each attempt
+	// Cooldown marks the model unhealthy for this long so later calls skip it.
+	Cooldown time.Duration
+}
+
+// DefaultPolicies retries throttling briefly, skips models we may not call and
+// falls through everything else.
+func DefaultPolicies() map[ErrorClass]Policy {
+	return map[ErrorClass]Policy{
+		ClassThrottling:   {Action: ActionRetry, Retries: 1, Backoff: 500 * time.Millisecond, Cooldown: 30 * time.Second},
+		ClassValidation:   {Action: ActionFallback},
+		ClassAccessDenied: {Action: ActionFallback, Cooldown: 10 * time.Minute},
+		ClassUnavailable:  {Action: ActionFallback, Cooldown: time.Minute},
+		ClassOther:        {Action: ActionFallback},
+	}
+}
+
+// ModelHealth is the in-memory view of how a model has been behaving.
+type ModelHealth struct {
+	Model               string     `json:"model"`
+	Successes           int        `json:"successes"`
+	Failures            int        `json:"failures"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastErrorClass      ErrorClass `json:"last_error_class,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastSuccess         time.Time  `json:"last_success,omitzero"`
+	CooldownUntil       time.Time  `json:"cooldown_until,omitzero"`
+}
+
+// Healthy reports whether the model is outside its cooldown window.
+func (h ModelHealth) Healthy(now time.Time) bool {
+	return now.After(h.CooldownUntil)
+}
+
+// Chain tries an ordered list of models until one answers.
+type Chain struct {
+	gen      Generator
+	models   []string
+	policies map[ErrorClass]Policy
+	// Timeout bounds each model attempt; zero means no extra limit.
+	Timeout time.Duration
+
+	mu     sync.Mutex
+	health map[string]*ModelHealth
+}
+
+// NewChain builds a fallback chain over models (tried in order) using the
+// default policies. Use SetPolicy to override a class.
+func NewChain(gen Generator, models ...string) *Chain {
+	c := &Chain{
+		gen:      gen,
+		policies: DefaultPolicies(),
+		health:   map[string]*ModelHealth{},
+	}
+	for _, m := range models {
+		m = strings.TrimSpace(m)
+		if m == "" {
+			continue
+		}
+		c.models = append(c.models, m)
+		c.health[m] = &ModelHealth{Model: m}
+	}
+	return c
+}
+
+// SetPolicy overrides the policy for one error class.
+func (c *Chain) SetPolicy(class ErrorClass, p Policy) {
+	c.policies[class] = p
+}
+
+// Models returns the configured model order.
+func (c *Chain) Models() []string {
+	return append([]string(nil), c.models...)
+}
+
+// Primary returns the first model in the chain.
+func (c *Chain) Primary() string {
+	if len(c.models) == 0 {
+		return ""
+	}
+	return c.models[0]
+}
+
+// Health returns a snapshot of per-model health in chain order.
+func (c *Chain) Health() []ModelHealth {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]ModelHealth, 0, len(c.models))
+	for _, m := range c.models {
+		out = append(out, *c.health[m])
+	}
+	return out
+}
+
+// Generate sends prompt to the first healthy model and walks down the chain
+// according to the error policies. It returns the text and the model that
+// produced it.
+func (c *Chain) Generate(ctx context.Context, prompt any) (string, string, error) {
+	if len(c.models) == 0 {
+		return "", "", fmt.Errorf("no models configured")
+	}
+	ctx, span := tracing.Start(ctx, "llm generate", tracing.KindInternal)
+	defer span.Finish()
+
+	var errs []string
+	for i, model := range c.order() {
+		out, err := c.tryModel(ctx, model, prompt)
+		if err == nil {
+			span.SetAttr("gen_ai.response.model", model)
+			span.SetAttr("llm.fallbacks", i)
+			return out, model, nil
+		}
+		class := Classify(err)
+		errs = append(errs, fmt.Sprintf("%s: %v", model, err))
+		if ctx.Err() != nil || c.policy(class).Action == ActionFail {
+			break
+		}
+		logger.InfoCtxf(ctx, "Bedrock model %s failed (%s), falling back", model, class)
+	}
+	err := fmt.Errorf("all models failed: %s", strings.Join(errs, "; "))
+	span.RecordError(err)
+	return "", "", err
+}
+
+// tryModel calls one model, honouring retry policies, and records health.
+func (c *Chain) tryModel(ctx context.Context, model string, prompt any) (string, error) {
+	attempt := 0
+	for {
+		out, err := c.invoke(ctx, model, prompt)
+		if err == nil {
+			c.recordSuccess(model)
+			return out, nil
+		}
+		class := Classify(err)
+		p := c.policy(class)
+		c.recordFailure(model, class, err, p.Cooldown)
+		if p.Action != ActionRetry || attempt >= p.Retries {
+			return "", err
+		}
+		wait := p.Backoff << attempt
+		attempt++
+		select {
+		case <-ctx.Done():
+			return "", err
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (c *Chain) invoke(ctx context.Context, model string, prompt any) (string, error) {
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+	return c.gen.GenerateText(ctx, model, prompt)
+}
+
+// order returns healthy models first (in configured order) followed by those
+// still cooling down, so a fully degraded chain still gets a chance.
+func (c *Chain) order() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	healthy := make([]string, 0, len(c.models))
+	var cooling []string
+	for _, m := range c.models {
+		if c.health[m].Healthy(now) {
+			healthy = append(healthy, m)
+		} else {
+			cooling = append(cooling, m)
+		}
+	}
+	return append(healthy, cooling...)
+}
+
+func (c *Chain) policy(class ErrorClass) Policy {
+	if p, ok := c.policies[class]; ok {
+		return p
+	}
+	return Policy{Action: ActionFallback}
+}
+
+func (c *Chain) recordSuccess(model string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	h := c.health[model]
+	h.Successes++
+	h.ConsecutiveFailures = 0
+	h.LastSuccess = time.Now()
+	h.CooldownUntil = time.Time{}
+}
+
+func (c *Chain) recordFailure(model string, class ErrorClass, err error, cooldown time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	h := c.health[model]
+	h.Failures++
+	h.ConsecutiveFailures++
+	h.LastErrorClass = class
+	h.LastError = err.Error()
+	if cooldown > 0 {
+		h.CooldownUntil = time.Now().Add(cooldown)
+	}
+}
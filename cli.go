@@ -0,0 +1,267 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/mark3labs/mcp-go/client"
+	mcptransport "github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mcp-bedrock-go/approval"
+	"mcp-bedrock-go/auth"
+	"mcp-bedrock-go/config"
+	"mcp-bedrock-go/internal/logging"
+	"mcp-bedrock-go/tools"
+	"mcp-bedrock-go/transport"
+)
+
+const usageText = `usage: mcp-bedrock-go [command] [flags]
+
+Commands:
+  serve                      run the MCP server (default when no command is given)
+  tools list                 list the tools and their parameters
+  call <tool> --arg k=v ...  call a tool in-process, or on a running server with --server
+  import-mock                load mocks/mock.json into the configured Odoo
+  doctor                     check the configuration, Odoo, Bedrock and the listen address
+  audit export               write the audit log, filtered, as JSON lines, JSON, YAML or a table
+  audit verify               check the audit log's hash chain
+
+Every command accepts the configuration flags (-config, -odoo-profile, ...);
+run "mcp-bedrock-go <command> -h" for its own flags.
+`
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+// run dispatches to a subcommand and returns the exit code. Bare flags keep
+// the old behaviour of serving.
+func run(args []string) int {
+	_ = godotenv.Load()
+
+	cmd := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cmd, args = args[0], args[1:]
+	}
+	switch cmd {
+	case "serve":
+		cfg, _, err := command("serve", args, false, nil)
+		if err != nil {
+			return exitErr(err)
+		}
+		serve(cfg)
+		return 0
+	case "tools":
+		if len(args) == 0 || args[0] != "list" {
+			fmt.Fprintln(os.Stderr, "usage: mcp-bedrock-go tools list [flags]")
+			return 2
+		}
+		return exitErr(toolsList(args[1:]))
+	case "call":
+		return exitErr(call(args))
+	case "import-mock":
+		return exitErr(importMock(args))
+	case "doctor":
+		return exitErr(doctor(args))
+	case "audit":
+		switch {
+		case len(args) > 0 && args[0] == "export":
+			return exitErr(auditExport(args[1:]))
+		case len(args) > 0 && args[0] == "verify":
+			return exitErr(auditVerify(args[1:]))
+		}
+		fmt.Fprintln(os.Stderr, "usage: mcp-bedrock-go audit export|verify [flags]")
+		return 2
+	case "help", "-h", "--help":
+		fmt.Print(usageText)
+		return 0
+	}
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", cmd, usageText)
+	return 2
+}
+
+// errFailed is returned by commands that already reported their failure.
+var errFailed = errors.New("failed")
+
+func exitErr(err error) int {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case !errors.Is(err, errFailed):
+		fmt.Fprintln(os.Stderr, "error:", err)
+	}
+	return 1
+}
+
+// command parses a subcommand's flags together with the configuration flags,
+// loads the configuration and sets up logging. CLI commands log warnings and
+// errors only unless -v is given.
+func command(name string, args []string, cli bool, define func(fs *flag.FlagSet)) (*config.Config, *flag.FlagSet, error) {
+	fs := flag.NewFlagSet("mcp-bedrock-go "+name, flag.ContinueOnError)
+	load := config.Bind(fs)
+	verbose := false
+	if cli {
+		fs.BoolVar(&verbose, "v", false, "log at the configured level instead of warnings only")
+	}
+	if define != nil {
+		define(fs)
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+	cfg, err := load()
+	if err != nil {
+		return nil, nil, err
+	}
+	level := cfg.Logging.Level
+	if cli && !verbose {
+		level = "warn"
+	}
+	if err := logging.Setup(logging.Options{
+		Format:     cfg.Logging.Format,
+		Level:      level,
+		Components: cfg.Logging.Components,
+		Redact:     cfg.Logging.Redact,
+	}); err != nil {
+		return nil, nil, err
+	}
+	logging.Secret(cfg.Secrets()...)
+	tools.Location = cfg.Location()
+	return cfg, fs, nil
+}
+
+// remote selects a running server for call and tools list.
+type remote struct {
+	url       string
+	transport string // sse or http; guessed from the URL when empty
+	token     string
+	apiKey    string
+}
+
+func (r *remote) define(fs *flag.FlagSet) {
+	fs.StringVar(&r.url, "server", "", "URL of a running server (e.g. http://127.0.0.1:5982); default in-process")
+	fs.StringVar(&r.transport, "server-transport", "", "sse or http (default: sse when the URL ends in /sse)")
+	fs.StringVar(&r.token, "token", os.Getenv("MCP_TOKEN"), "bearer token for --server (env MCP_TOKEN)")
+	fs.StringVar(&r.apiKey, "api-key", os.Getenv("MCP_API_KEY"), "X-API-Key for --server (env MCP_API_KEY)")
+}
+
+// connect opens an initialized MCP client: against r.url when set, else
+// in-process against a server built from cfg. yes confirms writes that
+// would otherwise wait for approval.
+func connect(ctx context.Context, cfg *config.Config, r remote, yes bool) (*client.Client, error) {
+	var (
+		c   *client.Client
+		err error
+	)
+	if r.url == "" {
+		s, err := localServer(cfg, yes)
+		if err != nil {
+			return nil, err
+		}
+		if c, err = client.NewInProcessClient(s); err != nil {
+			return nil, err
+		}
+	} else {
+		u, err := url.Parse(r.url)
+		if err != nil {
+			return nil, fmt.Errorf("--server: %w", err)
+		}
+		kind := r.transport
+		if kind == "" {
+			kind = "http"
+			if strings.HasSuffix(u.Path, transport.SSEPath) {
+				kind = "sse"
+			}
+		}
+		headers := map[string]string{}
+		if r.token != "" {
+			headers["Authorization"] = "Bearer " + r.token
+		}
+		if r.apiKey != "" {
+			headers["X-API-Key"] = r.apiKey
+		}
+		switch kind {
+		case "sse":
+			if u.Path == "" || u.Path == "/" {
+				u.Path = transport.SSEPath
+			}
+			c, err = client.NewSSEMCPClient(u.String(), mcptransport.WithHeaders(headers))
+		case "http":
+			if u.Path == "" || u.Path == "/" {
+				u.Path = transport.HTTPPath
+			}
+			c, err = client.NewStreamableHttpClient(u.String(), mcptransport.WithHTTPHeaders(headers))
+		default:
+			return nil, fmt.Errorf("--server-transport %q: want sse or http", kind)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err = c.Start(ctx); err != nil {
+		return nil, err
+	}
+	init := mcp.InitializeRequest{}
+	init.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	init.Params.ClientInfo = mcp.Implementation{Name: "mcp-bedrock-go-cli", Version: cfg.Server.Version}
+	if _, err := c.Initialize(ctx, init); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("initialize: %w", err)
+	}
+	return c, nil
+}
+
+// localServer builds the MCP server for in-process calls: the one serve
+// runs, with calls made as the stdio principal. A one-shot process cannot
+// wait in the approvals queue, so writes the approval rules gate need --yes
+// instead.
+func localServer(cfg *config.Config, yes bool) (*server.MCPServer, error) {
+	b, err := newBackends(cfg)
+	if err != nil {
+		return nil, err
+	}
+	s, err := newServer(cfg, b, &cliCaller{
+		principal: &auth.Principal{Subject: cfg.Auth.Stdio.Subject, Roles: cfg.Auth.Stdio.Roles, Method: "cli"},
+		yes:       yes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.MCPServer, nil
+}
+
+// needsYes refuses writes the approval rules gate; dry runs pass.
+func needsYes(rules approval.Rules) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if req.GetBool("dry_run", false) || !rules.Needs(req.Params.Name, req.GetArguments()) {
+				return next(ctx, req)
+			}
+			return mcp.NewToolResultError(approval.Summarize(req.Params.Name, req.GetArguments()) +
+				" needs approval: re-run with --yes to confirm, or call a running server with --server to queue it"), nil
+		}
+	}
+}
+
+// outputFlag adds -o and checks its value after parsing.
+func outputFlag(fs *flag.FlagSet, def string) *string {
+	return fs.String("o", def, "output format: json, table or yaml")
+}
+
+func checkOutput(format string) error {
+	switch format {
+	case "json", "table", "yaml":
+		return nil
+	}
+	return fmt.Errorf("-o %q: want json, table or yaml", format)
+}
@@ -0,0 +1,536 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mcp-bedrock-go/audit"
+	"mcp-bedrock-go/config"
+	outputformat "mcp-bedrock-go/format"
+	"mcp-bedrock-go/health"
+	"mcp-bedrock-go/internal/mockimport"
+	odoolib "mcp-bedrock-go/odoo"
+	"mcp-bedrock-go/tools"
+)
+
+// toolsList prints the registered tools, or those of a running server with
+// --server.
+func toolsList(args []string) error {
+	var (
+		r      remote
+		format *string
+	)
+	cfg, _, err := command("tools list", args, true, func(fs *flag.FlagSet) {
+		r.define(fs)
+		format = outputFlag(fs, "table")
+	})
+	if err != nil {
+		return err
+	}
+	if err := checkOutput(*format); err != nil {
+		return err
+	}
+
+	var (
+		list       []mcp.Tool
+		registered map[string]*server.ServerTool
+	)
+	if r.url == "" {
+		// Enabled means the in-process server registers it: tools.disabled
+		// and missing subsystems (approvals, search indexes) leave tools out
+		s, err := localServer(cfg, false)
+		if err != nil {
+			return err
+		}
+		registered = s.ListTools()
+		list = tools.Definitions()
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeouts.Odoo.Duration+30*time.Second)
+		defer cancel()
+		c, err := connect(ctx, cfg, r, false)
+		if err != nil {
+			return err
+		}
+		defer c.Close()
+		res, err := c.ListTools(ctx, mcp.ListToolsRequest{})
+		if err != nil {
+			return err
+		}
+		list = res.Tools
+		sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	}
+
+	rows := make([]map[string]any, len(list))
+	for i, t := range list {
+		row := map[string]any{
+			"name":        t.Name,
+			"description": t.Description,
+			"parameters":  paramSummary(t),
+		}
+		if r.url == "" {
+			row["enabled"] = registered[t.Name] != nil
+		}
+		if *format != "table" {
+			row["input_schema"] = t.InputSchema
+			if t.OutputSchema.Type != "" {
+				row["output_schema"] = t.OutputSchema
+			}
+		}
+		rows[i] = row
+	}
+	return printValue(*format, rows, []string{"name", "enabled", "parameters", "description"})
+}
+
+// paramSummary lists a tool's parameters as name:type, required ones
+// marked with *.
+func paramSummary(t mcp.Tool) string {
+	required := map[string]bool{}
+	for _, r := range t.InputSchema.Required {
+		required[r] = true
+	}
+	names := make([]string, 0, len(t.InputSchema.Properties))
+	for name := range t.InputSchema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		prop, _ := t.InputSchema.Properties[name].(map[string]any)
+		typ, _ := prop["type"].(string)
+		if f, ok := prop["format"].(string); ok {
+			typ = f
+		}
+		parts[i] = name + ":" + typ
+		if required[name] {
+			parts[i] += "*"
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// argList collects repeated --arg k=v flags. k:=v takes v as JSON
+// (k:='[1,2]'); plain values are strings, coerced by the tool's schema.
+type argList map[string]any
+
+func (a argList) String() string { return "" }
+
+func (a argList) Set(s string) error {
+	if k, v, ok := strings.Cut(s, ":="); ok && !strings.Contains(k, "=") {
+		var val any
+		if err := json.Unmarshal([]byte(v), &val); err != nil {
+			return fmt.Errorf("%s: %w", k, err)
+		}
+		a[k] = val
+		return nil
+	}
+	k, v, ok := strings.Cut(s, "=")
+	if !ok || k == "" {
+		return fmt.Errorf("%q: want key=value", s)
+	}
+	a[k] = v
+	return nil
+}
+
+// call runs one tool and prints its result.
+func call(args []string) error {
+	name := ""
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	var (
+		r       remote
+		format  *string
+		yes     *bool
+		timeout *time.Duration
+		jsonArg *string
+		toolArg = argList{}
+	)
+	cfg, fs, err := command("call", args, true, func(fs *flag.FlagSet) {
+		r.define(fs)
+		format = outputFlag(fs, "json")
+		fs.Var(toolArg, "arg", "tool argument key=value, or key:=<json>; repeatable")
+		jsonArg = fs.String("args", "", "tool arguments as a JSON object; --arg values override")
+		yes = fs.Bool("yes", false, "confirm writes the approval rules would hold (in-process only)")
+		timeout = fs.Duration("timeout", 2*time.Minute, "how long to wait for the result")
+	})
+	if err != nil {
+		return err
+	}
+	if name == "" && fs.NArg() > 0 {
+		name = fs.Arg(0)
+	}
+	if name == "" {
+		return fmt.Errorf("usage: mcp-bedrock-go call <tool> [--arg key=value ...]")
+	}
+	if err := checkOutput(*format); err != nil {
+		return err
+	}
+	arguments := map[string]any{}
+	if *jsonArg != "" {
+		if err := json.Unmarshal([]byte(*jsonArg), &arguments); err != nil {
+			return fmt.Errorf("--args: %w", err)
+		}
+	}
+	for k, v := range toolArg {
+		arguments[k] = v
+	}
+
+	if r.url == "" {
+		// In-process calls are traced like served ones
+		stopTracing, err := startTracing(cfg)
+		if err != nil {
+			return fmt.Errorf("tracing setup: %w", err)
+		}
+		defer func() {
+			flushCtx, cancel := context.WithTimeout(context.Background(), cfg.Timeouts.Shutdown.Duration)
+			defer cancel()
+			stopTracing(flushCtx)
+		}()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	c, err := connect(ctx, cfg, r, *yes)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	req := mcp.CallToolRequest{}
+	req.Params.Name = name
+	req.Params.Arguments = arguments
+	res, err := c.CallTool(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	var texts []string
+	for _, content := range res.Content {
+		if tc, ok := content.(mcp.TextContent); ok {
+			texts = append(texts, tc.Text)
+		}
+	}
+	var out any = strings.Join(texts, "\n")
+	if len(texts) > 0 {
+		var v any
+		if json.Unmarshal([]byte(texts[0]), &v) == nil {
+			out = v
+		}
+	}
+	if res.IsError {
+		if msg, ok := out.(string); ok {
+			fmt.Fprintf(os.Stderr, "%s failed: %s\n", name, msg)
+			return errFailed
+		}
+		fmt.Fprintf(os.Stderr, "%s failed:\n", name)
+		printValue(*format, out, nil)
+		return errFailed
+	}
+	if arguments[outputformat.Arg] != nil {
+		// the server already rendered it as asked
+		fmt.Println(strings.Join(texts, "\n"))
+		return nil
+	}
+	return printValue(*format, out, nil)
+}
+
+// importMock loads a mock.json into the configured Odoo.
+func importMock(args []string) error {
+	var (
+		file   *string
+		dryRun *bool
+		format *string
+	)
+	cfg, _, err := command("import-mock", args, true, func(fs *flag.FlagSet) {
+		file = fs.String("file", "mocks/mock.json", "mock data to import")
+		dryRun = fs.Bool("dry-run", false, "report what would be created without writing")
+		format = outputFlag(fs, "table")
+	})
+	if err != nil {
+		return err
+	}
+	if err := checkOutput(*format); err != nil {
+		return err
+	}
+	data, err := mockimport.Load(*file)
+	if err != nil {
+		return err
+	}
+	prof := cfg.ActiveOdoo()
+	oc := odoolib.New(prof.URL, prof.DB, prof.Username, prof.APIKey)
+	oc.HTTP.Timeout = cfg.Timeouts.Odoo.Duration
+	if err := oc.Login(); err != nil {
+		return fmt.Errorf("Odoo login (profile %s): %w", cfg.Odoo.Profile, err)
+	}
+
+	recs, err := mockimport.Import(context.Background(), oc, data, *dryRun)
+	if perr := printValue(*format, recs, []string{"model", "ref", "id", "status"}); perr != nil {
+		return perr
+	}
+	return err
+}
+
+// doctor checks the configuration and every dependency once and prints a
+// report; it fails when a critical check does.
+func doctor(args []string) error {
+	var format *string
+	cfg, _, err := command("doctor", args, true, func(fs *flag.FlagSet) {
+		format = outputFlag(fs, "table")
+	})
+	if err != nil {
+		return err
+	}
+	if err := checkOutput(*format); err != nil {
+		return err
+	}
+
+	checks := health.New(cfg.Health.Timeout.Duration, 0)
+	checks.Add("config", true, func(context.Context) (string, error) {
+		return configDetail(cfg), nil
+	})
+	checks.Add("tool_registry", true, func(context.Context) (string, error) {
+		if err := tools.Check(); err != nil {
+			return "", err
+		}
+		if err := cfg.ValidateTools(tools.Names()); err != nil {
+			return "", err
+		}
+		enabled := 0
+		for _, n := range tools.Names() {
+			if cfg.ToolEnabled(n) {
+				enabled++
+			}
+		}
+		return fmt.Sprintf("%d tools, %d enabled", len(tools.Names()), enabled), nil
+	})
+	b, err := newBackends(cfg)
+	if err != nil {
+		checks.Add("backends", true, func(context.Context) (string, error) { return "", err })
+	} else {
+		checks.Add("odoo", true, health.Odoo(b.odoo))
+		checks.Add("bedrock_credentials", true, health.AWSCredentials(b.awsCfg))
+		checks.Add("bedrock_models", false, health.Models(b.llm))
+	}
+	if u := cfg.Odoo.Users; u.Enabled && len(u.Map) > 0 {
+		checks.Add("odoo_users", false, func(context.Context) (string, error) {
+			prof := cfg.ActiveOdoo()
+			subjects := make([]string, 0, len(u.Map))
+			for s := range u.Map {
+				subjects = append(subjects, s)
+			}
+			sort.Strings(subjects)
+			var failed []string
+			for _, subject := range subjects {
+				c := odoolib.New(prof.URL, prof.DB, u.Map[subject].Username, u.Map[subject].APIKey)
+				c.HTTP.Timeout = cfg.Timeouts.Odoo.Duration
+				if err := c.Login(); err != nil {
+					failed = append(failed, fmt.Sprintf("%s (%s): %v", subject, u.Map[subject].Username, err))
+				}
+			}
+			if len(failed) > 0 {
+				return "", fmt.Errorf("%s", strings.Join(failed, "; "))
+			}
+			return fmt.Sprintf("%d mapped users log in", len(u.Map)), nil
+		})
+	}
+	if cfg.Audit.Enabled {
+		checks.Add("audit_chain", false, func(context.Context) (string, error) {
+			l, err := audit.Open(cfg.Audit.File)
+			if err != nil {
+				return "", err
+			}
+			v, err := l.Verify()
+			if err != nil {
+				return "", err
+			}
+			if !v.OK {
+				return "", fmt.Errorf("%s: entry %d: %s", cfg.Audit.File, v.BrokenAt, v.Problem)
+			}
+			return fmt.Sprintf("%s: %d entries, chain intact", cfg.Audit.File, v.Entries), nil
+		})
+	}
+	if cfg.Features.Retrieval {
+		checks.Add("docs", false, func(context.Context) (string, error) {
+			entries, err := os.ReadDir(cfg.Docs.Dir)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("%s: %d files", cfg.Docs.Dir, len(entries)), nil
+		})
+	}
+	if cfg.HasTransport("sse") || cfg.HasTransport("http") {
+		checks.Add("listen", true, func(context.Context) (string, error) {
+			l, err := net.Listen("tcp", cfg.Server.Listen)
+			if err != nil {
+				return "", err
+			}
+			l.Close()
+			return cfg.Server.Listen + " is free", nil
+		})
+	}
+
+	rep := checks.Check(context.Background(), 0)
+	rows := make([]map[string]any, len(rep.Checks))
+	for i, c := range rep.Checks {
+		detail := c.Detail
+		if c.Status != health.StatusOK {
+			detail = c.LastError
+		}
+		rows[i] = map[string]any{"check": c.Name, "status": c.Status, "critical": c.Critical, "latency_ms": c.LatencyMS, "detail": detail}
+	}
+	var out any = rows
+	if *format != "table" {
+		out = map[string]any{"status": rep.Status, "checks": rows}
+	}
+	if err := printValue(*format, out, []string{"check", "status", "critical", "latency_ms", "detail"}); err != nil {
+		return err
+	}
+	if rep.Status == "fail" {
+		return errFailed
+	}
+	return nil
+}
+
+// configDetail summarises where the configuration points.
+func configDetail(cfg *config.Config) string {
+	prof := cfg.ActiveOdoo()
+	return fmt.Sprintf("odoo profile %s (%s), transports %s, models %s",
+		cfg.Odoo.Profile, prof.URL, strings.Join(cfg.Server.Transports, ","), strings.Join(cfg.Models.Default, ","))
+}
+
+// openAudit opens the configured audit log, or the one named by --file,
+// refusing to create a missing one.
+func openAudit(cfg *config.Config, file string) (*audit.Log, error) {
+	if file == "" {
+		file = cfg.Audit.File
+	}
+	if _, err := os.Stat(file); err != nil {
+		return nil, err
+	}
+	return audit.Open(file)
+}
+
+// auditExport writes the audit log, oldest first, for archiving or review.
+func auditExport(args []string) error {
+	var (
+		file, since, until *string
+		f                  audit.Filter
+		format             *string
+	)
+	cfg, _, err := command("audit export", args, true, func(fs *flag.FlagSet) {
+		file = fs.String("file", "", "audit log (default audit.file)")
+		fs.StringVar(&f.Tool, "tool", "", "only calls of this tool")
+		fs.StringVar(&f.Subject, "subject", "", "only calls by this principal")
+		fs.StringVar(&f.SessionID, "session", "", "only calls from this MCP session")
+		fs.StringVar(&f.Record, "record", "", "only calls that created this record id or name, or had this argument value")
+		fs.StringVar(&f.Outcome, "outcome", "", "ok, error, pending or failure")
+		since = fs.String("since", "", "calls at or after this time (RFC 3339 or YYYY-MM-DD)")
+		until = fs.String("until", "", "calls at or before this time (RFC 3339 or YYYY-MM-DD)")
+		fs.IntVar(&f.Limit, "limit", 0, "only the most recent n calls")
+		format = fs.String("o", "jsonl", "output format: jsonl, json, table or yaml")
+	})
+	if err != nil {
+		return err
+	}
+	if *format != "jsonl" {
+		if err := checkOutput(*format); err != nil {
+			return err
+		}
+	}
+	if f.Since, err = cliTime(*since, false); err != nil {
+		return fmt.Errorf("--since: %w", err)
+	}
+	if f.Until, err = cliTime(*until, true); err != nil {
+		return fmt.Errorf("--until: %w", err)
+	}
+	l, err := openAudit(cfg, *file)
+	if err != nil {
+		return err
+	}
+	entries, err := l.Query(f)
+	if err != nil {
+		return err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Seq < entries[j].Seq })
+
+	switch *format {
+	case "jsonl":
+		enc := json.NewEncoder(os.Stdout)
+		for _, e := range entries {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "table":
+		rows := make([]map[string]any, len(entries))
+		for i, e := range entries {
+			var recs []string
+			for _, r := range e.Records {
+				ref := fmt.Sprintf("%s/%d", r.Model, r.ID)
+				if r.Name != "" {
+					ref += " " + r.Name
+				}
+				recs = append(recs, ref)
+			}
+			rows[i] = map[string]any{
+				"seq": e.Seq, "time": e.Time.Format(time.DateTime), "subject": e.Subject, "tool": e.Tool,
+				"outcome": e.Outcome, "records": strings.Join(recs, ", "), "latency_ms": e.LatencyMS,
+			}
+		}
+		return printValue(*format, rows, []string{"seq", "time", "subject", "tool", "outcome", "records", "latency_ms"})
+	}
+	return printValue(*format, entries, nil)
+}
+
+// auditVerify checks the hash chain and fails when it is broken.
+func auditVerify(args []string) error {
+	var (
+		file   *string
+		format *string
+	)
+	cfg, _, err := command("audit verify", args, true, func(fs *flag.FlagSet) {
+		file = fs.String("file", "", "audit log (default audit.file)")
+		format = outputFlag(fs, "table")
+	})
+	if err != nil {
+		return err
+	}
+	if err := checkOutput(*format); err != nil {
+		return err
+	}
+	l, err := openAudit(cfg, *file)
+	if err != nil {
+		return err
+	}
+	v, err := l.Verify()
+	if err != nil {
+		return err
+	}
+	if err := printValue(*format, v, nil); err != nil {
+		return err
+	}
+	if !v.OK {
+		return errFailed
+	}
+	return nil
+}
+
+// cliTime parses a --since/--until value: RFC 3339, "YYYY-MM-DD HH:MM:SS"
+// (UTC) or a date at the plant.
+func cliTime(s string, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return tools.AuditTime(s, end)
+}
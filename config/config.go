@@ -0,0 +1,861 @@
+// Package config loads server settings from defaults, a YAML or TOML file,
+// environment variables and command-line flags, in that order of precedence
+// (later sources win), and validates the result at startup.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+
+	"mcp-bedrock-go/bedrock"
+	"mcp-bedrock-go/internal/logging"
+	"mcp-bedrock-go/policy"
+	"mcp-bedrock-go/transport"
+)
+
+// Config is the full server configuration.
+type Config struct {
+	Server       Server            `yaml:"server" toml:"server"`
+	Tools        Tools             `yaml:"tools" toml:"tools"`
+	Models       Models            `yaml:"models" toml:"models"`
+	Odoo         Odoo              `yaml:"odoo" toml:"odoo"`
+	Timeouts     Timeouts          `yaml:"timeouts" toml:"timeouts"`
+	Features     Features          `yaml:"features" toml:"features"`
+	Conversation Conversation      `yaml:"conversation" toml:"conversation"`
+	Docs         Docs              `yaml:"docs" toml:"docs"`
+	Auth         Auth              `yaml:"auth" toml:"auth"`
+	Authz        Authz             `yaml:"authz" toml:"authz"`
+	Approval     Approval          `yaml:"approval" toml:"approval"`
+	Idempotency  Idempotency       `yaml:"idempotency" toml:"idempotency"`
+	Audit        Audit             `yaml:"audit" toml:"audit"`
+	Resources    Resources         `yaml:"resources" toml:"resources"`
+	Health       Health            `yaml:"health" toml:"health"`
+	Tracing      Tracing           `yaml:"tracing" toml:"tracing"`
+	Logging      Logging           `yaml:"logging" toml:"logging"`
+	Source       map[string]string `yaml:"-" toml:"-"` // setting → where it came from, for diagnostics
+
+	envErrs []string
+}
+
+// Server covers the MCP server identity and how it is exposed.
+// AllowInsecure lets the sse and http transports listen beyond loopback
+// without authentication, e.g. behind a proxy that authenticates.
+type Server struct {
+	Name          string   `yaml:"name" toml:"name"`
+	Version       string   `yaml:"version" toml:"version"`
+	Listen        string   `yaml:"listen" toml:"listen"`
+	Transports    []string `yaml:"transports" toml:"transports"`
+	AllowInsecure bool     `yaml:"allow_insecure" toml:"allow_insecure"`
+	Timezone      string   `yaml:"timezone" toml:"timezone"` // plant time zone (IANA name); "" = the host's
+}
+
+// Tools selects which tools are registered. An empty Enabled list means all.
+type Tools struct {
+	Enabled  []string `yaml:"enabled" toml:"enabled"`
+	Disabled []string `yaml:"disabled" toml:"disabled"`
+}
+
+// Models holds the Bedrock fallback chain and per-tool overrides. Policies
+// replace the chain's built-in reaction to an error class (throttling,
+// validation, access_denied, unavailable, other).
+type Models struct {
+	Default   []string               `yaml:"default" toml:"default"`
+	PerTool   map[string][]string    `yaml:"per_tool" toml:"per_tool"`
+	Embedding string                 `yaml:"embedding" toml:"embedding"`
+	Policies  map[string]ModelPolicy `yaml:"policies" toml:"policies"`
+}
+
+// ModelPolicy is how the fallback chain reacts to one error class: retry the
+// same model, fall back to the next one or fail the call.
+type ModelPolicy struct {
+	Action   string   `yaml:"action" toml:"action"`
+	Retries  int      `yaml:"retries" toml:"retries"`   // extra attempts when action is retry
+	Backoff  Duration `yaml:"backoff" toml:"backoff"`   // wait between retries, doubled each time
+	Cooldown Duration `yaml:"cooldown" toml:"cooldown"` // skip the model for this long afterwards
+}
+
+// ModelPolicies returns the fallback chain's policies: the built-in ones
+// with the configured classes replaced.
+func (c *Config) ModelPolicies() map[bedrock.ErrorClass]bedrock.Policy {
+	out := bedrock.DefaultPolicies()
+	for class, p := range c.Models.Policies {
+		out[bedrock.ErrorClass(class)] = bedrock.Policy{
+			Action:   bedrock.Action(p.Action),
+			Retries:  p.Retries,
+			Backoff:  p.Backoff.Duration,
+			Cooldown: p.Cooldown.Duration,
+		}
+	}
+	return out
+}
+
+// Odoo names the active connection profile among several.
+type Odoo struct {
+	Profile  string                 `yaml:"profile" toml:"profile"`
+	Profiles map[string]OdooProfile `yaml:"profiles" toml:"profiles"`
+	Users    OdooUsers              `yaml:"users" toml:"users"`
+}
+
+// OdooUsers runs tool calls as the caller's own Odoo user instead of the
+// profile's service account. A principal's Odoo login comes from Map, or,
+// when Delegated is set, from the X-Odoo-Login and X-Odoo-API-Key headers
+// of the request that opened the session. Fallback decides what happens to
+// callers with neither: "service" uses the service account, "deny" refuses
+// the call.
+type OdooUsers struct {
+	Enabled   bool                `yaml:"enabled" toml:"enabled"`
+	Delegated bool                `yaml:"delegated" toml:"delegated"`
+	Fallback  string              `yaml:"fallback" toml:"fallback"`
+	IdleTTL   Duration            `yaml:"idle_ttl" toml:"idle_ttl"` // drop a user's client after this long unused
+	Map       map[string]OdooUser `yaml:"map" toml:"map"`           // principal subject → Odoo login
+}
+
+// OdooUser is one Odoo login with its API key.
+type OdooUser struct {
+	Username string `yaml:"username" toml:"username"`
+	APIKey   string `yaml:"api_key" toml:"api_key"`
+}
+
+// OdooProfile is one Odoo connection.
+type OdooProfile struct {
+	URL      string `yaml:"url" toml:"url"`
+	DB       string `yaml:"db" toml:"db"`
+	Username string `yaml:"username" toml:"username"`
+	APIKey   string `yaml:"api_key" toml:"api_key"`
+}
+
+// Timeouts bounds outbound calls and shutdown.
+type Timeouts struct {
+	Odoo     Duration `yaml:"odoo" toml:"odoo"`
+	LLM      Duration `yaml:"llm" toml:"llm"`
+	Shutdown Duration `yaml:"shutdown" toml:"shutdown"`
+}
+
+// Features are on/off switches for optional subsystems.
+type Features struct {
+	Conversations bool `yaml:"conversations" toml:"conversations"`
+	Retrieval     bool `yaml:"retrieval" toml:"retrieval"`
+	ProductSearch bool `yaml:"product_search" toml:"product_search"`
+	DryRun        bool `yaml:"dry_run" toml:"dry_run"` // write tools never call Create
+	Resources     bool `yaml:"resources" toml:"resources"`
+	Prompts       bool `yaml:"prompts" toml:"prompts"`
+	Metrics       bool `yaml:"metrics" toml:"metrics"` // Prometheus /metrics
+	REST          bool `yaml:"rest" toml:"rest"`       // /api/v1 tool endpoints and OpenAPI document
+}
+
+// Conversation tunes follow-up memory.
+type Conversation struct {
+	TTL       Duration `yaml:"ttl" toml:"ttl"`
+	MaxChars  int      `yaml:"max_chars" toml:"max_chars"`
+	KeepTurns int      `yaml:"keep_turns" toml:"keep_turns"`
+}
+
+// Resources tunes the odoo:// MCP resources.
+type Resources struct {
+	PollInterval Duration `yaml:"poll_interval" toml:"poll_interval"` // how often subscribed records are checked for changes
+}
+
+// Health tunes the /healthz and /readyz dependency probes.
+type Health struct {
+	Interval Duration `yaml:"interval" toml:"interval"`   // background refresh behind /healthz
+	Timeout  Duration `yaml:"timeout" toml:"timeout"`     // per probe
+	CacheTTL Duration `yaml:"cache_ttl" toml:"cache_ttl"` // /readyz reuses results younger than this
+	Public   bool     `yaml:"public" toml:"public"`       // serve both paths without credentials when auth is enabled
+}
+
+// Tracing configures span export. Exporter is "" (off), "otlp" (OTLP/JSON
+// over HTTP to Endpoint) or "file" (one OTLP/JSON request per line in File).
+type Tracing struct {
+	Exporter string            `yaml:"exporter" toml:"exporter"`
+	Endpoint string            `yaml:"endpoint" toml:"endpoint"`
+	Headers  map[string]string `yaml:"headers" toml:"headers"`
+	File     string            `yaml:"file" toml:"file"`
+}
+
+// Logging configures the structured logger. Components are the package
+// names (odoo, bedrock, auth, approval, transport, ...) and "app".
+type Logging struct {
+	Format     string            `yaml:"format" toml:"format"` // json or text
+	Level      string            `yaml:"level" toml:"level"`
+	Components map[string]string `yaml:"components" toml:"components"` // component → level
+	Redact     []string          `yaml:"redact" toml:"redact"`         // attribute names to mask besides logging.DefaultRedact
+}
+
+// Docs configures the document retrieval index.
+type Docs struct {
+	Dir string `yaml:"dir" toml:"dir"`
+}
+
+// Auth configures authentication of the HTTP transports. Any combination of
+// methods may be enabled; the first one that recognises a request's
+// credentials decides.
+type Auth struct {
+	Enabled bool         `yaml:"enabled" toml:"enabled"`
+	APIKeys []AuthAPIKey `yaml:"api_keys" toml:"api_keys"`
+	HMAC    AuthHMAC     `yaml:"hmac" toml:"hmac"`
+	JWT     AuthJWT      `yaml:"jwt" toml:"jwt"`
+	Exempt  []string     `yaml:"exempt" toml:"exempt"` // paths served without credentials
+	Stdio   AuthStdio    `yaml:"stdio" toml:"stdio"`
+}
+
+// AuthAPIKey is one static key and the principal it stands for.
+type AuthAPIKey struct {
+	Key     string   `yaml:"key" toml:"key"`
+	Subject string   `yaml:"subject" toml:"subject"`
+	Roles   []string `yaml:"roles" toml:"roles"`
+}
+
+// AuthHMAC enables bearer tokens signed with a shared secret.
+type AuthHMAC struct {
+	Secret string `yaml:"secret" toml:"secret"`
+	Issuer string `yaml:"issuer" toml:"issuer"`
+}
+
+// AuthJWT enables JWTs verified against a local JWKS file.
+type AuthJWT struct {
+	JWKSFile   string `yaml:"jwks_file" toml:"jwks_file"`
+	Issuer     string `yaml:"issuer" toml:"issuer"`
+	Audience   string `yaml:"audience" toml:"audience"`
+	RolesClaim string `yaml:"roles_claim" toml:"roles_claim"`
+}
+
+// AuthStdio is the principal given to the stdio client, which is the local
+// user who launched the server.
+type AuthStdio struct {
+	Subject string   `yaml:"subject" toml:"subject"`
+	Roles   []string `yaml:"roles" toml:"roles"`
+}
+
+// Authz configures role-based authorization. Roles defined here replace the
+// built-in viewer, planner, supervisor and admin roles of the same name.
+type Authz struct {
+	Enabled       bool                   `yaml:"enabled" toml:"enabled"`
+	AnonymousRole string                 `yaml:"anonymous_role" toml:"anonymous_role"` // role for callers without credentials; "" denies
+	Roles         map[string]policy.Role `yaml:"roles" toml:"roles"`
+}
+
+// Policy builds the authorization engine from the built-in and configured roles.
+func (c *Config) Policy() (*policy.Engine, error) {
+	return policy.New(policy.Merge(policy.Defaults(), c.Authz.Roles), c.Authz.AnonymousRole)
+}
+
+// Approval puts a person in the loop before costly writes. Rules map a tool
+// to argument thresholds; a tool with no thresholds always needs approval.
+type Approval struct {
+	Enabled           bool                          `yaml:"enabled" toml:"enabled"`
+	TTL               Duration                      `yaml:"ttl" toml:"ttl"`
+	AllowSelfApproval bool                          `yaml:"allow_self_approval" toml:"allow_self_approval"`
+	Rules             map[string]map[string]float64 `yaml:"rules" toml:"rules"`
+}
+
+// Idempotency lets clients retry write tools without creating duplicates.
+// A call with an idempotency_key returns the first result for Window; a call
+// without one is matched on its session and arguments for DeriveWindow (0
+// turns that off).
+type Idempotency struct {
+	Enabled      bool     `yaml:"enabled" toml:"enabled"`
+	File         string   `yaml:"file" toml:"file"` // "" keeps keys in memory only
+	Window       Duration `yaml:"window" toml:"window"`
+	DeriveWindow Duration `yaml:"derive_window" toml:"derive_window"`
+}
+
+// Audit keeps the hash-chained log of tool calls read by query_audit and
+// "mcp-bedrock-go audit".
+type Audit struct {
+	Enabled bool   `yaml:"enabled" toml:"enabled"`
+	File    string `yaml:"file" toml:"file"` // JSON lines, appended to
+}
+
+// Duration accepts Go duration strings ("15s", "2m") in YAML and TOML.
+type Duration struct{ time.Duration }
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Duration) UnmarshalText(b []byte) error {
+	v, err := time.ParseDuration(string(b))
+	if err != nil {
+		return err
+	}
+	d.Duration = v
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Duration) MarshalText() ([]byte, error) { return []byte(d.String()), nil }
+
+// KnownTransports lists the transport names accepted in Server.Transports:
+// stdio, sse and http (Streamable HTTP).
+var KnownTransports = transport.Names
+
+// Default returns the built-in configuration.
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			Name:       "bedrock-mcp",
+			Version:    "1.0.0",
+			Listen:     "127.0.0.1:5982",
+			Transports: []string{transport.HTTP},
+		},
+		Models: Models{
+			Default: []string{
+				"us.meta.llama3-1-70b-instruct-v1:0",
+				"us.meta.llama3-3-70b-instruct-v1:0",
+				"us.meta.llama3-1-8b-instruct-v1:0",
+			},
+		},
+		Odoo: Odoo{
+			Profile:  "default",
+			Profiles: map[string]OdooProfile{"default": {}},
+			Users:    OdooUsers{Fallback: "service", IdleTTL: Duration{30 * time.Minute}},
+		},
+		Timeouts: Timeouts{
+			Odoo:     Duration{15 * time.Second},
+			LLM:      Duration{60 * time.Second},
+			Shutdown: Duration{10 * time.Second},
+		},
+		Features:     Features{Conversations: true, Retrieval: true, ProductSearch: true, Resources: true, Prompts: true, Metrics: true, REST: true},
+		Conversation: Conversation{TTL: Duration{30 * time.Minute}, MaxChars: 12000, KeepTurns: 4},
+		Docs:         Docs{Dir: "docs"},
+		Resources:    Resources{PollInterval: Duration{30 * time.Second}},
+		Health:       Health{Interval: Duration{15 * time.Second}, Timeout: Duration{5 * time.Second}, CacheTTL: Duration{5 * time.Second}, Public: true},
+		Approval: Approval{
+			TTL:   Duration{time.Hour},
+			Rules: map[string]map[string]float64{"create_mo": {"qty": 1000}, "add_product": {}},
+		},
+		Idempotency: Idempotency{Enabled: true, File: "idempotency.json", Window: Duration{24 * time.Hour}, DeriveWindow: Duration{2 * time.Minute}},
+		Audit:       Audit{Enabled: true, File: "audit.jsonl"},
+		Logging:     Logging{Format: "json", Level: "info"},
+		Tracing:     Tracing{Endpoint: "http://localhost:4318/v1/traces", File: "traces.jsonl"},
+		Auth:        Auth{Stdio: AuthStdio{Subject: "local", Roles: []string{"admin"}}},
+		Source:      map[string]string{},
+	}
+}
+
+// Load builds the configuration from defaults, the file named by -config or
+// MCP_CONFIG, the environment and finally the command-line flags in args.
+func Load(args []string) (*Config, error) {
+	fs := flag.NewFlagSet("mcp-bedrock-go", flag.ContinueOnError)
+	load := Bind(fs)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	return load()
+}
+
+// Bind registers the configuration flags on fs, so subcommands can mix them
+// with their own. The returned function builds the configuration once fs
+// has been parsed.
+func Bind(fs *flag.FlagSet) func() (*Config, error) {
+	path := fs.String("config", os.Getenv("MCP_CONFIG"), "YAML or TOML config file")
+	listen := fs.String("listen", "", "HTTP listen address, e.g. 127.0.0.1:5982")
+	insecure := fs.Bool("allow-insecure", false, "serve HTTP beyond loopback without authentication")
+	transports := fs.String("transport", "", "comma-separated transports: "+strings.Join(KnownTransports, ", "))
+	models := fs.String("models", "", "comma-separated default model fallback chain")
+	profile := fs.String("odoo-profile", "", "Odoo connection profile to use")
+	enabled := fs.String("tools", "", "comma-separated tools to enable (default all)")
+
+	return func() (*Config, error) {
+		cfg := Default()
+		if *path != "" {
+			if err := cfg.loadFile(*path); err != nil {
+				return nil, err
+			}
+		}
+		cfg.applyEnv(*profile)
+
+		set := func(key, val string, apply func(string)) {
+			if val != "" {
+				apply(val)
+				cfg.Source[key] = "flag"
+			}
+		}
+		set("server.listen", *listen, func(v string) { cfg.Server.Listen = v })
+		set("server.transports", *transports, func(v string) { cfg.Server.Transports = splitList(v) })
+		set("models.default", *models, func(v string) { cfg.Models.Default = splitList(v) })
+		set("tools.enabled", *enabled, func(v string) { cfg.Tools.Enabled = splitList(v) })
+		if *insecure {
+			cfg.Server.AllowInsecure = true
+			cfg.Source["server.allow_insecure"] = "flag"
+		}
+
+		if err := cfg.Validate(); err != nil {
+			return nil, err
+		}
+		return cfg, nil
+	}
+}
+
+func (c *Config) loadFile(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(strings.NewReader(string(b)))
+		dec.KnownFields(true)
+		if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("config file %s: %w", path, err)
+		}
+	case ".toml":
+		md, err := toml.Decode(string(b), c)
+		if err != nil {
+			return fmt.Errorf("config file %s: %w", path, err)
+		}
+		if undec := md.Undecoded(); len(undec) > 0 {
+			keys := make([]string, len(undec))
+			for i, k := range undec {
+				keys[i] = k.String()
+			}
+			return fmt.Errorf("config file %s: unknown keys: %s", path, strings.Join(keys, ", "))
+		}
+	default:
+		return fmt.Errorf("config file %s: unsupported extension (use .yaml, .yml or .toml)", path)
+	}
+	c.Source["file"] = path
+	return nil
+}
+
+// applyEnv overlays the environment variables the server has always read
+// (ODOO_*, BEDROCK_MODEL_IDS, DOCS_*) plus MCP_* server settings. The Odoo
+// profile flag is applied here so ODOO_* land in the profile actually used.
+func (c *Config) applyEnv(profileFlag string) {
+	str := func(key string, dst *string) {
+		if v := os.Getenv(key); v != "" {
+			*dst = v
+			c.Source[key] = "env"
+		}
+	}
+	list := func(key string, dst *[]string) {
+		if v := os.Getenv(key); v != "" {
+			*dst = splitList(v)
+			c.Source[key] = "env"
+		}
+	}
+	dur := func(key string, dst *Duration) {
+		if v := os.Getenv(key); v != "" {
+			if d, err := time.ParseDuration(v); err == nil {
+				dst.Duration = d
+				c.Source[key] = "env"
+			} else {
+				c.envErrs = append(c.envErrs, fmt.Sprintf("%s: %q is not a duration such as \"30s\"", key, v))
+			}
+		}
+	}
+	boolean := func(key string, dst *bool) {
+		if v := os.Getenv(key); v != "" {
+			if b, err := strconv.ParseBool(v); err == nil {
+				*dst = b
+				c.Source[key] = "env"
+			} else {
+				c.envErrs = append(c.envErrs, fmt.Sprintf("%s: %q is not a boolean", key, v))
+			}
+		}
+	}
+
+	str("MCP_SERVER_NAME", &c.Server.Name)
+	str("MCP_LISTEN", &c.Server.Listen)
+	boolean("MCP_ALLOW_INSECURE", &c.Server.AllowInsecure)
+	str("PLANT_TIMEZONE", &c.Server.Timezone)
+	list("MCP_TRANSPORTS", &c.Server.Transports)
+	list("MCP_TOOLS", &c.Tools.Enabled)
+	list("BEDROCK_MODEL_IDS", &c.Models.Default)
+	str("DOCS_EMBED_MODEL", &c.Models.Embedding)
+	str("DOCS_DIR", &c.Docs.Dir)
+	str("ODOO_PROFILE", &c.Odoo.Profile)
+	if profileFlag != "" {
+		c.Odoo.Profile = profileFlag
+		c.Source["odoo.profile"] = "flag"
+	}
+	dur("ODOO_TIMEOUT", &c.Timeouts.Odoo)
+	boolean("ODOO_USERS_ENABLED", &c.Odoo.Users.Enabled)
+	boolean("ODOO_USERS_DELEGATED", &c.Odoo.Users.Delegated)
+	str("ODOO_USERS_FALLBACK", &c.Odoo.Users.Fallback)
+	dur("LLM_TIMEOUT", &c.Timeouts.LLM)
+	boolean("FEATURE_CONVERSATIONS", &c.Features.Conversations)
+	boolean("FEATURE_RETRIEVAL", &c.Features.Retrieval)
+	boolean("FEATURE_PRODUCT_SEARCH", &c.Features.ProductSearch)
+	boolean("FEATURE_DRY_RUN", &c.Features.DryRun)
+	boolean("FEATURE_RESOURCES", &c.Features.Resources)
+	boolean("FEATURE_PROMPTS", &c.Features.Prompts)
+	boolean("FEATURE_METRICS", &c.Features.Metrics)
+	boolean("FEATURE_REST", &c.Features.REST)
+	boolean("MCP_AUTH_ENABLED", &c.Auth.Enabled)
+	boolean("MCP_AUTHZ_ENABLED", &c.Authz.Enabled)
+	boolean("MCP_APPROVAL_ENABLED", &c.Approval.Enabled)
+	boolean("MCP_AUDIT_ENABLED", &c.Audit.Enabled)
+	str("MCP_AUDIT_FILE", &c.Audit.File)
+	boolean("MCP_IDEMPOTENCY_ENABLED", &c.Idempotency.Enabled)
+	str("MCP_IDEMPOTENCY_FILE", &c.Idempotency.File)
+	dur("MCP_IDEMPOTENCY_WINDOW", &c.Idempotency.Window)
+	str("MCP_AUTH_HMAC_SECRET", &c.Auth.HMAC.Secret)
+	str("MCP_AUTH_JWKS_FILE", &c.Auth.JWT.JWKSFile)
+	if v := os.Getenv("DEBUG"); v == "1" || v == "true" {
+		c.Logging.Level = "debug"
+		c.Source["DEBUG"] = "env"
+	}
+	str("LOG_LEVEL", &c.Logging.Level)
+	str("LOG_FORMAT", &c.Logging.Format)
+	str("MCP_TRACING_EXPORTER", &c.Tracing.Exporter)
+	str("MCP_TRACING_FILE", &c.Tracing.File)
+	if v := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); v != "" {
+		c.Tracing.Endpoint = strings.TrimRight(v, "/") + "/v1/traces"
+		c.Source["OTEL_EXPORTER_OTLP_ENDPOINT"] = "env"
+	}
+	str("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", &c.Tracing.Endpoint)
+
+	// ODOO_* fill the active profile, as before profiles existed.
+	if c.Odoo.Profiles == nil {
+		c.Odoo.Profiles = map[string]OdooProfile{}
+	}
+	p := c.Odoo.Profiles[c.Odoo.Profile]
+	str("ODOO_URL", &p.URL)
+	str("ODOO_DB", &p.DB)
+	str("ODOO_USERNAME", &p.Username)
+	str("ODOO_API_KEY", &p.APIKey)
+	c.Odoo.Profiles[c.Odoo.Profile] = p
+}
+
+// Secrets returns every credential in the configuration, for the logger to
+// mask.
+func (c *Config) Secrets() []string {
+	out := []string{c.Auth.HMAC.Secret}
+	for _, p := range c.Odoo.Profiles {
+		out = append(out, p.APIKey)
+	}
+	for _, u := range c.Odoo.Users.Map {
+		out = append(out, u.APIKey)
+	}
+	for _, k := range c.Auth.APIKeys {
+		out = append(out, k.Key)
+	}
+	for _, v := range c.Tracing.Headers {
+		out = append(out, v)
+	}
+	return out
+}
+
+// ValidationError lists every problem found in a configuration.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Validate checks the configuration and reports all problems at once.
+func (c *Config) Validate() error {
+	probs := append([]string(nil), c.envErrs...)
+	add := func(format string, a ...any) { probs = append(probs, fmt.Sprintf(format, a...)) }
+
+	if c.Server.Name == "" {
+		add("server.name: must not be empty")
+	}
+	if host, port, err := net.SplitHostPort(c.Server.Listen); err != nil {
+		add("server.listen: %q is not host:port (%v)", c.Server.Listen, err)
+	} else if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		add("server.listen: port %q must be a number between 0 and 65535", port)
+	} else if c.httpEnabled() && !c.Auth.Enabled && !c.Server.AllowInsecure && !loopback(host) {
+		add("server.listen: %q accepts connections from other hosts but auth is disabled; enable auth (MCP_AUTH_ENABLED), listen on 127.0.0.1 or set server.allow_insecure (-allow-insecure)", c.Server.Listen)
+	}
+	if _, err := time.LoadLocation(c.Server.Timezone); err != nil {
+		add("server.timezone: %q is not an IANA time zone such as Europe/Berlin", c.Server.Timezone)
+	}
+	if len(c.Server.Transports) == 0 {
+		add("server.transports: at least one of %s is required", strings.Join(KnownTransports, ", "))
+	}
+	for _, t := range c.Server.Transports {
+		if !contains(KnownTransports, t) {
+			add("server.transports: unknown transport %q (want one of %s)", t, strings.Join(KnownTransports, ", "))
+		}
+	}
+
+	if len(c.Models.Default) == 0 {
+		add("models.default: at least one model ID is required")
+	}
+	for tool, ms := range c.Models.PerTool {
+		if len(ms) == 0 {
+			add("models.per_tool.%s: empty model list (remove the entry to use models.default)", tool)
+		}
+	}
+	for class, p := range c.Models.Policies {
+		prefix := "models.policies." + class
+		if !slices.Contains(bedrock.Classes, bedrock.ErrorClass(class)) {
+			add("%s: unknown error class (want one of %s)", prefix, joinNames(bedrock.Classes))
+		}
+		if !slices.Contains(bedrock.Actions, bedrock.Action(p.Action)) {
+			add("%s.action: %q must be one of %s", prefix, p.Action, joinNames(bedrock.Actions))
+		}
+		if p.Retries < 0 || p.Backoff.Duration < 0 || p.Cooldown.Duration < 0 {
+			add("%s: retries, backoff and cooldown must not be negative", prefix)
+		}
+	}
+
+	p, ok := c.Odoo.Profiles[c.Odoo.Profile]
+	if !ok {
+		add("odoo.profile: %q is not defined under odoo.profiles (have: %s)", c.Odoo.Profile, strings.Join(c.profileNames(), ", "))
+	} else {
+		prefix := "odoo.profiles." + c.Odoo.Profile
+		if p.URL == "" {
+			add("%s.url: required (or set ODOO_URL)", prefix)
+		} else if u, err := url.Parse(p.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("%s.url: %q must be an http(s) URL", prefix, p.URL)
+		}
+		if p.DB == "" {
+			add("%s.db: required (or set ODOO_DB)", prefix)
+		}
+		if p.Username == "" {
+			add("%s.username: required (or set ODOO_USERNAME)", prefix)
+		}
+		if p.APIKey == "" {
+			add("%s.api_key: required (or set ODOO_API_KEY)", prefix)
+		}
+	}
+
+	if u := c.Odoo.Users; u.Enabled {
+		if u.Fallback != "service" && u.Fallback != "deny" {
+			add("odoo.users.fallback: %q must be service or deny", u.Fallback)
+		}
+		if u.IdleTTL.Duration <= 0 {
+			add("odoo.users.idle_ttl: must be a positive duration such as \"30m\"")
+		}
+		if len(u.Map) == 0 && !u.Delegated {
+			add("odoo.users: enabled but neither map nor delegated is set")
+		}
+		for subject, m := range u.Map {
+			if m.Username == "" || m.APIKey == "" {
+				add("odoo.users.map.%s: username and api_key are required", subject)
+			}
+		}
+	}
+
+	for name, d := range map[string]Duration{"timeouts.odoo": c.Timeouts.Odoo, "timeouts.llm": c.Timeouts.LLM, "timeouts.shutdown": c.Timeouts.Shutdown, "conversation.ttl": c.Conversation.TTL, "resources.poll_interval": c.Resources.PollInterval, "health.interval": c.Health.Interval, "health.timeout": c.Health.Timeout} {
+		if d.Duration <= 0 {
+			add("%s: must be a positive duration such as \"30s\"", name)
+		}
+	}
+	if c.Conversation.MaxChars < 0 || c.Conversation.KeepTurns < 0 {
+		add("conversation: max_chars and keep_turns must not be negative")
+	}
+
+	if c.Auth.Enabled {
+		if len(c.Auth.APIKeys) == 0 && c.Auth.HMAC.Secret == "" && c.Auth.JWT.JWKSFile == "" {
+			add("auth: enabled but no method configured (api_keys, hmac.secret or jwt.jwks_file)")
+		}
+		for i, k := range c.Auth.APIKeys {
+			if k.Key == "" || k.Subject == "" {
+				add("auth.api_keys[%d]: key and subject are required", i)
+			}
+		}
+		if s := c.Auth.HMAC.Secret; s != "" && len(s) < 32 {
+			add("auth.hmac.secret: must be at least 32 characters")
+		}
+		if f := c.Auth.JWT.JWKSFile; f != "" {
+			if _, err := os.Stat(f); err != nil {
+				add("auth.jwt.jwks_file: %v", err)
+			}
+		}
+		for _, e := range c.Auth.Exempt {
+			if !strings.HasPrefix(e, "/") {
+				add("auth.exempt: %q must be a path starting with /", e)
+			}
+		}
+	}
+
+	if c.Approval.Enabled && c.Approval.TTL.Duration <= 0 {
+		add("approval.ttl: must be a positive duration such as \"1h\"")
+	}
+
+	if c.Audit.Enabled && c.Audit.File == "" {
+		add("audit.file: required when audit.enabled is true")
+	}
+
+	if c.Idempotency.Enabled {
+		if c.Idempotency.Window.Duration <= 0 {
+			add("idempotency.window: must be a positive duration such as \"24h\"")
+		}
+		if c.Idempotency.DeriveWindow.Duration < 0 || c.Idempotency.DeriveWindow.Duration > c.Idempotency.Window.Duration {
+			add("idempotency.derive_window: must be between 0 and idempotency.window")
+		}
+	}
+
+	if _, err := logging.ParseLevel(c.Logging.Level); err != nil {
+		add("logging.level: %v", err)
+	}
+	for name, l := range c.Logging.Components {
+		if _, err := logging.ParseLevel(l); err != nil {
+			add("logging.components.%s: %v", name, err)
+		}
+	}
+	if f := strings.ToLower(c.Logging.Format); f != "json" && f != "text" {
+		add("logging.format: %q must be json or text", c.Logging.Format)
+	}
+
+	switch c.Tracing.Exporter {
+	case "":
+	case "otlp":
+		if u, err := url.Parse(c.Tracing.Endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+			add("tracing.endpoint: %q is not a URL such as http://localhost:4318/v1/traces", c.Tracing.Endpoint)
+		}
+	case "file":
+		if c.Tracing.File == "" {
+			add("tracing.file: required when tracing.exporter is file")
+		}
+	default:
+		add("tracing.exporter: unknown exporter %q (have: otlp, file)", c.Tracing.Exporter)
+	}
+
+	if c.Authz.Enabled {
+		if e, err := c.Policy(); err != nil {
+			add("authz: %v", err)
+		} else {
+			known := e.Roles()
+			check := func(field string, roles []string) {
+				for _, r := range roles {
+					if !contains(known, r) {
+						add("%s: unknown role %q (have: %s)", field, r, strings.Join(known, ", "))
+					}
+				}
+			}
+			check("auth.stdio.roles", c.Auth.Stdio.Roles)
+			for i, k := range c.Auth.APIKeys {
+				check(fmt.Sprintf("auth.api_keys[%d].roles", i), k.Roles)
+			}
+		}
+	}
+
+	if len(probs) == 0 {
+		return nil
+	}
+	sort.Strings(probs)
+	return &ValidationError{Problems: probs}
+}
+
+// ValidateTools checks tool names in the config against the tools the server
+// knows about.
+func (c *Config) ValidateTools(known []string) error {
+	var probs []string
+	check := func(field string, names []string) {
+		for _, n := range names {
+			if !contains(known, n) {
+				probs = append(probs, fmt.Sprintf("%s: unknown tool %q", field, n))
+			}
+		}
+	}
+	check("tools.enabled", c.Tools.Enabled)
+	check("tools.disabled", c.Tools.Disabled)
+	for tool := range c.Models.PerTool {
+		check("models.per_tool", []string{tool})
+	}
+	for tool := range c.Approval.Rules {
+		check("approval.rules", []string{tool})
+	}
+	if len(probs) == 0 {
+		return nil
+	}
+	sort.Strings(probs)
+	return &ValidationError{Problems: probs}
+}
+
+// Location returns the plant time zone, the host's when none is configured.
+// Validate has checked the name.
+func (c *Config) Location() *time.Location {
+	if c.Server.Timezone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(c.Server.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// ToolEnabled reports whether the named tool should be registered.
+func (c *Config) ToolEnabled(name string) bool {
+	if contains(c.Tools.Disabled, name) {
+		return false
+	}
+	return len(c.Tools.Enabled) == 0 || contains(c.Tools.Enabled, name)
+}
+
+// ModelsFor returns the model fallback chain for a tool.
+func (c *Config) ModelsFor(tool string) []string {
+	if ms, ok := c.Models.PerTool[tool]; ok && len(ms) > 0 {
+		return ms
+	}
+	return c.Models.Default
+}
+
+// ActiveOdoo returns the selected Odoo profile.
+func (c *Config) ActiveOdoo() OdooProfile {
+	return c.Odoo.Profiles[c.Odoo.Profile]
+}
+
+// HasTransport reports whether the named transport is enabled.
+func (c *Config) HasTransport(name string) bool {
+	return contains(c.Server.Transports, name)
+}
+
+func (c *Config) profileNames() []string {
+	names := make([]string, 0, len(c.Odoo.Profiles))
+	for n := range c.Odoo.Profiles {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// httpEnabled reports whether an HTTP-based transport is selected.
+func (c *Config) httpEnabled() bool {
+	return c.HasTransport(transport.SSE) || c.HasTransport(transport.HTTP)
+}
+
+// loopback reports whether a listen host only accepts local connections.
+func loopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func joinNames[T ~string](names []T) string {
+	s := make([]string, len(names))
+	for i, n := range names {
+		s[i] = string(n)
+	}
+	return strings.Join(s, ", ")
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
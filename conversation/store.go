@@ -0,0 +1,262 @@
+// Package conversation keeps short-lived memory for LLM tools so planners can
+// ask follow-up questions against the same analysis.
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"mcp-bedrock-go/internal/logging"
+	"mcp-bedrock-go/internal/metrics"
+)
+
+var logger = logging.For("conversation")
+
+// Turn is one question/answer exchange together with the data it was based on.
+type Turn struct {
+	Question string    `json:"question"`
+	Answer   string    `json:"answer"`
+	Model    string    `json:"model,omitempty"`
+	Snapshot string    `json:"snapshot,omitempty"` // JSON of the Odoo context used
+	At       time.Time `json:"at"`
+}
+
+// Session is the history of one conversation.
+type Session struct {
+	ID         string    `json:"id"`
+	Summary    string    `json:"summary,omitempty"` // condensed older turns
+	Turns      []Turn    `json:"turns"`
+	LastActive time.Time `json:"last_active"`
+
+	summarizing bool // an Append is condensing the oldest turns
+}
+
+// Summarizer condenses older turns (plus any previous summary) into a short text.
+type Summarizer func(ctx context.Context, previous string, turns []Turn) (string, error)
+
+// Generator is the subset of the Bedrock fallback chain the summarizer needs.
+type Generator interface {
+	Generate(ctx context.Context, prompt any) (string, string, error)
+}
+
+// Store holds sessions in memory, summarizes long histories and expires idle
+// sessions after TTL.
+type Store struct {
+	TTL       time.Duration // idle time before a session is dropped
+	MaxChars  int           // history size that triggers summarization
+	KeepTurns int           // most recent turns kept verbatim after summarizing
+	Summarize Summarizer
+
+	mu       sync.Mutex
+	sessions map[string]*Session
+}
+
+// NewStore creates a store with the given idle TTL and summarization settings.
+func NewStore(ttl time.Duration, maxChars, keepTurns int, summarize Summarizer) *Store {
+	return &Store{
+		TTL:       ttl,
+		MaxChars:  maxChars,
+		KeepTurns: keepTurns,
+		Summarize: summarize,
+		sessions:  map[string]*Session{},
+	}
+}
+
+// Get returns a copy of the session, or nil if it does not exist or expired.
+// A nil Store (conversations disabled) never has sessions.
+func (s *Store) Get(id string) *Session {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.sessions[id]
+	if !ok {
+		metrics.CacheLookup("conversation", false)
+		return nil
+	}
+	if s.expired(sess, time.Now()) {
+		delete(s.sessions, id)
+		metrics.CacheLookup("conversation", false)
+		return nil
+	}
+	metrics.CacheLookup("conversation", true)
+	cp := *sess
+	cp.Turns = append([]Turn(nil), sess.Turns...)
+	return &cp
+}
+
+// Append records a turn and summarizes the history if it grew past MaxChars.
+// One summary runs per session at a time; appends meanwhile only add turns,
+// which the next summary picks up.
+func (s *Store) Append(ctx context.Context, id string, t Turn) {
+	if s == nil {
+		return
+	}
+	if t.At.IsZero() {
+		t.At = time.Now()
+	}
+
+	s.mu.Lock()
+	sess, ok := s.sessions[id]
+	if !ok || s.expired(sess, t.At) {
+		sess = &Session{ID: id}
+		s.sessions[id] = sess
+	}
+	sess.Turns = append(sess.Turns, t)
+	sess.LastActive = t.At
+
+	if sess.summarizing || s.MaxChars <= 0 || historySize(sess) <= s.MaxChars || len(sess.Turns) <= s.KeepTurns {
+		s.mu.Unlock()
+		return
+	}
+	sess.summarizing = true
+	cut := len(sess.Turns) - s.KeepTurns
+	old := append([]Turn(nil), sess.Turns[:cut]...)
+	previous := sess.Summary
+	s.mu.Unlock()
+
+	// Summarize outside the lock; the LLM call can take a while.
+	summary, err := s.summarize(ctx, previous, old)
+	if err != nil {
+		logger.ErrorCtxf(ctx, "conversation %s: summarize failed: %v", id, err)
+		summary = fallbackSummary(previous, old)
+	}
+
+	// Only appends ran meanwhile, so the summarized turns are still the
+	// first cut.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess.summarizing = false
+	if cur, ok := s.sessions[id]; ok && cur == sess {
+		sess.Summary = summary
+		sess.Turns = sess.Turns[cut:]
+	}
+}
+
+// Delete forgets a session.
+func (s *Store) Delete(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+}
+
+// Sweep drops every session idle for longer than TTL and returns how many
+// were removed.
+func (s *Store) Sweep() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	n := 0
+	for id, sess := range s.sessions {
+		if s.expired(sess, now) {
+			delete(s.sessions, id)
+			n++
+		}
+	}
+	return n
+}
+
+// Run sweeps expired sessions every interval until ctx is cancelled.
+func (s *Store) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if n := s.Sweep(); n > 0 {
+				logger.DebugCtxf(ctx, "conversation: expired %d idle sessions", n)
+			}
+		}
+	}
+}
+
+// History renders the session as prompt text: the running summary followed by
+// the verbatim recent turns. It returns "" for an unknown session.
+func (s *Store) History(id string) string {
+	sess := s.Get(id)
+	if sess == nil || (sess.Summary == "" && len(sess.Turns) == 0) {
+		return ""
+	}
+	var b strings.Builder
+	if sess.Summary != "" {
+		fmt.Fprintf(&b, "Summary of earlier discussion:\n%s\n\n", sess.Summary)
+	}
+	for i, t := range sess.Turns {
+		fmt.Fprintf(&b, "Turn %d\nQ: %s\nA: %s\n\n", i+1, t.Question, t.Answer)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func (s *Store) expired(sess *Session, now time.Time) bool {
+	return s.TTL > 0 && now.Sub(sess.LastActive) > s.TTL
+}
+
+func (s *Store) summarize(ctx context.Context, previous string, turns []Turn) (string, error) {
+	if s.Summarize == nil {
+		return fallbackSummary(previous, turns), nil
+	}
+	return s.Summarize(ctx, previous, turns)
+}
+
+// ChainSummarizer asks the LLM to condense the history.
+func ChainSummarizer(gen Generator, wrap func(string) string) Summarizer {
+	return func(ctx context.Context, previous string, turns []Turn) (string, error) {
+		var b strings.Builder
+		b.WriteString("Summarize this production-planning conversation in at most 10 bullet points. Keep decisions, numbers, product codes and open questions.\n\n")
+		if previous != "" {
+			fmt.Fprintf(&b, "Earlier summary:\n%s\n\n", previous)
+		}
+		for _, t := range turns {
+			fmt.Fprintf(&b, "Q: %s\nA: %s\n\n", t.Question, t.Answer)
+		}
+		prompt := b.String()
+		if wrap != nil {
+			prompt = wrap(prompt)
+		}
+		out, _, err := gen.Generate(ctx, prompt)
+		return strings.TrimSpace(out), err
+	}
+}
+
+// fallbackSummary keeps the questions and a clipped answer when no LLM
+// summary is available.
+func fallbackSummary(previous string, turns []Turn) string {
+	var b strings.Builder
+	if previous != "" {
+		b.WriteString(previous)
+		b.WriteString("\n")
+	}
+	for _, t := range turns {
+		fmt.Fprintf(&b, "- %s → %s\n", t.Question, clip(t.Answer, 200))
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func historySize(sess *Session) int {
+	n := len(sess.Summary)
+	for _, t := range sess.Turns {
+		n += len(t.Question) + len(t.Answer)
+	}
+	return n
+}
+
+func clip(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n]) + "…"
+}
+
+// Snapshot serializes the data a turn was based on.
+func Snapshot(v any) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
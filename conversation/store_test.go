@@ -0,0 +1,59 @@
+package conversation
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestConcurrentSummaries appends while an earlier append is still
+// summarizing and checks every question ends up in exactly one place: the
+// summary or the verbatim turns.
+func TestConcurrentSummaries(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	summarize := func(ctx context.Context, previous string, turns []Turn) (string, error) {
+		first := false
+		once.Do(func() { first = true })
+		if first {
+			close(started)
+			<-release
+		}
+		parts := []string{}
+		if previous != "" {
+			parts = append(parts, previous)
+		}
+		for _, t := range turns {
+			parts = append(parts, t.Question)
+		}
+		return strings.Join(parts, " "), nil
+	}
+	s := NewStore(0, 10, 1, summarize)
+	ctx := context.Background()
+	answer := strings.Repeat("a", 20)
+
+	s.Append(ctx, "c", Turn{Question: "q1", Answer: answer})
+	done := make(chan struct{})
+	go func() {
+		s.Append(ctx, "c", Turn{Question: "q2", Answer: answer}) // summarizes q1
+		close(done)
+	}()
+	<-started
+	s.Append(ctx, "c", Turn{Question: "q3", Answer: answer})
+	s.Append(ctx, "c", Turn{Question: "q4", Answer: answer})
+	close(release)
+	<-done
+	s.Append(ctx, "c", Turn{Question: "q5", Answer: answer})
+
+	sess := s.Get("c")
+	seen := strings.Fields(sess.Summary)
+	for _, t := range sess.Turns {
+		seen = append(seen, t.Question)
+	}
+	want := []string{"q1", "q2", "q3", "q4", "q5"}
+	if strings.Join(seen, " ") != strings.Join(want, " ") {
+		t.Fatalf("summary %q and turns %v, want each of %v once in order", sess.Summary, sess.Turns, want)
+	}
+}
@@ -0,0 +1,161 @@
+// Package delegation runs each caller's Odoo calls as their own Odoo user.
+// A principal's Odoo login comes from a configured map or, when delegation
+// is on, from headers on the request that opened the session. The matching
+// client is taken from an odoo.Pool and put on the call's context, so Odoo
+// applies that user's access rules and its chatter shows who acted.
+package delegation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mcp-bedrock-go/auth"
+	"mcp-bedrock-go/internal/logging"
+	"mcp-bedrock-go/odoo"
+)
+
+var logger = logging.For("delegation")
+
+// Headers carrying a caller's own Odoo login and API key.
+const (
+	LoginHeader = "X-Odoo-Login"
+	KeyHeader   = "X-Odoo-API-Key"
+)
+
+// ErrNoUser means the caller has no Odoo user and the fallback is deny.
+var ErrNoUser = errors.New("no Odoo user for this caller")
+
+// Resolver picks the Odoo client for each call.
+type Resolver struct {
+	Pool      *odoo.Pool
+	Map       map[string]odoo.Credentials // principal subject → Odoo login
+	Delegated bool                        // accept the login headers
+	Deny      bool                        // refuse callers without an Odoo user instead of using the service account
+
+	mu       sync.RWMutex
+	sessions map[string]odoo.Credentials
+}
+
+// New returns a resolver drawing clients from pool.
+func New(pool *odoo.Pool, m map[string]odoo.Credentials, delegated, deny bool) *Resolver {
+	return &Resolver{Pool: pool, Map: m, Delegated: delegated, Deny: deny, sessions: map[string]odoo.Credentials{}}
+}
+
+type credKey struct{}
+
+// HTTPMiddleware takes the login headers off each request when delegation
+// is on. It belongs inside authentication, so only authenticated callers
+// can name an Odoo user.
+func (r *Resolver) HTTPMiddleware(next http.Handler) http.Handler {
+	if !r.Delegated {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		user, key := strings.TrimSpace(req.Header.Get(LoginHeader)), req.Header.Get(KeyHeader)
+		if user != "" && key != "" {
+			req = req.WithContext(context.WithValue(req.Context(), credKey{}, odoo.Credentials{User: user, Key: key}))
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// Hooks records the delegated login of each MCP session when it registers
+// and forgets it on unregister, so later requests of the session need not
+// repeat the headers.
+func (r *Resolver) Hooks(h *server.Hooks) {
+	h.AddOnRegisterSession(func(ctx context.Context, sess server.ClientSession) {
+		if c, ok := ctx.Value(credKey{}).(odoo.Credentials); ok {
+			r.mu.Lock()
+			r.sessions[sess.SessionID()] = c
+			r.mu.Unlock()
+		}
+	})
+	h.AddOnUnregisterSession(func(ctx context.Context, sess server.ClientSession) {
+		r.mu.Lock()
+		delete(r.sessions, sess.SessionID())
+		r.mu.Unlock()
+	})
+}
+
+// credentials finds the caller's Odoo login: headers on this request, then
+// those the session was opened with, then the map entry for the principal.
+func (r *Resolver) credentials(ctx context.Context) (odoo.Credentials, bool) {
+	if c, ok := ctx.Value(credKey{}).(odoo.Credentials); ok {
+		return c, true
+	}
+	if sess := server.ClientSessionFromContext(ctx); sess != nil {
+		r.mu.RLock()
+		c, ok := r.sessions[sess.SessionID()]
+		r.mu.RUnlock()
+		if ok {
+			return c, true
+		}
+	}
+	if p := auth.FromContext(ctx); p != nil {
+		c, ok := r.Map[p.Subject]
+		return c, ok
+	}
+	return odoo.Credentials{}, false
+}
+
+// bind returns ctx carrying the caller's Odoo client. Without an Odoo user
+// ctx is returned unchanged (the service account), or ErrNoUser when
+// denying.
+func (r *Resolver) bind(ctx context.Context) (context.Context, error) {
+	cred, ok := r.credentials(ctx)
+	if !ok {
+		if r.Deny {
+			return ctx, ErrNoUser
+		}
+		return ctx, nil
+	}
+	c, err := r.Pool.Get(ctx, cred)
+	if err != nil {
+		return ctx, err
+	}
+	logger.DebugCtxf(ctx, "delegation: odoo calls run as %s (uid %d)", c.User, c.UID())
+	return odoo.WithClient(ctx, c), nil
+}
+
+// Middleware runs tool calls as the caller's Odoo user. A caller that
+// cannot be mapped, or whose login Odoo rejects, gets a tool error.
+func (r *Resolver) Middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx, err := r.bind(ctx)
+		if err != nil {
+			logger.InfoCtxf(ctx, "delegation: %s refused: %v", req.Params.Name, err)
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return next(ctx, req)
+	}
+}
+
+// ResourceMiddleware does the same for resource reads.
+func (r *Resolver) ResourceMiddleware(next server.ResourceHandlerFunc) server.ResourceHandlerFunc {
+	return func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		ctx, err := r.bind(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", req.Params.URI, err)
+		}
+		return next(ctx, req)
+	}
+}
+
+// PromptMiddleware does the same for prompts, which read Odoo to build
+// their context.
+func (r *Resolver) PromptMiddleware(next server.PromptHandlerFunc) server.PromptHandlerFunc {
+	return func(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		ctx, err := r.bind(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("prompt %s: %w", req.Params.Name, err)
+		}
+		return next(ctx, req)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	bedrocklib "mcp-bedrock-go/bedrock"
+)
+
+// fakeLLM answers deterministically from what the prompt contains, so the
+// harness and checks can be exercised without Bedrock. Its answers are
+// written to pass the checks: it smoke-tests the harness and measures
+// nothing about prompts or models.
+type fakeLLM struct{}
+
+func (fakeLLM) GenerateText(ctx context.Context, modelID string, prompt any) (string, error) {
+	p := fmt.Sprint(prompt)
+	var b strings.Builder
+	if strings.Contains(p, "RUSH-TEA") {
+		b.WriteString("Prioritise RUSH-TEA ahead of the waiting CUST-B order so the 12:00 deadline is met. ")
+		b.WriteString("Running overtime on the PRINT STATION costs about 8,000 but avoids the tardiness penalty; the trade-off favours overtime. ")
+	}
+	if strings.Contains(p, "workcenter") || strings.Contains(p, "production plan") {
+		b.WriteString("Plan: PRINT STATION → DIECUT STATION → FOLDING LINE, estimated duration per step from routing. ")
+	}
+	if b.Len() == 0 {
+		b.WriteString("No specific recommendation.")
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// recordingLLM passes calls to a live generator and stores the responses.
+type recordingLLM struct {
+	inner bedrocklib.Generator
+	tape  *tape
+}
+
+func (r recordingLLM) GenerateText(ctx context.Context, modelID string, prompt any) (string, error) {
+	out, err := r.inner.GenerateText(ctx, modelID, prompt)
+	if err != nil {
+		return "", err
+	}
+	r.tape.put(modelID, prompt, out)
+	return out, nil
+}
+
+// replayLLM answers from a tape recorded earlier and fails on unknown prompts.
+type replayLLM struct{ tape *tape }
+
+func (r replayLLM) GenerateText(ctx context.Context, modelID string, prompt any) (string, error) {
+	out, ok := r.tape.get(modelID, prompt)
+	if !ok {
+		return "", fmt.Errorf("no recorded response for model %s (prompt %s)", modelID, tapeKey(modelID, prompt)[:12])
+	}
+	return out, nil
+}
+
+// tape is a prompt-hash → response file used by record and replay modes.
+type tape struct {
+	path string
+
+	mu      sync.Mutex
+	entries map[string]string
+}
+
+func loadTape(path string) (*tape, error) {
+	t := &tape{path: path, entries: map[string]string{}}
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return t, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &t.entries); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return t, nil
+}
+
+func (t *tape) get(modelID string, prompt any) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out, ok := t.entries[tapeKey(modelID, prompt)]
+	return out, ok
+}
+
+func (t *tape) put(modelID string, prompt any, out string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.entries[tapeKey(modelID, prompt)] = out
+}
+
+func (t *tape) save() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b, _ := json.MarshalIndent(t.entries, "", "  ")
+	return os.WriteFile(t.path, b, 0o644)
+}
+
+func tapeKey(modelID string, prompt any) string {
+	sum := sha256.Sum256([]byte(modelID + "\x00" + fmt.Sprint(prompt)))
+	return hex.EncodeToString(sum[:])
+}
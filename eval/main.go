@@ -0,0 +1,316 @@
+// Command eval runs the LLM tools against golden manufacturing scenarios built
+// from mocks/mock.json variants, scores the answers with rule-based checks and
+// diffs the scores against a previous run.
+//
+// The fake LLM returns canned phrases the checks look for, so a fake run is
+// a smoke test of the harness (scenarios load, tools run, checks parse) and
+// scores nothing. Regressions are measured on Bedrock answers: record a
+// tape once, then replay it against changed prompts and tools.
+//
+//	go run ./eval -llm fake                                # smoke test only
+//	go run ./eval -llm record -tape eval/recordings.json   # needs AWS credentials
+//	go run ./eval -llm replay -baseline eval/results/last.json -fail-on-regression
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	bedrockruntime "github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	bedrocklib "mcp-bedrock-go/bedrock"
+	"mcp-bedrock-go/conversation"
+	"mcp-bedrock-go/internal/odoofake"
+	odoolib "mcp-bedrock-go/odoo"
+	"mcp-bedrock-go/productsearch"
+	"mcp-bedrock-go/tools"
+)
+
+// Result is the scored outcome of one scenario.
+type Result struct {
+	Scenario  string        `json:"scenario"`
+	Tool      string        `json:"tool"`
+	Model     string        `json:"model,omitempty"`
+	Score     float64       `json:"score"`
+	Checks    []CheckResult `json:"checks"`
+	Output    string        `json:"output"`
+	Error     string        `json:"error,omitempty"`
+	LatencyMS int64         `json:"latency_ms"`
+}
+
+// Run is one eval invocation, written to -out and read back via -baseline.
+type Run struct {
+	StartedAt time.Time `json:"started_at"`
+	LLM       string    `json:"llm"`
+	Models    []string  `json:"models"`
+	Results   []Result  `json:"results"`
+}
+
+func main() {
+	scenarioDir := flag.String("scenarios", "eval/scenarios", "directory of scenario JSON files")
+	mode := flag.String("llm", "fake", "LLM mode: fake (harness smoke test), replay, record or live")
+	tapePath := flag.String("tape", "eval/recordings.json", "recorded responses for replay/record modes")
+	models := flag.String("models", "us.meta.llama3-1-70b-instruct-v1:0", "comma-separated model fallback chain")
+	outPath := flag.String("out", "", "write results JSON here (default eval/results/<timestamp>.json)")
+	baseline := flag.String("baseline", "", "previous results JSON to diff against")
+	failOnRegression := flag.Bool("fail-on-regression", false, "exit 1 if any scenario scores lower than the baseline")
+	flag.Parse()
+
+	scenarios, err := loadScenarios(*scenarioDir)
+	if err != nil {
+		log.Fatalf("load scenarios: %v", err)
+	}
+	if len(scenarios) == 0 {
+		log.Fatalf("no scenarios in %s", *scenarioDir)
+	}
+
+	if *mode == "fake" && *failOnRegression {
+		log.Fatalf("-fail-on-regression needs -llm replay or live: fake answers are canned and cannot regress")
+	}
+	gen, tp, err := generator(*mode, *tapePath)
+	if err != nil {
+		log.Fatalf("llm: %v", err)
+	}
+	if *mode == "fake" {
+		fmt.Println("llm=fake: smoke test of the harness; scores do not measure answer quality")
+	}
+
+	run := Run{StartedAt: time.Now().UTC(), LLM: *mode, Models: strings.Split(*models, ",")}
+	for _, sc := range scenarios {
+		res := runScenario(context.Background(), sc, bedrocklib.NewChain(gen, run.Models...))
+		run.Results = append(run.Results, res)
+		status := "ok"
+		if res.Error != "" {
+			status = "error: " + res.Error
+		}
+		fmt.Printf("%-32s %-20s score=%.2f  %s\n", res.Scenario, res.Tool, res.Score, status)
+		for _, c := range res.Checks {
+			mark := "✓"
+			if !c.Passed {
+				mark = "✗"
+			}
+			fmt.Printf("    %s %s\n", mark, c.Name)
+		}
+	}
+
+	if tp != nil && *mode == "record" {
+		if err := tp.save(); err != nil {
+			log.Fatalf("save tape: %v", err)
+		}
+	}
+
+	if *outPath == "" {
+		*outPath = filepath.Join("eval", "results", run.StartedAt.Format("20060102-150405")+".json")
+	}
+	if err := os.MkdirAll(filepath.Dir(*outPath), 0o755); err != nil {
+		log.Fatalf("results dir: %v", err)
+	}
+	b, _ := json.MarshalIndent(run, "", "  ")
+	if err := os.WriteFile(*outPath, b, 0o644); err != nil {
+		log.Fatalf("write results: %v", err)
+	}
+	fmt.Println("results written to", *outPath)
+
+	if *baseline != "" {
+		regressions, err := diff(*baseline, run)
+		if err != nil {
+			log.Fatalf("diff: %v", err)
+		}
+		if regressions > 0 && *failOnRegression {
+			os.Exit(1)
+		}
+	}
+}
+
+// generator builds the LLM backend for the selected mode.
+func generator(mode, tapePath string) (bedrocklib.Generator, *tape, error) {
+	switch mode {
+	case "fake":
+		return fakeLLM{}, nil, nil
+	case "replay":
+		tp, err := loadTape(tapePath)
+		return replayLLM{tape: tp}, tp, err
+	case "record", "live":
+		cfg, err := config.LoadDefaultConfig(context.Background())
+		if err != nil {
+			return nil, nil, err
+		}
+		live := bedrocklib.New(bedrockruntime.NewFromConfig(cfg))
+		if mode == "live" {
+			return live, nil, nil
+		}
+		tp, err := loadTape(tapePath)
+		return recordingLLM{inner: live, tape: tp}, tp, err
+	}
+	return nil, nil, fmt.Errorf("unknown mode %q", mode)
+}
+
+// runScenario seeds a fake Odoo from the scenario's mock variant, calls the
+// tool and scores the output.
+func runScenario(ctx context.Context, sc Scenario, llm *bedrocklib.Chain) Result {
+	res := Result{Scenario: sc.Name, Tool: sc.Tool}
+
+	raw, err := odoofake.LoadMock(filepath.Join(sc.dir, sc.Mock))
+	if err != nil {
+		res.Error = err.Error()
+		return score(res, sc.Checks)
+	}
+	fake, err := odoofake.New(mergePatch(raw, sc.Patch))
+	if err != nil {
+		res.Error = err.Error()
+		return score(res, sc.Checks)
+	}
+	defer fake.Close()
+
+	oc := odoolib.New(fake.URL, "eval", "eval", "eval")
+	if err := oc.Login(); err != nil {
+		res.Error = err.Error()
+		return score(res, sc.Checks)
+	}
+
+	handler, ok := toolHandlers(oc, llm)[sc.Tool]
+	if !ok {
+		res.Error = "unknown tool " + sc.Tool
+		return score(res, sc.Checks)
+	}
+
+	var req mcp.CallToolRequest
+	req.Params.Name = sc.Tool
+	req.Params.Arguments = sc.Args
+
+	start := time.Now()
+	out, err := handler(ctx, req)
+	res.LatencyMS = time.Since(start).Milliseconds()
+	if err != nil {
+		res.Error = err.Error()
+		return score(res, sc.Checks)
+	}
+
+	var texts []string
+	for _, c := range out.Content {
+		if tc, ok := c.(mcp.TextContent); ok {
+			texts = append(texts, tc.Text)
+		}
+	}
+	res.Output = strings.Join(texts, "\n")
+	if out.IsError {
+		res.Error = res.Output
+	}
+	if out.Meta != nil {
+		if m, ok := out.Meta.AdditionalFields["model"].(string); ok {
+			res.Model = m
+		}
+	}
+	return score(res, sc.Checks)
+}
+
+// toolHandlers builds the registered tools scenarios may call, wired to the
+// fake Odoo and validating arguments as the server does. Tools whose
+// subsystem is missing here (docs, approvals) are left out.
+func toolHandlers(oc *odoolib.Client, llm *bedrocklib.Chain) map[string]server.ToolHandlerFunc {
+	deps := tools.Deps{
+		Odoo:     oc,
+		LLM:      func(string) *bedrocklib.Chain { return llm },
+		Convs:    conversation.NewStore(time.Hour, 0, 0, nil),
+		Products: productsearch.New(oc, nil, time.Hour),
+	}
+	out := map[string]server.ToolHandlerFunc{}
+	for _, t := range tools.Build(deps) {
+		out[t.Tool.Name] = tools.Validate(t.Handler)
+	}
+	return out
+}
+
+func score(res Result, checks []Check) Result {
+	total, passed := 0.0, 0.0
+	for _, c := range checks {
+		w := c.Weight
+		if w == 0 {
+			w = 1
+		}
+		ok := false
+		if res.Error == "" {
+			var err error
+			ok, err = c.run(res.Output)
+			if err != nil {
+				res.Error = err.Error()
+			}
+		}
+		res.Checks = append(res.Checks, CheckResult{Name: c.Name, Passed: ok, Weight: w})
+		total += w
+		if ok {
+			passed += w
+		}
+	}
+	if total > 0 {
+		res.Score = passed / total
+	}
+	return res
+}
+
+// diff prints score changes against a previous run and returns the number of
+// scenarios that got worse.
+func diff(path string, cur Run) (int, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	var prev Run
+	if err := json.Unmarshal(b, &prev); err != nil {
+		return 0, fmt.Errorf("parse %s: %w", path, err)
+	}
+	old := map[string]Result{}
+	for _, r := range prev.Results {
+		old[r.Scenario] = r
+	}
+
+	fmt.Printf("\nDiff against %s (%s, llm=%s)\n", path, prev.StartedAt.Format(time.RFC3339), prev.LLM)
+	smoke := prev.LLM == "fake" || cur.LLM == "fake"
+	if smoke {
+		fmt.Println("  (a fake run is a smoke test; changes are not counted as regressions)")
+	}
+	regressions := 0
+	for _, r := range cur.Results {
+		o, ok := old[r.Scenario]
+		if !ok {
+			fmt.Printf("  + %-30s new, score=%.2f\n", r.Scenario, r.Score)
+			continue
+		}
+		delete(old, r.Scenario)
+		tag := "="
+		switch {
+		case r.Score < o.Score:
+			tag = "▼"
+			if !smoke {
+				regressions++
+			}
+		case r.Score > o.Score:
+			tag = "▲"
+		}
+		fmt.Printf("  %s %-30s %.2f → %.2f\n", tag, r.Scenario, o.Score, r.Score)
+
+		was := map[string]bool{}
+		for _, c := range o.Checks {
+			was[c.Name] = c.Passed
+		}
+		for _, c := range r.Checks {
+			if p, ok := was[c.Name]; ok && p != c.Passed {
+				fmt.Printf("      %s: %v → %v\n", c.Name, p, c.Passed)
+			}
+		}
+	}
+	for name := range old {
+		fmt.Printf("  - %-30s removed\n", name)
+	}
+	return regressions, nil
+}
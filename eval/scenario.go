@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Scenario is one golden case: a mock dataset variant, a tool call and the
+// rule-based checks its answer must satisfy.
+type Scenario struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Mock        string         `json:"mock"`            // mock.json path, relative to the scenario file
+	Patch       map[string]any `json:"patch,omitempty"` // JSON merge patch applied to the mock
+	Tool        string         `json:"tool"`
+	Args        map[string]any `json:"args,omitempty"`
+	Checks      []Check        `json:"checks"`
+
+	dir string
+}
+
+// Check is a rule over the tool output text. Matching is case-insensitive.
+type Check struct {
+	Name   string   `json:"name"`
+	Kind   string   `json:"kind"` // contains_any | contains_all | not_contains | regex
+	Values []string `json:"values"`
+	Weight float64  `json:"weight,omitempty"`
+}
+
+// loadScenarios reads every *.json scenario in dir, sorted by name.
+func loadScenarios(dir string) ([]Scenario, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var out []Scenario
+	for _, p := range paths {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		var sc Scenario
+		if err := json.Unmarshal(b, &sc); err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		if sc.Name == "" {
+			sc.Name = strings.TrimSuffix(filepath.Base(p), ".json")
+		}
+		if sc.Tool == "" || len(sc.Checks) == 0 {
+			return nil, fmt.Errorf("%s: tool and checks are required", p)
+		}
+		sc.dir = filepath.Dir(p)
+		out = append(out, sc)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// CheckResult is the outcome of one check.
+type CheckResult struct {
+	Name   string  `json:"name"`
+	Passed bool    `json:"passed"`
+	Weight float64 `json:"weight"`
+}
+
+// run evaluates the check against output.
+func (c Check) run(output string) (bool, error) {
+	text := strings.ToLower(output)
+	switch c.Kind {
+	case "contains_any":
+		for _, v := range c.Values {
+			if strings.Contains(text, strings.ToLower(v)) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "contains_all":
+		for _, v := range c.Values {
+			if !strings.Contains(text, strings.ToLower(v)) {
+				return false, nil
+			}
+		}
+		return true, nil
+	case "not_contains":
+		for _, v := range c.Values {
+			if strings.Contains(text, strings.ToLower(v)) {
+				return false, nil
+			}
+		}
+		return true, nil
+	case "regex":
+		for _, v := range c.Values {
+			re, err := regexp.Compile("(?i)" + v)
+			if err != nil {
+				return false, fmt.Errorf("check %s: %w", c.Name, err)
+			}
+			if re.MatchString(output) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return false, fmt.Errorf("check %s: unknown kind %q", c.Name, c.Kind)
+}
+
+// mergePatch applies an RFC 7396 JSON merge patch: objects merge recursively,
+// null deletes a key and everything else (including arrays) replaces.
+func mergePatch(dst, patch map[string]any) map[string]any {
+	if dst == nil {
+		dst = map[string]any{}
+	}
+	for k, v := range patch {
+		if v == nil {
+			delete(dst, k)
+			continue
+		}
+		if pm, ok := v.(map[string]any); ok {
+			dm, _ := dst[k].(map[string]any)
+			dst[k] = mergePatch(dm, pm)
+			continue
+		}
+		dst[k] = v
+	}
+	return dst
+}
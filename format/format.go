@@ -0,0 +1,338 @@
+// Package format renders tool results for whoever reads them. Tools return
+// their data once, as MCP structuredContent with an indented JSON text copy;
+// a caller passing output_format gets the text as compact JSON, a Markdown
+// table, CSV or a plain-language summary instead. The table helpers are
+// shared with the CLI's output.
+package format
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Arg is the tool argument that selects the format.
+const Arg = "output_format"
+
+// Formats.
+const (
+	JSON     = "json"     // indented JSON, the default
+	Compact  = "compact"  // JSON without whitespace, for LLM context
+	Markdown = "markdown" // tables and bullet lists
+	CSV      = "csv"      // one row per record
+	Summary  = "summary"  // a few plain sentences
+)
+
+// Names lists the formats in the order they are documented.
+var Names = []string{JSON, Compact, Markdown, CSV, Summary}
+
+// itemsKey wraps list results: structuredContent must be an object.
+const itemsKey = "items"
+
+// Result returns v as a tool result: indented JSON text for clients that
+// read content, and v as structuredContent. A list is wrapped as
+// {"items": [...]} in structuredContent only, so the text keeps its shape.
+func Result(v any) *mcp.CallToolResult {
+	b, _ := json.MarshalIndent(v, "", "  ")
+	res := mcp.NewToolResultText(string(b))
+	if g, err := Normalize(v); err == nil {
+		res.StructuredContent = Structured(g)
+	}
+	return res
+}
+
+// Structured makes a normalized value an object, as structuredContent must
+// be: lists and scalars are wrapped under "items".
+func Structured(v any) map[string]any {
+	if m, ok := v.(map[string]any); ok {
+		return m
+	}
+	return map[string]any{itemsKey: v}
+}
+
+// unwrap undoes Structured for rendering.
+func unwrap(v any) any {
+	if m, ok := v.(map[string]any); ok && len(m) == 1 {
+		if items, ok := m[itemsKey]; ok {
+			return items
+		}
+	}
+	return v
+}
+
+// Normalize round-trips v through JSON so structs, maps and decoded
+// payloads render alike.
+func Normalize(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var g any
+	if err := json.Unmarshal(b, &g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Render returns v in format. A structuredContent object wrapping a list is
+// rendered as the list.
+func Render(format string, v any) (string, error) {
+	g, err := Normalize(v)
+	if err != nil {
+		return "", err
+	}
+	g = unwrap(g)
+	switch format {
+	case JSON, "":
+		b, err := json.MarshalIndent(g, "", "  ")
+		return string(b), err
+	case Compact:
+		b, err := json.Marshal(g)
+		return string(b), err
+	case Markdown:
+		return markdown(g), nil
+	case CSV:
+		return csvText(g)
+	case Summary:
+		return summary(g), nil
+	}
+	return "", fmt.Errorf("unknown output format %q (have: %s)", format, strings.Join(Names, ", "))
+}
+
+// Rows turns a list into table rows; items that are not objects become a
+// "value" column.
+func Rows(list []any) []map[string]any {
+	rows := make([]map[string]any, 0, len(list))
+	for _, item := range list {
+		row, ok := item.(map[string]any)
+		if !ok {
+			row = map[string]any{"value": item}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// Columns is the union of the rows' keys: preferred first, then id and
+// name, then the rest alphabetically.
+func Columns(rows []map[string]any, preferred []string) []string {
+	seen := map[string]bool{}
+	for _, row := range rows {
+		for k := range row {
+			seen[k] = true
+		}
+	}
+	var cols []string
+	for _, c := range append(append([]string(nil), preferred...), "id", "name") {
+		if seen[c] {
+			cols = append(cols, c)
+			delete(seen, c)
+		}
+	}
+	var rest []string
+	for k := range seen {
+		rest = append(rest, k)
+	}
+	sort.Strings(rest)
+	return append(cols, rest...)
+}
+
+// keys orders an object's keys like Columns orders a table's.
+func keys(m map[string]any) []string {
+	return Columns([]map[string]any{m}, nil)
+}
+
+// OnlyList returns the list when m holds exactly one list of objects next
+// to scalar fields, such as capacity_check's work orders.
+func OnlyList(m map[string]any) ([]any, string) {
+	var (
+		list []any
+		key  string
+	)
+	for k, v := range m {
+		switch t := v.(type) {
+		case []any:
+			if list != nil {
+				return nil, ""
+			}
+			list, key = t, k
+		case map[string]any:
+			return nil, ""
+		}
+	}
+	if len(list) == 0 {
+		return nil, ""
+	}
+	if _, ok := list[0].(map[string]any); !ok {
+		return nil, ""
+	}
+	return list, key
+}
+
+// Cell renders one table value on a single line. Odoo many2one pairs
+// [id, "name"] show the name; other nested values are compact JSON.
+func Cell(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.ReplaceAll(t, "\n", " ")
+	case float64:
+		if t == float64(int64(t)) {
+			return fmt.Sprintf("%d", int64(t))
+		}
+		return fmt.Sprintf("%g", t)
+	case bool:
+		return fmt.Sprint(t)
+	case []any:
+		if name, ok := many2one(t); ok {
+			return name
+		}
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+func many2one(v []any) (string, bool) {
+	if len(v) != 2 {
+		return "", false
+	}
+	if _, ok := v[0].(float64); !ok {
+		return "", false
+	}
+	name, ok := v[1].(string)
+	return name, ok
+}
+
+// isTable reports whether v is a list of records (or an empty list).
+func isTable(v any) bool {
+	list, ok := v.([]any)
+	if !ok {
+		return false
+	}
+	if len(list) == 0 {
+		return true
+	}
+	_, obj := list[0].(map[string]any)
+	return obj
+}
+
+// isText reports whether a string reads as prose rather than a field value.
+func isText(v any) bool {
+	s, ok := v.(string)
+	return ok && (strings.Contains(s, "\n") || len(s) > 120)
+}
+
+func markdown(v any) string {
+	var b strings.Builder
+	switch t := v.(type) {
+	case []any:
+		if isTable(t) {
+			mdTable(&b, t)
+		} else {
+			for _, item := range t {
+				fmt.Fprintf(&b, "- %s\n", mdCell(item))
+			}
+		}
+	case map[string]any:
+		mdObject(&b, t, 3)
+	default:
+		b.WriteString(Cell(t))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// mdObject lists scalar fields as bullets, then prose fields as paragraphs
+// and lists of records as tables under a heading of the given level.
+func mdObject(b *strings.Builder, m map[string]any, level int) {
+	var later []string
+	for _, k := range keys(m) {
+		v := m[k]
+		_, nested := v.(map[string]any)
+		if isTable(v) || nested || isText(v) {
+			later = append(later, k)
+			continue
+		}
+		fmt.Fprintf(b, "- **%s:** %s\n", k, mdCell(v))
+	}
+	heading := strings.Repeat("#", min(level, 6))
+	for _, k := range later {
+		fmt.Fprintf(b, "\n%s %s\n\n", heading, k)
+		switch t := m[k].(type) {
+		case []any:
+			mdTable(b, t)
+		case map[string]any:
+			mdObject(b, t, level+1)
+		case string:
+			b.WriteString(strings.TrimSpace(t) + "\n")
+		}
+	}
+}
+
+func mdTable(b *strings.Builder, list []any) {
+	if len(list) == 0 {
+		b.WriteString("_none_\n")
+		return
+	}
+	rows := Rows(list)
+	cols := Columns(rows, nil)
+	fmt.Fprintf(b, "| %s |\n", strings.Join(cols, " | "))
+	fmt.Fprintf(b, "|%s\n", strings.Repeat(" --- |", len(cols)))
+	for _, row := range rows {
+		cells := make([]string, len(cols))
+		for i, c := range cols {
+			cells[i] = mdCell(row[c])
+		}
+		fmt.Fprintf(b, "| %s |\n", strings.Join(cells, " | "))
+	}
+}
+
+func mdCell(v any) string {
+	return strings.ReplaceAll(Cell(v), "|", `\|`)
+}
+
+// csvText writes a list of records one row each. An object wrapping a
+// single list gives that list's rows; any other object gives field,value
+// rows.
+func csvText(v any) (string, error) {
+	var rows [][]string
+	switch t := v.(type) {
+	case []any:
+		rows = csvRows(Rows(t))
+	case map[string]any:
+		if list, _ := OnlyList(t); list != nil {
+			rows = csvRows(Rows(list))
+			break
+		}
+		rows = [][]string{{"field", "value"}}
+		for _, k := range keys(t) {
+			rows = append(rows, []string{k, Cell(t[k])})
+		}
+	default:
+		rows = [][]string{{Cell(t)}}
+	}
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(rows); err != nil {
+		return "", err
+	}
+	return strings.TrimRight(buf.String(), "\n"), nil
+}
+
+func csvRows(rows []map[string]any) [][]string {
+	cols := Columns(rows, nil)
+	out := [][]string{cols}
+	for _, row := range rows {
+		line := make([]string, len(cols))
+		for i, c := range cols {
+			line[i] = Cell(row[c])
+		}
+		out = append(out, line)
+	}
+	return out
+}
@@ -0,0 +1,64 @@
+package format
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Middleware renders a tool's result in the output_format the caller asked
+// for. The result's text content is replaced by one text block in that
+// format; structuredContent, _meta and non-text content are kept. Results
+// without structuredContent whose first text is JSON (queued approvals,
+// replays of older results) are rendered from that JSON; errors and prose
+// are left alone.
+func Middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		format := req.GetString(Arg, "")
+		res, err := next(ctx, req)
+		if format == "" || err != nil || res == nil || res.IsError {
+			return res, err
+		}
+		v := res.StructuredContent
+		if v == nil {
+			if v = jsonText(res); v == nil {
+				return res, nil
+			}
+		}
+		text, rerr := Render(format, v)
+		if rerr != nil {
+			return mcp.NewToolResultError(rerr.Error()), nil
+		}
+		content := []mcp.Content{mcp.NewTextContent(text)}
+		for _, c := range res.Content {
+			if _, ok := c.(mcp.TextContent); !ok {
+				content = append(content, c)
+			}
+		}
+		res.Content = content
+		return res, nil
+	}
+}
+
+// jsonText decodes the first text block when it holds a JSON object or list.
+func jsonText(res *mcp.CallToolResult) any {
+	for _, c := range res.Content {
+		tc, ok := c.(mcp.TextContent)
+		if !ok {
+			continue
+		}
+		s := strings.TrimSpace(tc.Text)
+		if !strings.HasPrefix(s, "{") && !strings.HasPrefix(s, "[") {
+			return nil
+		}
+		var v any
+		if json.Unmarshal([]byte(s), &v) != nil {
+			return nil
+		}
+		return v
+	}
+	return nil
+}
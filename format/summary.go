@@ -0,0 +1,160 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+)
+
+// summaryItems caps how many records a summary names.
+const summaryItems = 10
+
+// labelKeys name a record in a summary, in order of preference.
+var labelKeys = []string{"name", "display_name", "title", "summary", "code", "default_code", "id"}
+
+// leadKeys open an object's summary: a tool's own message says what
+// happened better than any field list.
+var leadKeys = []string{"message", "status"}
+
+// summary describes v in a few plain lines: how many records and what they
+// are, or an object's message, its prose and its fields.
+func summary(v any) string {
+	var b strings.Builder
+	switch t := v.(type) {
+	case []any:
+		sumList(&b, t)
+	case map[string]any:
+		sumObject(&b, t)
+	default:
+		b.WriteString(Cell(t))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func sumList(b *strings.Builder, list []any) {
+	switch len(list) {
+	case 0:
+		b.WriteString("No results.\n")
+		return
+	case 1:
+		b.WriteString("1 result:\n")
+	default:
+		fmt.Fprintf(b, "%d results:\n", len(list))
+	}
+	for i, item := range list {
+		if i == summaryItems {
+			fmt.Fprintf(b, "… and %d more.\n", len(list)-summaryItems)
+			break
+		}
+		fmt.Fprintf(b, "- %s\n", describe(item))
+	}
+}
+
+func sumObject(b *strings.Builder, m map[string]any) {
+	done := map[string]bool{}
+	for _, k := range leadKeys {
+		if s, ok := m[k].(string); ok && s != "" {
+			b.WriteString(sentence(s) + "\n")
+			done[k] = true
+		}
+	}
+	for _, k := range keys(m) {
+		if isText(m[k]) && !done[k] {
+			b.WriteString(strings.TrimSpace(m[k].(string)) + "\n")
+			done[k] = true
+		}
+	}
+	for _, k := range keys(m) {
+		if done[k] {
+			continue
+		}
+		switch t := m[k].(type) {
+		case []any:
+			if len(t) == 0 {
+				fmt.Fprintf(b, "%s: none.\n", label(k))
+				continue
+			}
+			if !isTable(t) {
+				fmt.Fprintf(b, "%s: %s.\n", label(k), joinCells(t))
+				continue
+			}
+			fmt.Fprintf(b, "%s (%d):\n", label(k), len(t))
+			for i, item := range t {
+				if i == summaryItems {
+					fmt.Fprintf(b, "  … and %d more.\n", len(t)-summaryItems)
+					break
+				}
+				fmt.Fprintf(b, "  - %s\n", describe(item))
+			}
+		case map[string]any:
+			fmt.Fprintf(b, "%s: %s.\n", label(k), describe(t))
+		default:
+			fmt.Fprintf(b, "%s: %s.\n", label(k), Cell(t))
+		}
+	}
+}
+
+// describe names a record and lists its other scalar fields:
+// "WH/MO/01001 (product A100 - Standard Box, product qty 500, state confirmed)".
+func describe(v any) string {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return Cell(v)
+	}
+	name, nameKey := "", ""
+	for _, k := range labelKeys {
+		if s := Cell(m[k]); s != "" && !isText(m[k]) {
+			name, nameKey = s, k
+			break
+		}
+	}
+	var parts []string
+	for _, k := range keys(m) {
+		v := m[k]
+		if k == nameKey || k == "id" || isText(v) || v == nil || v == false {
+			continue
+		}
+		if _, nested := v.(map[string]any); nested {
+			continue
+		}
+		if list, ok := v.([]any); ok {
+			if _, pair := many2one(list); !pair {
+				continue
+			}
+		}
+		parts = append(parts, strings.ToLower(label(k))+" "+Cell(v))
+	}
+	switch {
+	case name == "":
+		return strings.Join(parts, ", ")
+	case len(parts) == 0:
+		return name
+	}
+	return name + " (" + strings.Join(parts, ", ") + ")"
+}
+
+// label turns a field name into words: "product_id" → "Product".
+func label(key string) string {
+	key = strings.TrimSuffix(key, "_ids")
+	key = strings.TrimSuffix(key, "_id")
+	words := strings.ReplaceAll(key, "_", " ")
+	if words == "" {
+		return key
+	}
+	return strings.ToUpper(words[:1]) + words[1:]
+}
+
+func sentence(s string) string {
+	s = strings.TrimSpace(s)
+	if strings.HasSuffix(s, ".") || strings.HasSuffix(s, "!") || strings.HasSuffix(s, "?") {
+		return s
+	}
+	return s + "."
+}
+
+func joinCells(list []any) string {
+	cells := make([]string, len(list))
+	for i, v := range list {
+		cells[i] = Cell(v)
+	}
+	return strings.Join(cells, ", ")
+}
@@ -0,0 +1,196 @@
+// Package health probes the server's dependencies (Odoo, Bedrock, the
+// transports) and serves /healthz and /readyz for container orchestration.
+//
+// /healthz is liveness: it answers from the last probe results and only
+// fails when the process cannot serve. /readyz is readiness: it probes
+// (results are reused for a short TTL) and returns 503 while any critical
+// dependency is down.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Probe checks one dependency. The detail string is reported on success
+// (a version, a login, a model count).
+type Probe func(ctx context.Context) (detail string, err error)
+
+// Status values.
+const (
+	StatusOK      = "ok"
+	StatusFail    = "fail"
+	StatusUnknown = "unknown" // not probed yet
+)
+
+// Result is the latest outcome of one check.
+type Result struct {
+	Name        string    `json:"name"`
+	Critical    bool      `json:"critical"`
+	Status      string    `json:"status"`
+	Detail      string    `json:"detail,omitempty"`
+	LatencyMS   float64   `json:"latency_ms"`
+	CheckedAt   time.Time `json:"checked_at,omitzero"`
+	LastError   string    `json:"last_error,omitempty"`
+	LastErrorAt time.Time `json:"last_error_at,omitzero"`
+}
+
+// Report is the body of /healthz and /readyz.
+type Report struct {
+	Status string    `json:"status"` // ok, degraded (a non-critical check failed) or fail
+	Uptime string    `json:"uptime"`
+	Checks []Result  `json:"checks"`
+	At     time.Time `json:"at"`
+}
+
+type check struct {
+	critical bool
+	probe    Probe
+}
+
+// Checker runs the registered probes.
+type Checker struct {
+	Timeout time.Duration // per probe
+	TTL     time.Duration // how long readiness reuses a result
+
+	started time.Time
+	mu      sync.Mutex
+	checks  map[string]check
+	results map[string]*Result
+}
+
+// New creates a checker with a per-probe timeout and result TTL.
+func New(timeout, ttl time.Duration) *Checker {
+	return &Checker{
+		Timeout: timeout,
+		TTL:     ttl,
+		started: time.Now(),
+		checks:  map[string]check{},
+		results: map[string]*Result{},
+	}
+}
+
+// Add registers a probe. Critical probes decide readiness; the others only
+// degrade the report.
+func (c *Checker) Add(name string, critical bool, p Probe) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks[name] = check{critical: critical, probe: p}
+	c.results[name] = &Result{Name: name, Critical: critical, Status: StatusUnknown}
+}
+
+// Check probes every dependency whose result is older than maxAge,
+// concurrently, and returns the report.
+func (c *Checker) Check(ctx context.Context, maxAge time.Duration) Report {
+	c.mu.Lock()
+	var stale []string
+	now := time.Now()
+	for name, r := range c.results {
+		if r.CheckedAt.IsZero() || now.Sub(r.CheckedAt) >= maxAge {
+			stale = append(stale, name)
+		}
+	}
+	c.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, name := range stale {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			c.run(ctx, name)
+		}(name)
+	}
+	wg.Wait()
+	return c.Report()
+}
+
+func (c *Checker) run(ctx context.Context, name string) {
+	c.mu.Lock()
+	ch := c.checks[name]
+	c.mu.Unlock()
+
+	pctx := ctx
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		pctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+	start := time.Now()
+	detail, err := ch.probe(pctx)
+	elapsed := time.Since(start)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r := c.results[name]
+	r.CheckedAt = start
+	r.LatencyMS = float64(elapsed.Microseconds()) / 1000
+	r.Detail = detail
+	if err != nil {
+		r.Status = StatusFail
+		r.LastError = err.Error()
+		r.LastErrorAt = start
+	} else {
+		r.Status = StatusOK
+	}
+}
+
+// Report returns the latest results without probing.
+func (c *Checker) Report() Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	rep := Report{Status: StatusOK, Uptime: time.Since(c.started).Round(time.Second).String(), At: time.Now()}
+	for _, r := range c.results {
+		rep.Checks = append(rep.Checks, *r)
+		switch {
+		case r.Status == StatusOK:
+		case r.Critical:
+			rep.Status = StatusFail
+		case rep.Status == StatusOK:
+			rep.Status = "degraded"
+		}
+	}
+	sort.Slice(rep.Checks, func(i, j int) bool { return rep.Checks[i].Name < rep.Checks[j].Name })
+	return rep
+}
+
+// Run refreshes every probe each interval so /healthz stays current.
+func (c *Checker) Run(ctx context.Context, interval time.Duration) {
+	c.Check(ctx, 0)
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			c.Check(ctx, 0)
+		}
+	}
+}
+
+// Healthz reports liveness from the cached results. It always answers 200
+// while the process can serve requests; dependency trouble shows in the body.
+func (c *Checker) Healthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, c.Report())
+}
+
+// Readyz probes stale dependencies and answers 503 while a critical one fails.
+func (c *Checker) Readyz(w http.ResponseWriter, r *http.Request) {
+	rep := c.Check(r.Context(), c.TTL)
+	code := http.StatusOK
+	if rep.Status == StatusFail {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, rep)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,140 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	bedrocklib "mcp-bedrock-go/bedrock"
+	odoolib "mcp-bedrock-go/odoo"
+)
+
+// Odoo checks that the server answers (version) and that our UID and API key
+// are still accepted. A rejected UID triggers one re-login, so an expired
+// session recovers without a restart.
+func Odoo(oc *odoolib.Client) Probe {
+	var mu sync.Mutex // serialise re-logins
+	return func(ctx context.Context) (string, error) {
+		version, err := oc.Version(ctx)
+		if err != nil {
+			return "", fmt.Errorf("version: %w", err)
+		}
+		login, err := oc.CheckUID(ctx)
+		if err != nil {
+			mu.Lock()
+			lerr := oc.LoginContext(ctx)
+			mu.Unlock()
+			if lerr != nil {
+				return "version " + version, fmt.Errorf("uid check: %v; re-login: %w", err, lerr)
+			}
+			if login, err = oc.CheckUID(ctx); err != nil {
+				return "version " + version, fmt.Errorf("uid check after re-login: %w", err)
+			}
+		}
+		return fmt.Sprintf("version %s, uid %d (%s)", version, oc.UID(), login), nil
+	}
+}
+
+// AWSCredentials checks that the default credential chain resolves to
+// unexpired credentials.
+func AWSCredentials(cfg aws.Config) Probe {
+	return func(ctx context.Context) (string, error) {
+		if cfg.Credentials == nil {
+			return "", errors.New("no credential provider configured")
+		}
+		creds, err := cfg.Credentials.Retrieve(ctx)
+		if err != nil {
+			return "", err
+		}
+		if creds.AccessKeyID == "" {
+			return "", errors.New("empty access key")
+		}
+		if creds.CanExpire && time.Until(creds.Expires) <= 0 {
+			return "", fmt.Errorf("credentials expired at %s", creds.Expires.Format(time.RFC3339))
+		}
+		detail := fmt.Sprintf("region %s, source %s", cfg.Region, creds.Source)
+		if creds.CanExpire {
+			detail += ", expires " + creds.Expires.Format(time.RFC3339)
+		}
+		return detail, nil
+	}
+}
+
+// Models fails when every model in the fallback chain is cooling down after
+// errors, i.e. the next LLM call would have nowhere healthy to go.
+func Models(chain *bedrocklib.Chain) Probe {
+	return func(ctx context.Context) (string, error) {
+		now := time.Now()
+		healthy := 0
+		var last string
+		for _, h := range chain.Health() {
+			if h.Healthy(now) {
+				healthy++
+			} else if h.LastError != "" {
+				last = h.Model + ": " + h.LastError
+			}
+		}
+		detail := fmt.Sprintf("%d of %d models healthy", healthy, len(chain.Models()))
+		if healthy == 0 {
+			return detail, fmt.Errorf("all models cooling down (%s)", last)
+		}
+		return detail, nil
+	}
+}
+
+// Transports tracks transport state reported by transport.Serve.
+type Transports struct {
+	mu    sync.Mutex
+	state map[string]error // nil while up
+}
+
+// NewTransports expects the named transports to come up.
+func NewTransports(names []string) *Transports {
+	t := &Transports{state: map[string]error{}}
+	for _, n := range names {
+		t.state[n] = errors.New("not started")
+	}
+	return t
+}
+
+// OnState is passed as transport.Options.OnState.
+func (t *Transports) OnState(name string, up bool, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch {
+	case up:
+		t.state[name] = nil
+	case err != nil:
+		t.state[name] = err
+	default:
+		t.state[name] = errors.New("stopped")
+	}
+}
+
+// Probe fails while any expected transport is not serving.
+func (t *Transports) Probe() Probe {
+	return func(ctx context.Context) (string, error) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		names := make([]string, 0, len(t.state))
+		for name := range t.state {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		var errs []error
+		var up []string
+		for _, name := range names {
+			if err := t.state[name]; err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			} else {
+				up = append(up, name)
+			}
+		}
+		return fmt.Sprintf("serving %v", up), errors.Join(errs...)
+	}
+}
@@ -0,0 +1,68 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	odoolib "mcp-bedrock-go/odoo"
+)
+
+// staleOdoo logs everyone in as uid 2 but never finds the user afterwards,
+// so every UID check fails and the probe re-logs in.
+func staleOdoo() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Params struct {
+				Method string `json:"method"`
+			} `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		var result any = []any{}
+		switch req.Params.Method {
+		case "authenticate":
+			result = 2
+		case "version":
+			result = map[string]any{"server_version": "stub"}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "result": result})
+	}))
+}
+
+// TestOdooReloginConcurrent re-logs in from the probe while tool calls use
+// the same client; run with -race.
+func TestOdooReloginConcurrent(t *testing.T) {
+	srv := staleOdoo()
+	defer srv.Close()
+	oc := odoolib.New(srv.URL, "db", "user", "key")
+	if err := oc.Login(); err != nil {
+		t.Fatal(err)
+	}
+
+	probe := Odoo(oc)
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, err := probe(ctx); err == nil || !strings.Contains(err.Error(), "after re-login") {
+				t.Errorf("probe: got %v, want a failure after re-login", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := oc.SearchReadContext(ctx, "mrp.production", []string{"id"}, nil); err != nil {
+				t.Errorf("search_read: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if oc.UID() != 2 {
+		t.Fatalf("uid %d, want 2", oc.UID())
+	}
+}
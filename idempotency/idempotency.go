@@ -0,0 +1,331 @@
+// Package idempotency makes write tools safe to retry. A call carrying an
+// idempotency_key (or, within a short window, the same arguments from the
+// same MCP session) returns the result of the first call instead of
+// creating another record. Results are kept in a file so retries across a
+// restart are still recognised.
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mcp-bedrock-go/auth"
+	"mcp-bedrock-go/format"
+	"mcp-bedrock-go/internal/logging"
+)
+
+var logger = logging.For("idempotency")
+
+// Arg is the tool argument that carries the caller's key.
+const Arg = "idempotency_key"
+
+// Record is a remembered result.
+type Record struct {
+	Key       string          `json:"key"`  // hash of tool, caller and key
+	Tool      string          `json:"tool"` // for inspection of the file
+	ArgsHash  string          `json:"args_hash"`
+	Derived   bool            `json:"derived,omitempty"` // key came from session and arguments
+	Action    string          `json:"action,omitempty"`  // approval the result still waits for
+	Result    json.RawMessage `json:"result"`
+	CreatedAt time.Time       `json:"created_at"`
+	ExpiresAt time.Time       `json:"expires_at"`
+}
+
+// Store remembers the results of keyed write calls.
+type Store struct {
+	Window       time.Duration          // how long an explicit key is remembered
+	DeriveWindow time.Duration          // same for keys derived from the session; 0 disables them
+	Tools        func(name string) bool // which tools take part
+
+	path     string
+	mu       sync.Mutex
+	records  map[string]*Record
+	inFlight map[string]chan struct{}
+}
+
+// Open loads the store kept in path, dropping expired records. An empty
+// path keeps results in memory only.
+func Open(path string, window, deriveWindow time.Duration, tools func(string) bool) (*Store, error) {
+	s := &Store{
+		Window:       window,
+		DeriveWindow: deriveWindow,
+		Tools:        tools,
+		path:         path,
+		records:      map[string]*Record{},
+		inFlight:     map[string]chan struct{}{},
+	}
+	if path == "" {
+		return s, nil
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var recs []*Record
+	if err := json.Unmarshal(b, &recs); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	now := time.Now()
+	for _, r := range recs {
+		// the approvals queue lives in memory, so a waiting action did not
+		// survive the restart
+		if now.Before(r.ExpiresAt) && r.Action == "" {
+			s.records[r.Key] = r
+		}
+	}
+	logger.Infof("idempotency: loaded %d keys from %s", len(s.records), path)
+	return s, nil
+}
+
+// Middleware returns the stored result for a repeated key and records the
+// first successful result otherwise. Dry runs (requested, or forced by the
+// server's dry-run mode) and errors are not recorded, so a failed call can
+// be retried with the same key. A call queued for approval is recorded as
+// pending until Settle learns how it ended. Reusing a key with
+// different arguments is an error.
+func (s *Store) Middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if s.Tools != nil && !s.Tools(req.Params.Name) || req.GetBool("dry_run", false) {
+			return next(ctx, req)
+		}
+		args := req.GetArguments()
+		argsHash := hashArgs(args)
+		key, window, derived := s.key(ctx, req.Params.Name, req.GetString(Arg, ""), argsHash)
+		if key == "" {
+			return next(ctx, req)
+		}
+
+		for {
+			s.mu.Lock()
+			if r, ok := s.records[key]; ok && time.Now().Before(r.ExpiresAt) {
+				s.mu.Unlock()
+				if r.ArgsHash != argsHash {
+					return mcp.NewToolResultError(fmt.Sprintf("%s %q was already used with different arguments", Arg, req.GetString(Arg, ""))), nil
+				}
+				return replay(r)
+			}
+			wait, busy := s.inFlight[key]
+			if !busy {
+				s.inFlight[key] = make(chan struct{})
+				s.mu.Unlock()
+				break
+			}
+			s.mu.Unlock()
+			// the first call is still running; its result answers this one
+			select {
+			case <-wait:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
+		res, err := next(ctx, req)
+
+		s.mu.Lock()
+		done := s.inFlight[key]
+		delete(s.inFlight, key)
+		if err == nil && res != nil && !res.IsError && !dryRun(res) {
+			if raw, merr := json.Marshal(res); merr == nil {
+				now := time.Now()
+				s.records[key] = &Record{
+					Key:       key,
+					Tool:      req.Params.Name,
+					ArgsHash:  argsHash,
+					Derived:   derived,
+					Action:    pendingAction(res),
+					Result:    raw,
+					CreatedAt: now,
+					ExpiresAt: now.Add(window),
+				}
+				s.saveLocked()
+			}
+		}
+		s.mu.Unlock()
+		close(done)
+		return res, err
+	}
+}
+
+// Settle replaces the pending result recorded for an approval action with
+// the result of the approved call, or forgets it when res is nil because the
+// action was rejected, expired or failed, so a retry queues it afresh.
+func (s *Store) Settle(action string, res *mcp.CallToolResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, r := range s.records {
+		if r.Action != action {
+			continue
+		}
+		raw, err := json.Marshal(res)
+		if res == nil || err != nil {
+			delete(s.records, k)
+			logger.Infof("idempotency: forgot %s key waiting for action %s", r.Tool, action)
+		} else {
+			r.Result, r.Action = raw, ""
+		}
+		s.saveLocked()
+		return
+	}
+}
+
+// key scopes the caller's key to the tool and principal, so two clients
+// choosing the same key do not see each other's results. Without a key it
+// derives one from the MCP session and the arguments.
+func (s *Store) key(ctx context.Context, tool, given, argsHash string) (string, time.Duration, bool) {
+	subject := ""
+	if p := auth.FromContext(ctx); p != nil {
+		subject = p.Subject
+	}
+	if given != "" {
+		return hash(tool, subject, given), s.Window, false
+	}
+	if s.DeriveWindow <= 0 {
+		return "", 0, false
+	}
+	sess := server.ClientSessionFromContext(ctx)
+	if sess == nil || sess.SessionID() == "" {
+		return "", 0, false
+	}
+	return hash(tool, subject, "session:"+sess.SessionID(), argsHash), s.DeriveWindow, true
+}
+
+// replay returns a stored result, marked so the caller can tell.
+func replay(r *Record) (*mcp.CallToolResult, error) {
+	raw := r.Result
+	res, err := mcp.ParseCallToolResult(&raw)
+	if err != nil {
+		return nil, fmt.Errorf("idempotency: stored result for %s: %w", r.Tool, err)
+	}
+	if res.Meta == nil {
+		res.Meta = &mcp.Meta{}
+	}
+	if res.Meta.AdditionalFields == nil {
+		res.Meta.AdditionalFields = map[string]any{}
+	}
+	res.Meta.AdditionalFields["idempotent_replay"] = true
+	res.Meta.AdditionalFields["original_at"] = r.CreatedAt
+	logger.Infof("idempotency: replayed %s result from %s", r.Tool, r.CreatedAt.Format(time.RFC3339))
+	return res, nil
+}
+
+// Sweep forgets expired records and returns how many.
+func (s *Store) Sweep() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	n := 0
+	for k, r := range s.records {
+		if !now.Before(r.ExpiresAt) {
+			delete(s.records, k)
+			n++
+		}
+	}
+	if n > 0 {
+		s.saveLocked()
+	}
+	return n
+}
+
+// Run sweeps the store every interval until ctx is cancelled.
+func (s *Store) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if n := s.Sweep(); n > 0 {
+				logger.Debugf("idempotency: %d keys expired", n)
+			}
+		}
+	}
+}
+
+// saveLocked writes the records to a temporary file and renames it over
+// the store, so a crash never leaves half a file. Callers hold s.mu.
+func (s *Store) saveLocked() {
+	if s.path == "" {
+		return
+	}
+	recs := make([]*Record, 0, len(s.records))
+	for _, r := range s.records {
+		recs = append(recs, r)
+	}
+	b, err := json.Marshal(recs)
+	if err == nil {
+		tmp, terr := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+		if err = terr; err == nil {
+			_, err = tmp.Write(b)
+			if cerr := tmp.Close(); err == nil {
+				err = cerr
+			}
+			if err == nil {
+				err = os.Rename(tmp.Name(), s.path)
+			}
+			if err != nil {
+				os.Remove(tmp.Name())
+			}
+		}
+	}
+	if err != nil {
+		logger.Errorf("idempotency: saving %s: %v", s.path, err)
+	}
+}
+
+// dryRun reports whether a write tool only reported what it would do; the
+// tools mark such results in their metadata.
+func dryRun(res *mcp.CallToolResult) bool {
+	if res.Meta == nil {
+		return false
+	}
+	v, _ := res.Meta.AdditionalFields["dry_run"].(bool)
+	return v
+}
+
+// pendingAction returns the approval action a queued call waits for; the
+// approvals queue marks such results in their metadata.
+func pendingAction(res *mcp.CallToolResult) string {
+	if res.Meta == nil {
+		return ""
+	}
+	id, _ := res.Meta.AdditionalFields["pending_action"].(string)
+	return id
+}
+
+// hashArgs hashes the arguments other than the key itself and the output
+// format, which does not change what is written; json.Marshal sorts map
+// keys, so equal arguments hash equally.
+func hashArgs(args map[string]any) string {
+	rest := make(map[string]any, len(args))
+	for k, v := range args {
+		if k != Arg && k != format.Arg {
+			rest[k] = v
+		}
+	}
+	b, _ := json.Marshal(rest)
+	return hash(string(b))
+}
+
+func hash(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
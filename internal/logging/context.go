@@ -0,0 +1,89 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID returns ctx carrying a request ID for log correlation.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID on ctx, or "".
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// NewRequestID returns a random 16-character ID.
+func NewRequestID() string {
+	var b [8]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+var (
+	extraMu sync.RWMutex
+	extra   []func(context.Context) []slog.Attr
+)
+
+// AddContextAttrs registers a source of per-request attributes, e.g. the
+// tracing package's trace_id.
+func AddContextAttrs(fn func(context.Context) []slog.Attr) {
+	extraMu.Lock()
+	extra = append(extra, fn)
+	extraMu.Unlock()
+}
+
+func contextAttrs(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	var out []slog.Attr
+	if id := RequestID(ctx); id != "" {
+		out = append(out, slog.String("request_id", id))
+	}
+	if sess := server.ClientSessionFromContext(ctx); sess != nil {
+		out = append(out, slog.String("session_id", sess.SessionID()))
+	}
+	extraMu.RLock()
+	defer extraMu.RUnlock()
+	for _, fn := range extra {
+		out = append(out, fn(ctx)...)
+	}
+	return out
+}
+
+// Middleware gives every HTTP request an ID, taken from X-Request-ID when
+// the caller sent one, and echoes it in the response.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if id == "" || len(id) > 128 {
+			id = NewRequestID()
+		}
+		w.Header().Set("X-Request-ID", id)
+		next.ServeHTTP(w, r.WithContext(WithRequestID(r.Context(), id)))
+	})
+}
+
+// ToolMiddleware gives tool calls that arrived without one (stdio, SSE) a
+// request ID, so every line a call logs can be correlated.
+func ToolMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if RequestID(ctx) == "" {
+			ctx = WithRequestID(ctx, NewRequestID())
+		}
+		return next(ctx, req)
+	}
+}
@@ -1,35 +1,209 @@
+// Package logging is the server's structured logger, built on log/slog. Each
+// package logs through a component logger (For("odoo")) whose level can be set
+// separately; records carry the request, session and trace IDs found on the
+// context, and secrets are redacted before anything is written.
 package logging
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"strings"
+	"sync/atomic"
 )
 
-// Debug is enabled when environment variable DEBUG is set to "1" or "true".
-var Debug bool
+// Options configures Setup.
+type Options struct {
+	Format     string            // json (default) or text
+	Level      string            // debug, info (default), warn, error
+	Components map[string]string // component → level, overriding Level
+	Redact     []string          // attribute names whose values are masked
+	Output     io.Writer         // default os.Stderr; stdout belongs to the stdio transport
+}
+
+// state is the active configuration, swapped atomically by Setup.
+type state struct {
+	handler slog.Handler
+	level   slog.Level
+	levels  map[string]slog.Level
+}
+
+var cur atomic.Pointer[state]
 
 func init() {
-	d := os.Getenv("DEBUG")
-	if d == "1" || d == "true" {
-		Debug = true
-	} else {
-		Debug = false
+	// Until Setup runs: JSON on stderr, debug when DEBUG is set as before.
+	level := "info"
+	if d := os.Getenv("DEBUG"); d == "1" || d == "true" {
+		level = "debug"
+	}
+	if err := Setup(Options{Level: level}); err != nil {
+		panic(err)
 	}
 }
 
-// Debugf logs when Debug is enabled.
-func Debugf(format string, v ...any) {
-	if Debug {
-		log.Printf("[DEBUG] "+format, v...)
+// ParseLevel accepts debug, info, warn/warning and error.
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
 	}
+	return 0, fmt.Errorf("unknown log level %q (have: debug, info, warn, error)", s)
+}
+
+// Setup replaces the logging configuration. Component loggers created
+// earlier pick it up immediately, and the standard library's log package
+// is routed through it as component "app".
+func Setup(opts Options) error {
+	st := &state{levels: map[string]slog.Level{}}
+	var err error
+	if st.level, err = ParseLevel(opts.Level); err != nil {
+		return err
+	}
+	for name, l := range opts.Components {
+		if st.levels[name], err = ParseLevel(l); err != nil {
+			return fmt.Errorf("component %s: %w", name, err)
+		}
+	}
+	out := opts.Output
+	if out == nil {
+		out = os.Stderr
+	}
+	hopts := &slog.HandlerOptions{
+		Level:       slog.LevelDebug, // components filter first
+		ReplaceAttr: redactor(opts.Redact),
+	}
+	switch strings.ToLower(opts.Format) {
+	case "", "json":
+		st.handler = slog.NewJSONHandler(out, hopts)
+	case "text":
+		st.handler = slog.NewTextHandler(out, hopts)
+	default:
+		return fmt.Errorf("unknown log format %q (have: json, text)", opts.Format)
+	}
+	cur.Store(st)
+	slog.SetDefault(For("app").Logger)
+	return nil
+}
+
+// Logger is a component logger. Code handling a request logs with the *Ctxf
+// methods so lines carry its request, session and trace IDs; the plain *f
+// methods are for startup and background work. The embedded slog.Logger's
+// structured calls (DebugContext(ctx, "msg", "payload", v)) are for payloads,
+// so redaction can mask them by attribute name.
+type Logger struct {
+	*slog.Logger
+}
+
+// For returns the logger of a component. It is safe to call from package
+// variable initialisers, before Setup.
+func For(component string) *Logger {
+	return &Logger{slog.New(&componentHandler{component: component})}
+}
+
+func (l *Logger) logf(ctx context.Context, level slog.Level, format string, v []any) {
+	if !l.Enabled(ctx, level) {
+		return
+	}
+	l.Log(ctx, level, fmt.Sprintf(format, v...))
+}
+
+// Debugf logs at debug level.
+func (l *Logger) Debugf(format string, v ...any) {
+	l.logf(context.Background(), slog.LevelDebug, format, v)
+}
+
+// Infof logs at info level.
+func (l *Logger) Infof(format string, v ...any) {
+	l.logf(context.Background(), slog.LevelInfo, format, v)
+}
+
+// Warnf logs at warn level.
+func (l *Logger) Warnf(format string, v ...any) {
+	l.logf(context.Background(), slog.LevelWarn, format, v)
 }
 
-// Infof always logs informational messages.
-func Infof(format string, v ...any) {
-	log.Printf("[INFO] "+format, v...)
+// Errorf logs at error level.
+func (l *Logger) Errorf(format string, v ...any) {
+	l.logf(context.Background(), slog.LevelError, format, v)
 }
 
-// Errorf always logs errors.
-func Errorf(format string, v ...any) {
-	log.Printf("[ERROR] "+format, v...)
+// DebugCtxf is Debugf with the request, session and trace IDs on ctx.
+func (l *Logger) DebugCtxf(ctx context.Context, format string, v ...any) {
+	l.logf(ctx, slog.LevelDebug, format, v)
 }
+
+// InfoCtxf is Infof with the IDs on ctx.
+func (l *Logger) InfoCtxf(ctx context.Context, format string, v ...any) {
+	l.logf(ctx, slog.LevelInfo, format, v)
+}
+
+// WarnCtxf is Warnf with the IDs on ctx.
+func (l *Logger) WarnCtxf(ctx context.Context, format string, v ...any) {
+	l.logf(ctx, slog.LevelWarn, format, v)
+}
+
+// ErrorCtxf is Errorf with the IDs on ctx.
+func (l *Logger) ErrorCtxf(ctx context.Context, format string, v ...any) {
+	l.logf(ctx, slog.LevelError, format, v)
+}
+
+// componentHandler tags records with their component, applies the
+// component's level and adds context IDs before handing them to the active
+// handler.
+type componentHandler struct {
+	component string
+	ops       []func(slog.Handler) slog.Handler // WithAttrs/WithGroup calls, in order
+}
+
+func (h *componentHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	st := cur.Load()
+	lvl, ok := st.levels[h.component]
+	if !ok {
+		lvl = st.level
+	}
+	return level >= lvl
+}
+
+func (h *componentHandler) Handle(ctx context.Context, r slog.Record) error {
+	st := cur.Load()
+	r.Message = scrub(r.Message)
+	out := st.handler.WithAttrs(append([]slog.Attr{slog.String("component", h.component)}, contextAttrs(ctx)...))
+	for _, op := range h.ops {
+		out = op(out)
+	}
+	return out.Handle(ctx, r)
+}
+
+func (h *componentHandler) with(op func(slog.Handler) slog.Handler) slog.Handler {
+	cp := *h
+	cp.ops = append(append([]func(slog.Handler) slog.Handler(nil), h.ops...), op)
+	return &cp
+}
+
+func (h *componentHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(x slog.Handler) slog.Handler { return x.WithAttrs(attrs) })
+}
+
+func (h *componentHandler) WithGroup(name string) slog.Handler {
+	return h.with(func(x slog.Handler) slog.Handler { return x.WithGroup(name) })
+}
+
+// std is the logger behind the package-level functions.
+var std = For("app")
+
+// Debugf logs at debug level as component "app".
+func Debugf(format string, v ...any) { std.Debugf(format, v...) }
+
+// Infof logs at info level as component "app".
+func Infof(format string, v ...any) { std.Infof(format, v...) }
+
+// Errorf logs at error level as component "app".
+func Errorf(format string, v ...any) { std.Errorf(format, v...) }
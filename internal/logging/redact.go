@@ -0,0 +1,131 @@
+package logging
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces masked values.
+const Redacted = "[REDACTED]"
+
+// DefaultRedact lists the attribute names always masked; Options.Redact adds
+// to them. A name also matches as a suffix after "_" or "-", so "secret"
+// covers "hmac_secret".
+var DefaultRedact = []string{"api_key", "api-key", "password", "passwd", "secret", "token", "authorization", "cookie", "prompt", "response"}
+
+var (
+	secretsMu sync.RWMutex
+	refs      = map[string]int{} // secret → registrations not yet forgotten
+	secrets   []string           // the keys of refs, longest first
+)
+
+// Secret registers values that must never appear in a log line, such as the
+// Odoo API key, which travels positionally inside execute_kw arguments where
+// no attribute name gives it away. Very short values are ignored.
+func Secret(values ...string) {
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	for _, v := range values {
+		if len(v) >= 6 {
+			refs[v]++
+		}
+	}
+	sortSecrets()
+}
+
+// Forget undoes one Secret call per value, for credentials that are only
+// held for a while (a delegated user's API key). A value stays masked until
+// every registration of it is forgotten.
+func Forget(values ...string) {
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	for _, v := range values {
+		if refs[v] > 1 {
+			refs[v]--
+		} else {
+			delete(refs, v)
+		}
+	}
+	sortSecrets()
+}
+
+// sortSecrets rebuilds secrets from refs, longest first so a secret that
+// contains another is masked whole. Callers hold secretsMu.
+func sortSecrets() {
+	secrets = secrets[:0]
+	for v := range refs {
+		secrets = append(secrets, v)
+	}
+	slices.SortFunc(secrets, func(a, b string) int { return len(b) - len(a) })
+}
+
+// scrub masks every registered secret in s.
+func scrub(s string) string {
+	secretsMu.RLock()
+	defer secretsMu.RUnlock()
+	for _, v := range secrets {
+		if strings.Contains(s, v) {
+			s = strings.ReplaceAll(s, v, Redacted)
+		}
+	}
+	return s
+}
+
+// redactor builds the ReplaceAttr hook masking sensitive names and secrets.
+func redactor(extra []string) func(groups []string, a slog.Attr) slog.Attr {
+	names := append(slices.Clone(DefaultRedact), extra...)
+	sensitive := func(key string) bool {
+		key = strings.ToLower(key)
+		for _, n := range names {
+			n = strings.ToLower(n)
+			if key == n || strings.HasSuffix(key, "_"+n) || strings.HasSuffix(key, "-"+n) {
+				return true
+			}
+		}
+		return false
+	}
+	var redact func(v any) any
+	redact = func(v any) any {
+		switch x := v.(type) {
+		case string:
+			return scrub(x)
+		case map[string]any:
+			out := make(map[string]any, len(x))
+			for k, e := range x {
+				if sensitive(k) {
+					out[k] = Redacted
+				} else {
+					out[k] = redact(e)
+				}
+			}
+			return out
+		case []any:
+			out := make([]any, len(x))
+			for i, e := range x {
+				out[i] = redact(e)
+			}
+			return out
+		case []string:
+			out := make([]string, len(x))
+			for i, e := range x {
+				out[i] = scrub(e)
+			}
+			return out
+		}
+		return v
+	}
+	return func(groups []string, a slog.Attr) slog.Attr {
+		if sensitive(a.Key) {
+			return slog.String(a.Key, Redacted)
+		}
+		switch a.Value.Kind() {
+		case slog.KindString:
+			return slog.String(a.Key, scrub(a.Value.String()))
+		case slog.KindAny:
+			return slog.Any(a.Key, redact(a.Value.Any()))
+		}
+		return a
+	}
+}
@@ -0,0 +1,46 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRedactExtendsDefaults(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Setup(Options{Redact: []string{"customer_email"}, Output: &buf}); err != nil {
+		t.Fatal(err)
+	}
+	defer Setup(Options{})
+
+	For("test").InfoContext(context.Background(), "call",
+		"customer_email", "a@example.com", "api_key", "k-123456", "hmac_secret", "s-123456", "qty", 5)
+	out := buf.String()
+	for _, leak := range []string{"a@example.com", "k-123456", "s-123456"} {
+		if strings.Contains(out, leak) {
+			t.Errorf("%q not redacted: %s", leak, out)
+		}
+	}
+	if !strings.Contains(out, `"qty":5`) {
+		t.Errorf("qty should be kept: %s", out)
+	}
+}
+
+func TestForgetSecret(t *testing.T) {
+	Secret("pooled-key-1", "pooled-key-1")
+	Forget("pooled-key-1")
+	if got := scrub("key pooled-key-1"); got != "key "+Redacted {
+		t.Fatalf("still registered once, got %q", got)
+	}
+	Forget("pooled-key-1")
+	if got := scrub("key pooled-key-1"); got != "key pooled-key-1" {
+		t.Fatalf("forgotten, got %q", got)
+	}
+
+	Secret("abcdef", "abcdefgh")
+	defer Forget("abcdef", "abcdefgh")
+	if got := scrub("abcdefgh"); got != Redacted {
+		t.Fatalf("longer secret masked in part: %q", got)
+	}
+}
@@ -0,0 +1,35 @@
+package metrics
+
+// The metrics exported by the server. They live here rather than in the
+// packages that update them so /metrics has one documented catalogue.
+var (
+	ToolCalls = NewCounter("mcp_tool_calls_total",
+		"MCP tool calls by tool and outcome (ok, error: tool reported an error, failure: handler failed).",
+		"tool", "outcome")
+	ToolDuration = NewHistogram("mcp_tool_duration_seconds",
+		"MCP tool call latency.", nil, "tool")
+	ActiveSessions = NewGauge("mcp_active_sessions",
+		"MCP sessions currently registered.")
+
+	OdooRPCDuration = NewHistogram("odoo_rpc_duration_seconds",
+		"Odoo JSON-RPC latency by model, method and outcome (ok, error).", nil, "model", "method", "outcome")
+	OdooClients = NewGauge("odoo_user_clients",
+		"Per-user Odoo clients currently logged in.")
+
+	BedrockDuration = NewHistogram("bedrock_invoke_duration_seconds",
+		"Bedrock InvokeModel latency by model, operation (generate, embed) and outcome (ok or an error class such as throttling).", nil, "model", "operation", "outcome")
+	BedrockTokens = NewCounter("bedrock_tokens_total",
+		"Tokens reported by Bedrock by model and direction (input, output).", "model", "direction")
+
+	CacheRequests = NewCounter("cache_requests_total",
+		"Cache lookups by cache and result (hit, miss); hit ratio = hit / (hit + miss).", "cache", "result")
+)
+
+// CacheLookup counts one lookup in the named cache.
+func CacheLookup(cache string, hit bool) {
+	if hit {
+		CacheRequests.Inc(cache, "hit")
+	} else {
+		CacheRequests.Inc(cache, "miss")
+	}
+}
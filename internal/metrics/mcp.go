@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ToolMiddleware records the outcome and latency of every tool call.
+func ToolMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		res, err := next(ctx, req)
+		outcome := "ok"
+		switch {
+		case err != nil:
+			outcome = "failure"
+		case res != nil && res.IsError:
+			outcome = "error"
+		}
+		ToolCalls.Inc(req.Params.Name, outcome)
+		ToolDuration.Observe(time.Since(start).Seconds(), req.Params.Name)
+		return res, err
+	}
+}
+
+// Hooks keeps mcp_active_sessions in step with session registration.
+func Hooks(h *server.Hooks) {
+	h.AddOnRegisterSession(func(ctx context.Context, sess server.ClientSession) {
+		ActiveSessions.Add(1)
+	})
+	h.AddOnUnregisterSession(func(ctx context.Context, sess server.ClientSession) {
+		ActiveSessions.Add(-1)
+	})
+}
@@ -0,0 +1,256 @@
+// Package metrics keeps process-wide counters, gauges and histograms and
+// serves them in the Prometheus text exposition format on /metrics.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefBuckets are latency buckets in seconds, from 5ms to 1 minute.
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60}
+
+type metric interface {
+	write(w io.Writer)
+}
+
+var (
+	mu      sync.Mutex
+	metrics []metric
+	names   = map[string]bool{}
+)
+
+func register(name string, m metric) {
+	mu.Lock()
+	defer mu.Unlock()
+	if names[name] {
+		panic("metrics: duplicate metric " + name)
+	}
+	names[name] = true
+	metrics = append(metrics, m)
+}
+
+// vec holds one value per label combination.
+type vec[T any] struct {
+	name, help, kind string
+	labels           []string
+
+	mu     sync.Mutex
+	series map[string]*T
+	values map[string][]string
+}
+
+func newVec[T any](name, help, kind string, labels []string) *vec[T] {
+	return &vec[T]{name: name, help: help, kind: kind, labels: labels, series: map[string]*T{}, values: map[string][]string{}}
+}
+
+// get returns the series for the label values, creating it with init. The
+// caller holds v.mu.
+func (v *vec[T]) get(values []string, init func() *T) *T {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: %s wants %d label values, got %d", v.name, len(v.labels), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+	s, ok := v.series[key]
+	if !ok {
+		s = init()
+		v.series[key] = s
+		v.values[key] = append([]string(nil), values...)
+	}
+	return s
+}
+
+// each visits the series in label order. The caller holds v.mu.
+func (v *vec[T]) each(fn func(labels string, s *T)) {
+	keys := make([]string, 0, len(v.series))
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fn(labelString(v.labels, v.values[k]), v.series[k])
+	}
+}
+
+func (v *vec[T]) header(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", v.name, v.help, v.name, v.kind)
+}
+
+// Counter is a monotonically increasing value per label combination.
+type Counter struct{ v *vec[float64] }
+
+// NewCounter registers a counter.
+func NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{newVec[float64](name, help, "counter", labels)}
+	if len(labels) == 0 {
+		c.Add(0) // expose the single series from the start
+	}
+	register(name, c)
+	return c
+}
+
+// Add increases the series for the label values by delta (>= 0).
+func (c *Counter) Add(delta float64, values ...string) {
+	c.v.mu.Lock()
+	*c.v.get(values, func() *float64 { return new(float64) }) += delta
+	c.v.mu.Unlock()
+}
+
+// Inc adds one.
+func (c *Counter) Inc(values ...string) { c.Add(1, values...) }
+
+func (c *Counter) write(w io.Writer) {
+	c.v.mu.Lock()
+	defer c.v.mu.Unlock()
+	c.v.header(w)
+	c.v.each(func(l string, s *float64) {
+		fmt.Fprintf(w, "%s%s %s\n", c.v.name, l, num(*s))
+	})
+}
+
+// Gauge is a value that goes up and down per label combination.
+type Gauge struct{ v *vec[float64] }
+
+// NewGauge registers a gauge.
+func NewGauge(name, help string, labels ...string) *Gauge {
+	g := &Gauge{newVec[float64](name, help, "gauge", labels)}
+	if len(labels) == 0 {
+		g.Add(0) // expose the single series from the start
+	}
+	register(name, g)
+	return g
+}
+
+// Add changes the series for the label values by delta.
+func (g *Gauge) Add(delta float64, values ...string) {
+	g.v.mu.Lock()
+	*g.v.get(values, func() *float64 { return new(float64) }) += delta
+	g.v.mu.Unlock()
+}
+
+// Set replaces the series value.
+func (g *Gauge) Set(val float64, values ...string) {
+	g.v.mu.Lock()
+	*g.v.get(values, func() *float64 { return new(float64) }) = val
+	g.v.mu.Unlock()
+}
+
+func (g *Gauge) write(w io.Writer) {
+	g.v.mu.Lock()
+	defer g.v.mu.Unlock()
+	g.v.header(w)
+	g.v.each(func(l string, s *float64) {
+		fmt.Fprintf(w, "%s%s %s\n", g.v.name, l, num(*s))
+	})
+}
+
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+// Histogram counts observations into buckets per label combination.
+type Histogram struct {
+	v       *vec[histogram]
+	buckets []float64
+}
+
+// NewHistogram registers a histogram; nil buckets means DefBuckets.
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if buckets == nil {
+		buckets = DefBuckets
+	}
+	h := &Histogram{v: newVec[histogram](name, help, "histogram", labels), buckets: buckets}
+	register(name, h)
+	return h
+}
+
+// Observe records one value.
+func (h *Histogram) Observe(val float64, values ...string) {
+	h.v.mu.Lock()
+	defer h.v.mu.Unlock()
+	s := h.v.get(values, func() *histogram { return &histogram{counts: make([]uint64, len(h.buckets))} })
+	if i := sort.SearchFloat64s(h.buckets, val); i < len(h.buckets) {
+		s.counts[i]++
+	}
+	s.count++
+	s.sum += val
+}
+
+func (h *Histogram) write(w io.Writer) {
+	h.v.mu.Lock()
+	defer h.v.mu.Unlock()
+	h.v.header(w)
+	h.v.each(func(l string, s *histogram) {
+		var cum uint64
+		for i, b := range h.buckets {
+			cum += s.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.v.name, withLE(l, num(b)), cum)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.v.name, withLE(l, "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.v.name, l, num(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.v.name, l, s.count)
+	})
+}
+
+// Write renders every registered metric in the text exposition format.
+func Write(w io.Writer) {
+	mu.Lock()
+	ms := append([]metric(nil), metrics...)
+	mu.Unlock()
+	for _, m := range ms {
+		m.write(w)
+	}
+}
+
+// Handler serves /metrics.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		Write(w)
+	})
+}
+
+func labelString(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, n := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(n)
+		b.WriteString(`="`)
+		b.WriteString(escape(values[i]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func withLE(labels, le string) string {
+	if labels == "" {
+		return `{le="` + le + `"}`
+	}
+	return labels[:len(labels)-1] + `,le="` + le + `"}`
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escape(s string) string { return escaper.Replace(s) }
+
+func num(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
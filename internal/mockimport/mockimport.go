@@ -0,0 +1,168 @@
+// Package mockimport loads mocks/mock.json into a real Odoo database:
+// products, their BOMs and the manufacturing orders, so a fresh instance
+// looks like the plant the tools and evals are written against.
+package mockimport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	odoolib "mcp-bedrock-go/odoo"
+)
+
+// Data is the part of mock.json that is imported.
+type Data struct {
+	Products []struct {
+		Name          string  `json:"name"`
+		DefaultCode   string  `json:"default_code"`
+		ListPrice     float64 `json:"list_price"`
+		StandardPrice float64 `json:"standard_price"`
+	} `json:"products"`
+	BOM []struct {
+		ProductDefaultCode string `json:"product_default_code"`
+		Lines              []struct {
+			Product string  `json:"product"`
+			Qty     float64 `json:"qty"`
+		} `json:"lines"`
+	} `json:"bom"`
+	MRPOrders []struct {
+		ProductDefaultCode string  `json:"product_default_code"`
+		Qty                float64 `json:"qty"`
+		Deadline           string  `json:"deadline"`
+	} `json:"mrp_orders"`
+}
+
+// Load reads a mock.json file.
+func Load(path string) (*Data, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var d Data
+	if err := json.Unmarshal(b, &d); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &d, nil
+}
+
+// Record is one row of the import report.
+type Record struct {
+	Model  string `json:"model"`
+	Ref    string `json:"ref"`    // product code the record belongs to
+	ID     int    `json:"id"`     // 0 in a dry run
+	Status string `json:"status"` // created, exists, would create, failed
+}
+
+type product struct{ id, tmplID int }
+
+// Import creates the mock data in Odoo. Products whose default_code already
+// exists are reused rather than duplicated, so the import can be re-run
+// after a partial failure; BOMs and MOs are always created. With dryRun
+// nothing is written and the report says what would be.
+func Import(ctx context.Context, oc *odoolib.Client, d *Data, dryRun bool) ([]Record, error) {
+	var out []Record
+	products := map[string]product{}
+
+	for _, p := range d.Products {
+		existing, err := oc.SearchReadContext(ctx, "product.product", []string{"id", "product_tmpl_id"}, []any{[]any{"default_code", "=", p.DefaultCode}})
+		if err != nil {
+			return out, fmt.Errorf("product %s: %w", p.DefaultCode, err)
+		}
+		if len(existing) > 0 {
+			pr := toProduct(existing[0])
+			products[p.DefaultCode] = pr
+			out = append(out, Record{Model: "product.product", Ref: p.DefaultCode, ID: pr.id, Status: "exists"})
+			continue
+		}
+		if dryRun {
+			products[p.DefaultCode] = product{}
+			out = append(out, Record{Model: "product.product", Ref: p.DefaultCode, Status: "would create"})
+			continue
+		}
+		id, err := oc.CreateContext(ctx, "product.product", map[string]any{
+			"name":           p.Name,
+			"default_code":   p.DefaultCode,
+			"list_price":     p.ListPrice,
+			"standard_price": p.StandardPrice,
+		})
+		if err != nil {
+			return out, fmt.Errorf("product %s: %w", p.DefaultCode, err)
+		}
+		// Odoo creates the template with the variant; BOMs hang off the template
+		pr := product{id: id, tmplID: id}
+		if recs, err := oc.SearchReadContext(ctx, "product.product", []string{"id", "product_tmpl_id"}, []any{[]any{"id", "=", id}}); err == nil && len(recs) > 0 {
+			pr = toProduct(recs[0])
+		}
+		products[p.DefaultCode] = pr
+		out = append(out, Record{Model: "product.product", Ref: p.DefaultCode, ID: id, Status: "created"})
+	}
+
+	for _, b := range d.BOM {
+		pr, ok := products[b.ProductDefaultCode]
+		if !ok {
+			return out, fmt.Errorf("bom: unknown product %s", b.ProductDefaultCode)
+		}
+		var lines []any
+		for _, l := range b.Lines {
+			comp, ok := products[l.Product]
+			if !ok {
+				return out, fmt.Errorf("bom %s: unknown component %s", b.ProductDefaultCode, l.Product)
+			}
+			lines = append(lines, []any{0, 0, map[string]any{"product_id": comp.id, "product_qty": l.Qty}})
+		}
+		rec, err := create(ctx, oc, dryRun, "mrp.bom", b.ProductDefaultCode, map[string]any{"product_tmpl_id": pr.tmplID, "bom_line_ids": lines})
+		out = append(out, rec)
+		if err != nil {
+			return out, err
+		}
+	}
+
+	for _, mo := range d.MRPOrders {
+		pr, ok := products[mo.ProductDefaultCode]
+		if !ok {
+			return out, fmt.Errorf("mrp order: unknown product %s", mo.ProductDefaultCode)
+		}
+		rec, err := create(ctx, oc, dryRun, "mrp.production", mo.ProductDefaultCode, map[string]any{
+			"product_id":    pr.id,
+			"product_qty":   mo.Qty,
+			"date_deadline": mo.Deadline,
+		})
+		out = append(out, rec)
+		if err != nil {
+			return out, err
+		}
+	}
+	return out, nil
+}
+
+func create(ctx context.Context, oc *odoolib.Client, dryRun bool, model, ref string, vals map[string]any) (Record, error) {
+	rec := Record{Model: model, Ref: ref, Status: "would create"}
+	if dryRun {
+		return rec, nil
+	}
+	id, err := oc.CreateContext(ctx, model, vals)
+	if err != nil {
+		rec.Status = "failed"
+		return rec, fmt.Errorf("%s for %s: %w", model, ref, err)
+	}
+	rec.ID, rec.Status = id, "created"
+	return rec, nil
+}
+
+// toProduct reads the id and template id of a product.product record;
+// product_tmpl_id comes back as [id, name].
+func toProduct(rec map[string]any) product {
+	var p product
+	if f, ok := rec["id"].(float64); ok {
+		p.id = int(f)
+	}
+	p.tmplID = p.id
+	if pair, ok := rec["product_tmpl_id"].([]any); ok && len(pair) > 0 {
+		if f, ok := pair[0].(float64); ok {
+			p.tmplID = int(f)
+		}
+	}
+	return p
+}
@@ -0,0 +1,408 @@
+// Package odoofake serves a small in-memory Odoo JSON-RPC endpoint built from
+// mocks/mock.json, so tools can run offline (evals, local smoke checks).
+package odoofake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Mock mirrors the layout of mocks/mock.json.
+type Mock struct {
+	Workcenters []struct {
+		ID          int     `json:"id"`
+		Name        string  `json:"name"`
+		Capacity    int     `json:"capacity"`
+		CostPerHour float64 `json:"cost_per_hour"`
+	} `json:"workcenters"`
+	Products []struct {
+		ID          int     `json:"id"`
+		Name        string  `json:"name"`
+		DefaultCode string  `json:"default_code"`
+		Category    string  `json:"category"`
+		ListPrice   float64 `json:"list_price"`
+	} `json:"products"`
+	BOM []struct {
+		BOMID     int `json:"bom_id"`
+		ProductID int `json:"product_id"`
+		Lines     []struct {
+			Product string  `json:"product"`
+			Qty     float64 `json:"qty"`
+		} `json:"lines"`
+	} `json:"bom"`
+	Routing []struct {
+		ProductDefaultCode string `json:"product_default_code"`
+		Operations         []struct {
+			Step          string  `json:"step"`
+			WorkcenterID  int     `json:"workcenter_id"`
+			CycleTimeSecs float64 `json:"cycle_time_secs"`
+		} `json:"operations"`
+	} `json:"routing"`
+	MRPOrders []struct {
+		MOID               int     `json:"mo_id"`
+		ProductDefaultCode string  `json:"product_default_code"`
+		Qty                float64 `json:"qty"`
+		Deadline           string  `json:"deadline"`
+		Status             string  `json:"status"`
+		CurrentStep        string  `json:"current_step"`
+	} `json:"mrp_orders"`
+}
+
+// LoadMock reads a mock.json-shaped file.
+func LoadMock(path string) (map[string]any, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return raw, nil
+}
+
+// Server is a fake Odoo holding records per model.
+type Server struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	records map[string][]map[string]any
+	nextID  int
+	// Creates lists every create call, in order, for assertions.
+	Creates []Create
+}
+
+// Create records one create call.
+type Create struct {
+	Model string         `json:"model"`
+	ID    int            `json:"id"`
+	Vals  map[string]any `json:"vals"`
+}
+
+// New starts a fake Odoo seeded from raw mock data (see LoadMock).
+func New(raw map[string]any) (*Server, error) {
+	b, _ := json.Marshal(raw)
+	var m Mock
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	s := &Server{records: seed(m), nextID: 10000}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s, nil
+}
+
+// seed maps the mock layout onto Odoo models and field shapes.
+func seed(m Mock) map[string][]map[string]any {
+	rec := map[string][]map[string]any{
+		// the user authenticate returns
+		"res.users": {{"id": float64(2), "login": "admin", "name": "Administrator"}},
+	}
+	m2o := func(id int, name string) []any { return []any{float64(id), name} }
+
+	wcName := map[int]string{}
+	for _, w := range m.Workcenters {
+		wcName[w.ID] = w.Name
+		rec["mrp.workcenter"] = append(rec["mrp.workcenter"], map[string]any{
+			"id": float64(w.ID), "name": w.Name, "capacity": float64(w.Capacity), "costs_hour": w.CostPerHour,
+		})
+	}
+
+	byCode := map[string]map[string]any{}
+	for _, p := range m.Products {
+		cat := "All"
+		if p.Category != "" {
+			cat = "All / " + p.Category
+		}
+		r := map[string]any{
+			"id": float64(p.ID), "name": p.Name, "default_code": p.DefaultCode,
+			"product_tmpl_id": m2o(p.ID, p.Name), "categ_id": m2o(1, cat), "list_price": p.ListPrice,
+		}
+		byCode[p.DefaultCode] = r
+		rec["product.product"] = append(rec["product.product"], r)
+		rec["product.template"] = append(rec["product.template"], map[string]any{
+			"id": float64(p.ID), "name": p.Name, "default_code": p.DefaultCode,
+		})
+	}
+
+	lineID := 1
+	for _, b := range m.BOM {
+		var lines []any
+		for _, l := range b.Lines {
+			comp := byCode[l.Product]
+			if comp == nil {
+				continue
+			}
+			rec["mrp.bom.line"] = append(rec["mrp.bom.line"], map[string]any{
+				"id": float64(lineID), "bom_id": m2o(b.BOMID, ""), "product_id": m2o(int(comp["id"].(float64)), comp["name"].(string)), "product_qty": l.Qty,
+			})
+			lines = append(lines, float64(lineID))
+			lineID++
+		}
+		rec["mrp.bom"] = append(rec["mrp.bom"], map[string]any{
+			"id": float64(b.BOMID), "product_tmpl_id": m2o(b.ProductID, ""), "bom_line_ids": lines,
+		})
+	}
+
+	woID := 1
+	for _, o := range m.MRPOrders {
+		p := byCode[o.ProductDefaultCode]
+		if p == nil {
+			continue
+		}
+		var wos []any
+		for _, r := range m.Routing {
+			if r.ProductDefaultCode != o.ProductDefaultCode {
+				continue
+			}
+			for _, op := range r.Operations {
+				state := "pending"
+				if op.Step == o.CurrentStep && o.Status == "Running" {
+					state = "progress"
+				}
+				rec["mrp.workorder"] = append(rec["mrp.workorder"], map[string]any{
+					"id": float64(woID), "name": op.Step, "workcenter_id": m2o(op.WorkcenterID, wcName[op.WorkcenterID]),
+					"production_id": m2o(o.MOID, ""), "state": state, "duration": op.CycleTimeSecs / 60,
+					"date_planned_start": o.Deadline, "date_planned_finished": o.Deadline,
+				})
+				wos = append(wos, float64(woID))
+				woID++
+			}
+		}
+		rec["mrp.production"] = append(rec["mrp.production"], map[string]any{
+			"id": float64(o.MOID), "name": fmt.Sprintf("WH/MO/%05d", o.MOID),
+			"product_id": m2o(int(p["id"].(float64)), p["name"].(string)), "product_qty": o.Qty,
+			"date_deadline": o.Deadline, "state": moState(o.Status), "workorder_ids": wos,
+		})
+	}
+	return rec
+}
+
+func moState(status string) string {
+	switch strings.ToLower(status) {
+	case "running":
+		return "progress"
+	case "waiting":
+		return "confirmed"
+	case "done":
+		return "done"
+	}
+	return "draft"
+}
+
+type rpcRequest struct {
+	Params struct {
+		Service string `json:"service"`
+		Method  string `json:"method"`
+		Args    []any  `json:"args"`
+	} `json:"params"`
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	var req rpcRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	result, err := s.dispatch(req)
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "error": map[string]any{"message": err.Error()}})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "result": result})
+}
+
+func (s *Server) dispatch(req rpcRequest) (any, error) {
+	p := req.Params
+	switch {
+	case p.Service == "common" && p.Method == "authenticate":
+		return 2, nil
+	case p.Service == "common" && p.Method == "version":
+		return map[string]any{"server_version": "fake"}, nil
+	case p.Service != "object" || p.Method != "execute_kw" || len(p.Args) < 5:
+		return nil, fmt.Errorf("unsupported call %s.%s", p.Service, p.Method)
+	}
+
+	model, _ := p.Args[3].(string)
+	method, _ := p.Args[4].(string)
+	var args []any
+	if len(p.Args) > 5 {
+		args, _ = p.Args[5].([]any)
+	}
+	var kwargs map[string]any
+	if len(p.Args) > 6 {
+		kwargs, _ = p.Args[6].(map[string]any)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch method {
+	case "search_read":
+		var domain []any
+		if len(args) > 0 {
+			domain, _ = args[0].([]any)
+		}
+		var fields []any
+		if kwargs != nil {
+			fields, _ = kwargs["fields"].([]any)
+		}
+		return s.searchRead(model, domain, fields), nil
+	case "read":
+		var ids []any
+		if len(args) > 0 {
+			ids, _ = args[0].([]any)
+		}
+		return s.searchRead(model, []any{[]any{"id", "in", ids}}, nil), nil
+	case "create":
+		if len(args) == 0 {
+			return nil, fmt.Errorf("create needs vals")
+		}
+		vals, _ := args[0].(map[string]any)
+		s.nextID++
+		id := s.nextID
+		rec := map[string]any{"id": float64(id)}
+		for k, v := range vals {
+			rec[k] = v
+		}
+		s.records[model] = append(s.records[model], rec)
+		s.Creates = append(s.Creates, Create{Model: model, ID: id, Vals: vals})
+		return id, nil
+	}
+	return nil, fmt.Errorf("unsupported method %s on %s", method, model)
+}
+
+func (s *Server) searchRead(model string, domain, fields []any) []map[string]any {
+	out := []map[string]any{}
+	for _, rec := range s.records[model] {
+		if !matches(rec, domain) {
+			continue
+		}
+		if len(fields) == 0 {
+			out = append(out, rec)
+			continue
+		}
+		row := map[string]any{"id": rec["id"]}
+		for _, f := range fields {
+			if name, ok := f.(string); ok {
+				if v, ok := rec[name]; ok {
+					row[name] = v
+				} else {
+					row[name] = false
+				}
+			}
+		}
+		out = append(out, row)
+	}
+	return out
+}
+
+// matches evaluates an implicit-AND domain of [field, op, value] triples.
+// Unknown operators and malformed items (such as the empty [] some tools
+// send) are ignored.
+func matches(rec map[string]any, domain []any) bool {
+	for _, item := range domain {
+		t, ok := item.([]any)
+		if !ok || len(t) != 3 {
+			continue
+		}
+		field, _ := t[0].(string)
+		op, _ := t[1].(string)
+		if !compare(scalar(rec[field]), op, t[2]) {
+			return false
+		}
+	}
+	return true
+}
+
+// scalar collapses many2one [id, name] pairs to the id.
+func scalar(v any) any {
+	if a, ok := v.([]any); ok && len(a) == 2 {
+		if _, ok := a[1].(string); ok {
+			return a[0]
+		}
+	}
+	return v
+}
+
+func compare(have any, op string, want any) bool {
+	switch op {
+	case "=":
+		return equal(have, want)
+	case "!=":
+		return !equal(have, want)
+	case "in", "not in":
+		list, _ := want.([]any)
+		found := false
+		for _, w := range list {
+			if equal(have, w) {
+				found = true
+				break
+			}
+		}
+		return found == (op == "in")
+	case "ilike", "like":
+		return strings.Contains(strings.ToLower(fmt.Sprint(have)), strings.ToLower(fmt.Sprint(want)))
+	case ">=", "<=", ">", "<":
+		a, b := fmt.Sprint(have), fmt.Sprint(want)
+		if af, ok := have.(float64); ok {
+			if bf, ok := num(want); ok {
+				return cmpFloat(af, bf, op)
+			}
+		}
+		// Dates compare as strings; match on the date prefix like Odoo does for day filters.
+		if len(b) == len("2006-01-02") && len(a) > len(b) {
+			a = a[:len(b)]
+		}
+		switch op {
+		case ">=":
+			return a >= b
+		case "<=":
+			return a <= b
+		case ">":
+			return a > b
+		}
+		return a < b
+	}
+	return true
+}
+
+func cmpFloat(a, b float64, op string) bool {
+	switch op {
+	case ">=":
+		return a >= b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	}
+	return a < b
+}
+
+func equal(a, b any) bool {
+	if af, ok := num(a); ok {
+		if bf, ok := num(b); ok {
+			return af == bf
+		}
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+func num(v any) (float64, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case int:
+		return float64(x), true
+	case string:
+		if f, err := strconv.ParseFloat(x, 64); err == nil {
+			return f, true
+		}
+	}
+	return 0, false
+}
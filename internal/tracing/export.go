@@ -0,0 +1,236 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Exporter ships finished spans.
+type Exporter interface {
+	Export(ctx context.Context, body []byte) error
+}
+
+// Options configures Init.
+type Options struct {
+	ServiceName    string
+	ServiceVersion string
+	Exporter       Exporter
+	BatchSize      int           // default 256
+	FlushInterval  time.Duration // default 5s
+}
+
+type processor struct {
+	opts  Options
+	mu    sync.Mutex
+	queue []*Span
+	kick  chan struct{}
+	done  chan struct{}
+}
+
+var (
+	procMu sync.RWMutex
+	proc   *processor
+)
+
+func active() *processor {
+	procMu.RLock()
+	defer procMu.RUnlock()
+	return proc
+}
+
+// Init turns tracing on. The returned function flushes queued spans and
+// turns tracing off again; call it on shutdown.
+func Init(opts Options) (shutdown func(context.Context)) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 256
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = 5 * time.Second
+	}
+	p := &processor{opts: opts, kick: make(chan struct{}, 1), done: make(chan struct{})}
+	procMu.Lock()
+	proc = p
+	procMu.Unlock()
+
+	stop := make(chan struct{})
+	go p.loop(stop)
+	return func(ctx context.Context) {
+		procMu.Lock()
+		proc = nil
+		procMu.Unlock()
+		close(stop)
+		select {
+		case <-p.done:
+		case <-ctx.Done():
+		}
+		p.flush(ctx)
+	}
+}
+
+func (p *processor) enqueue(s *Span) {
+	p.mu.Lock()
+	p.queue = append(p.queue, s)
+	full := len(p.queue) >= p.opts.BatchSize
+	p.mu.Unlock()
+	if full {
+		select {
+		case p.kick <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (p *processor) loop(stop chan struct{}) {
+	defer close(p.done)
+	t := time.NewTicker(p.opts.FlushInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-t.C:
+		case <-p.kick:
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		p.flush(ctx)
+		cancel()
+	}
+}
+
+func (p *processor) flush(ctx context.Context) {
+	p.mu.Lock()
+	spans := p.queue
+	p.queue = nil
+	p.mu.Unlock()
+	if len(spans) == 0 {
+		return
+	}
+	body, err := json.Marshal(p.request(spans))
+	if err != nil {
+		logger.Errorf("tracing: encode %d spans: %v", len(spans), err)
+		return
+	}
+	if err := p.opts.Exporter.Export(ctx, body); err != nil {
+		logger.Errorf("tracing: export %d spans: %v", len(spans), err)
+	}
+}
+
+// request builds an OTLP ExportTraceServiceRequest in its JSON mapping.
+func (p *processor) request(spans []*Span) map[string]any {
+	out := make([]map[string]any, 0, len(spans))
+	for _, s := range spans {
+		s.mu.Lock()
+		span := map[string]any{
+			"traceId":           hex.EncodeToString(s.TraceID[:]),
+			"spanId":            hex.EncodeToString(s.SpanID[:]),
+			"name":              s.Name,
+			"kind":              s.Kind,
+			"startTimeUnixNano": strconv.FormatInt(s.Start.UnixNano(), 10),
+			"endTimeUnixNano":   strconv.FormatInt(s.End.UnixNano(), 10),
+			"attributes":        attributes(s.attrs),
+			"status":            map[string]any{},
+		}
+		if s.ParentID != ([8]byte{}) {
+			span["parentSpanId"] = hex.EncodeToString(s.ParentID[:])
+		}
+		if s.errMsg != "" {
+			span["status"] = map[string]any{"code": 2, "message": s.errMsg}
+		}
+		s.mu.Unlock()
+		out = append(out, span)
+	}
+	resource := attributes(map[string]any{"service.name": p.opts.ServiceName, "service.version": p.opts.ServiceVersion})
+	return map[string]any{"resourceSpans": []any{map[string]any{
+		"resource":   map[string]any{"attributes": resource},
+		"scopeSpans": []any{map[string]any{"scope": map[string]any{"name": "mcp-bedrock-go"}, "spans": out}},
+	}}}
+}
+
+func attributes(m map[string]any) []any {
+	out := make([]any, 0, len(m))
+	for k, v := range m {
+		var val map[string]any
+		switch x := v.(type) {
+		case string:
+			val = map[string]any{"stringValue": x}
+		case bool:
+			val = map[string]any{"boolValue": x}
+		case int:
+			val = map[string]any{"intValue": strconv.Itoa(x)}
+		case int64:
+			val = map[string]any{"intValue": strconv.FormatInt(x, 10)}
+		case float64:
+			val = map[string]any{"doubleValue": x}
+		default:
+			val = map[string]any{"stringValue": fmt.Sprint(x)}
+		}
+		out = append(out, map[string]any{"key": k, "value": val})
+	}
+	return out
+}
+
+// OTLPHTTP posts OTLP/JSON to a collector, e.g. http://localhost:4318/v1/traces.
+type OTLPHTTP struct {
+	Endpoint string
+	Headers  map[string]string
+	HTTP     *http.Client
+}
+
+// Export implements Exporter.
+func (e *OTLPHTTP) Export(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", e.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.Headers {
+		req.Header.Set(k, v)
+	}
+	client := e.HTTP
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("collector returned %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// File appends one OTLP/JSON request per line, the layout the collector's
+// file exporter writes and its otlpjsonfile receiver reads.
+type File struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+// NewFile opens path for appending.
+func NewFile(path string) (*File, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &File{f: f}, nil
+}
+
+// Export implements Exporter.
+func (e *File) Export(ctx context.Context, body []byte) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err := e.f.Write(append(body, '\n'))
+	return err
+}
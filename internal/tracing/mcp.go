@@ -0,0 +1,46 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ToolMiddleware wraps every tool call in a server span. A traceparent in
+// the request's _meta (or on the HTTP request) makes it part of the
+// caller's trace.
+func ToolMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if m := req.Params.Meta; m != nil {
+			if tp, ok := m.AdditionalFields["traceparent"].(string); ok {
+				ctx = Extract(ctx, tp)
+			}
+		}
+		ctx, span := Start(ctx, "tools/call "+req.Params.Name, KindServer)
+		defer span.Finish()
+		span.SetAttr("mcp.method.name", "tools/call")
+		span.SetAttr("mcp.tool.name", req.Params.Name)
+		if sess := server.ClientSessionFromContext(ctx); sess != nil {
+			span.SetAttr("mcp.session.id", sess.SessionID())
+		}
+
+		res, err := next(ctx, req)
+		switch {
+		case err != nil:
+			span.RecordError(err)
+		case res != nil && res.IsError:
+			span.SetAttr("mcp.tool.error", true)
+			if len(res.Content) > 0 {
+				if t, ok := res.Content[0].(mcp.TextContent); ok {
+					span.RecordError(toolError(t.Text))
+				}
+			}
+		}
+		return res, err
+	}
+}
+
+type toolError string
+
+func (e toolError) Error() string { return string(e) }
@@ -0,0 +1,187 @@
+// Package tracing records OpenTelemetry-compatible spans for tool calls and
+// the Odoo and Bedrock requests they make, and exports them as OTLP/JSON,
+// either to a collector over HTTP or to a local file for offline use.
+//
+// Tracing is off until Init is called with an exporter; until then Start
+// returns nil spans and every Span method is a no-op.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"mcp-bedrock-go/internal/logging"
+)
+
+var logger = logging.For("tracing")
+
+// Span kinds, as in OTLP.
+const (
+	KindInternal = 1
+	KindServer   = 2
+	KindClient   = 3
+)
+
+// Span is one timed operation in a trace.
+type Span struct {
+	TraceID  [16]byte
+	SpanID   [8]byte
+	ParentID [8]byte
+	Name     string
+	Kind     int
+	Start    time.Time
+	End      time.Time
+
+	mu     sync.Mutex
+	attrs  map[string]any
+	errMsg string
+	ended  bool
+}
+
+// SetAttr sets an attribute; values should be strings, ints, floats or bools.
+func (s *Span) SetAttr(key string, value any) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.attrs[key] = value
+	s.mu.Unlock()
+}
+
+// RecordError marks the span failed. A nil err is ignored.
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	s.errMsg = err.Error()
+	s.mu.Unlock()
+}
+
+// Finish ends the span and queues it for export. Later calls do nothing.
+func (s *Span) Finish() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.End = time.Now()
+	s.mu.Unlock()
+	if p := active(); p != nil {
+		p.enqueue(s)
+	}
+}
+
+// TraceIDString is the hex trace ID, or "" for a nil span.
+func (s *Span) TraceIDString() string {
+	if s == nil {
+		return ""
+	}
+	return hex.EncodeToString(s.TraceID[:])
+}
+
+// Traceparent renders the W3C traceparent header for this span.
+func (s *Span) Traceparent() string {
+	if s == nil {
+		return ""
+	}
+	return "00-" + hex.EncodeToString(s.TraceID[:]) + "-" + hex.EncodeToString(s.SpanID[:]) + "-01"
+}
+
+type spanKey struct{}
+
+// remote is a parent received from another process.
+type remote struct {
+	traceID [16]byte
+	spanID  [8]byte
+}
+
+type remoteKey struct{}
+
+// FromContext returns the current span, or nil.
+func FromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+// TraceID returns the hex trace ID of the current span, or "".
+func TraceID(ctx context.Context) string {
+	return FromContext(ctx).TraceIDString()
+}
+
+// Start begins a span named name as a child of the span on ctx (or of a
+// remote parent set by Extract). It returns nil when tracing is off.
+func Start(ctx context.Context, name string, kind int) (context.Context, *Span) {
+	if active() == nil {
+		return ctx, nil
+	}
+	s := &Span{Name: name, Kind: kind, Start: time.Now(), attrs: map[string]any{}}
+	switch parent := FromContext(ctx); {
+	case parent != nil:
+		s.TraceID, s.ParentID = parent.TraceID, parent.SpanID
+	default:
+		if r, ok := ctx.Value(remoteKey{}).(remote); ok {
+			s.TraceID, s.ParentID = r.traceID, r.spanID
+		} else {
+			rand.Read(s.TraceID[:])
+		}
+	}
+	rand.Read(s.SpanID[:])
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// Extract returns ctx with the parent named by a W3C traceparent value, so
+// spans started from it join the caller's trace. Malformed values are ignored.
+func Extract(ctx context.Context, traceparent string) context.Context {
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) != 4 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return ctx
+	}
+	var r remote
+	if _, err := hex.Decode(r.traceID[:], []byte(parts[1])); err != nil {
+		return ctx
+	}
+	if _, err := hex.Decode(r.spanID[:], []byte(parts[2])); err != nil {
+		return ctx
+	}
+	if r.traceID == ([16]byte{}) || r.spanID == ([8]byte{}) {
+		return ctx
+	}
+	return context.WithValue(ctx, remoteKey{}, r)
+}
+
+// Middleware picks up the traceparent header of incoming HTTP requests.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tp := r.Header.Get("traceparent"); tp != "" {
+			r = r.WithContext(Extract(r.Context(), tp))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Inject sets the traceparent header of an outgoing request from ctx.
+func Inject(ctx context.Context, h http.Header) {
+	if s := FromContext(ctx); s != nil {
+		h.Set("traceparent", s.Traceparent())
+	}
+}
+
+func init() {
+	logging.AddContextAttrs(func(ctx context.Context) []slog.Attr {
+		if s := FromContext(ctx); s != nil {
+			return []slog.Attr{slog.String("trace_id", s.TraceIDString()), slog.String("span_id", hex.EncodeToString(s.SpanID[:]))}
+		}
+		return nil
+	})
+}
@@ -3,146 +3,511 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
-	"github.com/joho/godotenv"
-
-	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	bedrockruntime "github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 
+	"mcp-bedrock-go/approval"
+	"mcp-bedrock-go/audit"
+	"mcp-bedrock-go/auth"
 	bedrocklib "mcp-bedrock-go/bedrock"
+	"mcp-bedrock-go/config"
+	"mcp-bedrock-go/conversation"
+	"mcp-bedrock-go/delegation"
+	"mcp-bedrock-go/format"
+	"mcp-bedrock-go/health"
+	"mcp-bedrock-go/idempotency"
+	"mcp-bedrock-go/internal/logging"
+	"mcp-bedrock-go/internal/metrics"
+	"mcp-bedrock-go/internal/tracing"
 	odoolib "mcp-bedrock-go/odoo"
+	"mcp-bedrock-go/policy"
+	"mcp-bedrock-go/productsearch"
+	"mcp-bedrock-go/prompts"
+	"mcp-bedrock-go/resources"
+	"mcp-bedrock-go/rest"
+	"mcp-bedrock-go/retrieval"
 	tools "mcp-bedrock-go/tools"
+	"mcp-bedrock-go/transport"
 )
 
-func main() {
-	_ = godotenv.Load()
+var logger = logging.For("app")
 
-	// Init Odoo
-	odoo := odoolib.New(
-		os.Getenv("ODOO_URL"),
-		os.Getenv("ODOO_DB"),
-		os.Getenv("ODOO_USERNAME"),
-		os.Getenv("ODOO_API_KEY"),
-	)
-	if err := odoo.Login(); err != nil {
-		log.Fatalf("Odoo login failed: %v", err)
+// serve runs the MCP server on the configured transports until interrupted.
+func serve(cfg *config.Config) {
+	// Spans for tool calls and the Odoo/Bedrock requests they make
+	stopTracing, err := startTracing(cfg)
+	if err != nil {
+		log.Fatalf("Tracing setup: %v", err)
 	}
 
-	// Init AWS Bedrock
-	cfg, _ := config.LoadDefaultConfig(context.Background())
-	brInner := bedrockruntime.NewFromConfig(cfg)
-	br := bedrocklib.New(brInner)
+	b, err := newBackends(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	odoo, llm := b.odoo, b.llm
+	s, err := newServer(cfg, b, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	users, subs := s.users, s.subs
+
+	// Dependency probes behind /healthz and /readyz
+	checks := health.New(cfg.Health.Timeout.Duration, cfg.Health.CacheTTL.Duration)
+	serving := health.NewTransports(cfg.Server.Transports)
+	checks.Add("odoo", true, health.Odoo(odoo))
+	checks.Add("bedrock_credentials", true, health.AWSCredentials(b.awsCfg))
+	checks.Add("bedrock_models", false, health.Models(llm))
+	checks.Add("transports", true, serving.Probe())
+
+	// Our own HTTP API lives next to the MCP endpoints
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", checks.Healthz)
+	mux.HandleFunc("/readyz", checks.Readyz)
+	if cfg.Features.Metrics {
+		mux.Handle("/metrics", metrics.Handler())
+	}
+	// The same tools over plain HTTP for dashboards and scripts
+	if cfg.Features.REST {
+		rest.Mount(mux, s.MCPServer, rest.Options{Title: cfg.Server.Name, Version: cfg.Server.Version, Auth: cfg.Auth.Enabled})
+	}
+	mux.HandleFunc("/api/status", func(w http.ResponseWriter, r *http.Request) {
+		rep := checks.Report()
+		json.NewEncoder(w).Encode(map[string]any{
+			"status":     rep.Status,
+			"server":     cfg.Server.Name,
+			"transports": cfg.Server.Transports,
+			"checks":     rep.Checks,
+			"models":     llm.Health(),
+		})
+	})
+
+	topts := transport.Options{
+		Transports: cfg.Server.Transports,
+		Listen:     cfg.Server.Listen,
+		Mux:        mux,
+		Shutdown:   cfg.Timeouts.Shutdown.Duration,
+		OnState:    serving.OnState,
+		StdioContext: func(ctx context.Context) context.Context {
+			return auth.WithPrincipal(ctx, &auth.Principal{Subject: cfg.Auth.Stdio.Subject, Roles: cfg.Auth.Stdio.Roles, Method: "stdio"})
+		},
+	}
+	// HTTP middleware, innermost first: delegated Odoo logins,
+	// authentication, trace context, request IDs
+	var authn []auth.Authenticator
+	exempt := cfg.Auth.Exempt
+	if cfg.Auth.Enabled {
+		if authn, err = authenticators(cfg.Auth); err != nil {
+			log.Fatalf("Auth setup: %v", err)
+		}
+		if cfg.Health.Public {
+			exempt = append(exempt, "/healthz", "/readyz")
+		}
+	} else if topts.HTTPEnabled() {
+		logger.Warnf("authentication is disabled; anyone who can reach %s can call every tool", cfg.Server.Listen)
+	}
+	topts.Middleware = func(h http.Handler) http.Handler {
+		if users != nil {
+			h = users.HTTPMiddleware(h)
+		}
+		if cfg.Auth.Enabled {
+			h = auth.Middleware(h, exempt, authn...)
+		}
+		if cfg.Tracing.Exporter != "" {
+			h = tracing.Middleware(h)
+		}
+		return logging.Middleware(h)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	modelID := "us.meta.llama3-1-70b-instruct-v1:0"
+	go checks.Run(ctx, cfg.Health.Interval.Duration)
 
-	// MCP Server
-	s := server.NewMCPServer(
-		"bedrock-mcp",
-		"1.0.0",
-		server.WithToolCapabilities(true),
+	if subs != nil {
+		topts.Subscriptions = subs
+		go subs.Run(ctx, s.MCPServer, cfg.Resources.PollInterval.Duration)
+	}
+
+	err = transport.Serve(ctx, s.MCPServer, topts)
+	flushCtx, cancel := context.WithTimeout(context.Background(), cfg.Timeouts.Shutdown.Duration)
+	stopTracing(flushCtx)
+	cancel()
+	if err != nil {
+		log.Fatalf("MCP server error: %v", err)
+	}
+}
+
+// cliCaller configures a server for in-process CLI calls.
+type cliCaller struct {
+	principal *auth.Principal // every call runs as this caller
+	yes       bool            // confirms writes the approval rules gate
+}
+
+// mcpServer is an assembled MCP server and the parts serve runs beside it.
+type mcpServer struct {
+	*server.MCPServer
+	users *delegation.Resolver     // nil when every call uses the service account
+	subs  *resources.Subscriptions // nil without resources
+}
+
+// newServer assembles the MCP server serve and the in-process CLI share: the
+// tool middleware chain, resources, prompts and the enabled tools. cli is
+// nil for serve. A one-shot CLI process cannot wait in the approvals queue,
+// so there writes the approval rules gate need cli.yes instead, and the
+// approval tools are left out.
+func newServer(cfg *config.Config, b *backends, cli *cliCaller) (*mcpServer, error) {
+	// Tools declare their schema and handler in tools/; check they agree
+	if err := tools.Check(); err != nil {
+		return nil, err
+	}
+	if err := cfg.ValidateTools(tools.Names()); err != nil {
+		return nil, err
+	}
+
+	// MCP Server; sessions remember the principal that opened them
+	hooks := &server.Hooks{}
+	auth.Hooks(hooks)
+	opts := []server.ServerOption{server.WithToolCapabilities(true)}
+	if cli != nil {
+		// No transport authenticates the CLI: its calls run as the stdio principal
+		opts = append(opts, server.WithToolHandlerMiddleware(func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+			return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return next(auth.WithPrincipal(ctx, cli.principal), req)
+			}
+		}))
+	}
+	opts = append(opts, server.WithToolHandlerMiddleware(logging.ToolMiddleware))
+	if cfg.Tracing.Exporter != "" {
+		opts = append(opts, server.WithToolHandlerMiddleware(tracing.ToolMiddleware))
+	}
+	if cfg.Features.Metrics {
+		// Outside recovery and everything that refuses a call, so recovered
+		// panics and denials are counted too
+		metrics.Hooks(hooks)
+		opts = append(opts, server.WithToolHandlerMiddleware(metrics.ToolMiddleware))
+	}
+	opts = append(opts,
 		server.WithRecovery(),
+		server.WithHooks(hooks),
+		server.WithToolHandlerMiddleware(auth.ToolMiddleware),
 	)
-
-	// Register Tools
-	s.AddTool(
-		mcp.NewTool("list_all_orders", mcp.WithDescription("List all manufacturing orders")),
-		tools.ListAllOrders(odoo),
+	if b.audit != nil {
+		// Every call is recorded with its caller, including those validation,
+		// policy or approval turn away
+		opts = append(opts, server.WithToolHandlerMiddleware(b.audit.Middleware))
+	}
+	opts = append(opts,
+		// Arguments are checked and coerced before policy limits and
+		// approval rules look at them
+		server.WithToolHandlerMiddleware(tools.Validate),
+		// Results leave in the caller's output_format, including replays
+		// and queued approvals produced further in
+		server.WithToolHandlerMiddleware(format.Middleware),
 	)
-
-	s.AddTool(
-		mcp.NewTool("list_active_products", mcp.WithDescription("List active manufacturing orders")),
-		tools.ListActiveProducts(odoo),
+	// Odoo records as browsable resources, with change subscriptions
+	var (
+		res  *resources.Registry
+		subs *resources.Subscriptions
 	)
+	if cfg.Features.Resources {
+		res = resources.New(b.odoo)
+		subs = resources.NewSubscriptions(res)
+		subs.Hooks(hooks)
+		opts = append(opts, server.WithResourceCapabilities(true, false), server.WithResourceRecovery())
+	}
+	// Role-based authorization hides and guards tools (and resources) per caller
+	var pol *policy.Engine
+	if cfg.Authz.Enabled {
+		var err error
+		pol, err = cfg.Policy()
+		if err != nil {
+			return nil, fmt.Errorf("authorization policy: %w", err)
+		}
+		opts = append(opts, server.WithToolFilter(pol.Filter), server.WithToolHandlerMiddleware(pol.Middleware))
+		if res != nil {
+			opts = append(opts, server.WithResourceHandlerMiddleware(pol.ResourceMiddleware(res.Models)))
+			subs.Allow = pol.ResourceCheck(res.Models)
+		}
+	}
+	// Odoo calls run as the caller's own Odoo user, so Odoo's access rules
+	// and chatter see the person rather than the service account. After the
+	// policy check, so refused calls never log in.
+	users := newDelegation(cfg)
+	if users != nil {
+		users.Hooks(hooks)
+		opts = append(opts, server.WithToolHandlerMiddleware(users.Middleware))
+		if res != nil {
+			opts = append(opts, server.WithResourceHandlerMiddleware(users.ResourceMiddleware))
+		}
+	}
+	// Retried writes get the first result back instead of a duplicate record.
+	// Inside validation so keys cover the coerced arguments, and outside
+	// approvals so a retried gated call is not queued twice.
+	var idem *idempotency.Store
+	if cfg.Idempotency.Enabled {
+		var err error
+		idem, err = openIdempotency(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("idempotency store: %w", err)
+		}
+		go idem.Run(context.Background(), time.Minute)
+		opts = append(opts, server.WithToolHandlerMiddleware(idem.Middleware))
+	}
+	// Costly writes wait for a person: elicitation, else the approvals queue.
+	// Inside the policy check, so only permitted calls are queued.
+	var approvals *approval.Queue
+	switch {
+	case !cfg.Approval.Enabled || b.writes.DryRun:
+	case cli != nil:
+		if !cli.yes {
+			opts = append(opts, server.WithToolHandlerMiddleware(needsYes(approval.Rules(cfg.Approval.Rules))))
+		}
+	default:
+		approvals = approval.NewQueue(cfg.Approval.Rules, cfg.Approval.TTL.Duration, cfg.Approval.AllowSelfApproval)
+		if idem != nil {
+			approvals.Decided = idem.Settle // a key's pending result follows its action
+		}
+		go approvals.Run(context.Background(), time.Minute)
+		opts = append(opts, server.WithElicitation(), server.WithToolHandlerMiddleware(approvals.Middleware))
+	}
+	if cfg.Features.Prompts {
+		opts = append(opts, server.WithPromptCapabilities(false))
+	}
+	s := server.NewMCPServer(cfg.Server.Name, cfg.Server.Version, opts...)
+	if res != nil {
+		res.Register(s)
+	}
+	// Planning prompts: rush order impact, shift briefing, late orders,
+	// shortages. They read Odoo too, so they pass the same policy check and
+	// run as the caller's Odoo user.
+	if cfg.Features.Prompts {
+		var mws []prompts.Middleware
+		if pol != nil {
+			mws = append(mws, pol.PromptMiddleware(prompts.Models))
+		}
+		if users != nil {
+			mws = append(mws, users.PromptMiddleware)
+		}
+		prompts.Register(s, b.odoo, b.docs, mws...)
+	}
 
-	s.AddTool(
-		mcp.NewTool("schedule_analysis",
-			mcp.WithDescription("Analyze scheduling impact"),
-			mcp.WithString("profile", mcp.Required())),
-		tools.ScheduleAnalysis(odoo, br, modelID),
-	)
+	// Register the enabled tools; search and approval tools also need their
+	// subsystem.
+	for _, t := range tools.Build(b.deps(approvals)) {
+		if cfg.ToolEnabled(t.Tool.Name) {
+			s.AddTool(t.Tool, t.Handler)
+		}
+	}
+	return &mcpServer{MCPServer: s, users: users, subs: subs}, nil
+}
 
-	s.AddTool(
-		mcp.NewTool("capacity_check",
-			mcp.WithDescription("Check capacity"),
-			mcp.WithString("workcenter_id", mcp.Required()),
-			mcp.WithString("date", mcp.Required())),
-		tools.CapacityCheck(odoo),
-	)
+// backends are the services tools are built from, shared by serve and the
+// in-process CLI commands.
+type backends struct {
+	odoo     *odoolib.Client
+	awsCfg   aws.Config
+	chainFor func(tool string) *bedrocklib.Chain
+	llm      *bedrocklib.Chain // default chain
+	convs    *conversation.Store
+	docs     *retrieval.Index
+	products *productsearch.Index
+	writes   tools.WriteOptions
+	audit    *audit.Log // nil when auditing is off
+}
 
-	s.AddTool(
-		mcp.NewTool("order_priority", mcp.WithDescription("Rank MOs")),
-		tools.OrderPriority(odoo),
-	)
+// newBackends connects to Odoo and Bedrock and starts the optional
+// subsystems. A failed Odoo login is only logged: the health probe retries it.
+func newBackends(cfg *config.Config) (*backends, error) {
+	// Init Odoo
+	prof := cfg.ActiveOdoo()
+	odoo := odoolib.New(prof.URL, prof.DB, prof.Username, prof.APIKey)
+	odoo.HTTP.Timeout = cfg.Timeouts.Odoo.Duration
+	if err := odoo.Login(); err != nil {
+		// Keep serving: /readyz reports the failure and the odoo probe retries the login
+		logger.Errorf("Odoo login failed (profile %s): %v", cfg.Odoo.Profile, err)
+	}
 
-	s.AddTool(
-		mcp.NewTool("order_risk",
-			mcp.WithDescription("Risk assessment"),
-			mcp.WithString("mo_id", mcp.Required())),
-		tools.OrderRisk(odoo),
-	)
+	// Init AWS Bedrock
+	awsCfg, err := awsconfig.LoadDefaultConfig(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("AWS config: %w", err)
+	}
+	brInner := bedrockruntime.NewFromConfig(awsCfg)
+	br := bedrocklib.New(brInner)
 
-	s.AddTool(
-		mcp.NewTool("material_availability",
-			mcp.WithDescription("Check BOM/stock"),
-			mcp.WithString("product_id", mcp.Required()),
-			mcp.WithString("mo_id", mcp.Required())),
-		tools.MaterialAvailability(odoo),
-	)
+	// One fallback chain per distinct model list, so tools sharing a list
+	// also share health tracking.
+	chains := map[string]*bedrocklib.Chain{}
+	chainFor := func(tool string) *bedrocklib.Chain {
+		models := cfg.ModelsFor(tool)
+		key := strings.Join(models, ",")
+		if c, ok := chains[key]; ok {
+			return c
+		}
+		c := bedrocklib.NewChain(br, models...)
+		c.Timeout = cfg.Timeouts.LLM.Duration
+		for class, p := range cfg.ModelPolicies() {
+			c.SetPolicy(class, p)
+		}
+		chains[key] = c
+		return c
+	}
+	llm := chainFor("")
 
-	s.AddTool(
-		mcp.NewTool("add_product",
-			mcp.WithDescription("Add product"),
-			mcp.WithString("name", mcp.Required()),
-			mcp.WithString("default_code"),
-			mcp.WithString("type"),
-			mcp.WithString("list_price")),
-		tools.AddProduct(odoo),
-	)
+	// Conversation memory for follow-up questions
+	var convs *conversation.Store
+	if cfg.Features.Conversations {
+		convs = conversation.NewStore(cfg.Conversation.TTL.Duration, cfg.Conversation.MaxChars, cfg.Conversation.KeepTurns,
+			conversation.ChainSummarizer(llm, bedrocklib.FormatSystemPrompt))
+		go convs.Run(context.Background(), time.Minute)
+	}
 
-	s.AddTool(
-		mcp.NewTool("list_product_meta", mcp.WithDescription("List product metadata")),
-		tools.ListProductMeta(odoo),
-	)
+	// Plant documents (SOPs, work instructions) for retrieval; an embedding
+	// model enables hybrid BM25 + embedding ranking.
+	var embedder retrieval.Embedder
+	if cfg.Models.Embedding != "" {
+		embedder = bedrocklib.NewEmbedder(br, cfg.Models.Embedding)
+	}
+	var docs *retrieval.Index
+	if cfg.Features.Retrieval {
+		docs = retrieval.NewIndex(cfg.Docs.Dir, embedder)
+		if err := docs.Load(context.Background()); err != nil {
+			logger.Warnf("Document index not loaded from %s: %v", cfg.Docs.Dir, err)
+		}
+	}
 
-	s.AddTool(
-		mcp.NewTool("create_mo",
-			mcp.WithDescription("Create manufacturing order"),
-			mcp.WithString("product_code"),
-			mcp.WithString("product_id"),
-			mcp.WithString("qty", mcp.Required()),
-			mcp.WithString("name"),
-			mcp.WithString("date_deadline")),
-		tools.CreateMO(odoo),
-	)
+	// Fuzzy product lookup for find_product and create_mo; refreshed every 5 minutes
+	var products *productsearch.Index
+	if cfg.Features.ProductSearch {
+		products = productsearch.New(odoo, embedder, 5*time.Minute)
+	}
 
-	// Run STDIO (for IDE)
-	go func() {
-		if err := server.ServeStdio(s); err != nil {
-			log.Fatalf("STDIO server error: %v", err)
+	// Server-wide dry-run overrides the per-call dry_run argument
+	writes := tools.WriteOptions{DryRun: cfg.Features.DryRun}
+	if writes.DryRun {
+		logger.Infof("Dry-run mode: write tools will not create anything in Odoo")
+	}
+
+	// Append-only, hash-chained record of every tool call
+	var auditLog *audit.Log
+	if cfg.Audit.Enabled {
+		if auditLog, err = audit.Open(cfg.Audit.File); err != nil {
+			return nil, fmt.Errorf("audit log: %w", err)
 		}
-	}()
+	}
 
-	// Create SSE HTTP Transport and mount endpoints
-	sse := server.NewSSEServer(s)
+	return &backends{
+		odoo:     odoo,
+		awsCfg:   awsCfg,
+		chainFor: chainFor,
+		llm:      llm,
+		convs:    convs,
+		docs:     docs,
+		products: products,
+		writes:   writes,
+		audit:    auditLog,
+	}, nil
+}
 
-	// Mount MCP SSE endpoints
-	http.Handle("/sse", sse.SSEHandler())
-	http.Handle("/message", sse.MessageHandler())
+// openIdempotency opens the store of idempotency keys for the write tools.
+func openIdempotency(cfg *config.Config) (*idempotency.Store, error) {
+	ic := cfg.Idempotency
+	return idempotency.Open(ic.File, ic.Window.Duration, ic.DeriveWindow.Duration, tools.Idempotent)
+}
 
-	// Optionally keep your own HTTP API
-	http.HandleFunc("/api/status", func(w http.ResponseWriter, r *http.Request) {
-		json.NewEncoder(w).Encode(map[string]any{
-			"status": "ok",
-			"server": "bedrock-mcp",
-		})
-	})
+// newDelegation builds the resolver of per-caller Odoo users, or nil when
+// every call uses the service account. Idle user clients are swept in the
+// background.
+func newDelegation(cfg *config.Config) *delegation.Resolver {
+	u := cfg.Odoo.Users
+	if !u.Enabled {
+		return nil
+	}
+	prof := cfg.ActiveOdoo()
+	pool := odoolib.NewPool(prof.URL, prof.DB, cfg.Timeouts.Odoo.Duration, u.IdleTTL.Duration)
+	go pool.Run(context.Background(), time.Minute)
+	m := make(map[string]odoolib.Credentials, len(u.Map))
+	for subject, user := range u.Map {
+		m[subject] = odoolib.Credentials{User: user.Username, Key: user.APIKey}
+	}
+	return delegation.New(pool, m, u.Delegated, u.Fallback == "deny")
+}
 
-	log.Println("MCP SSE HTTP server running on http://localhost:5982")
-	log.Fatal(http.ListenAndServe(":5982", nil))
+// deps are the tool dependencies; approvals is nil when writes are not gated.
+func (b *backends) deps(approvals *approval.Queue) tools.Deps {
+	return tools.Deps{
+		Odoo:      b.odoo,
+		LLM:       b.chainFor,
+		Convs:     b.convs,
+		Docs:      b.docs,
+		Products:  b.products,
+		Approvals: approvals,
+		Writes:    b.writes,
+		Audit:     b.audit,
+	}
+}
+
+// startTracing installs the configured span exporter. The returned function
+// flushes pending spans; it is a no-op when tracing is off.
+func startTracing(cfg *config.Config) (func(context.Context), error) {
+	var exp tracing.Exporter
+	switch cfg.Tracing.Exporter {
+	case "":
+		return func(context.Context) {}, nil
+	case "otlp":
+		exp = &tracing.OTLPHTTP{Endpoint: cfg.Tracing.Endpoint, Headers: cfg.Tracing.Headers}
+	case "file":
+		f, err := tracing.NewFile(cfg.Tracing.File)
+		if err != nil {
+			return nil, err
+		}
+		exp = f
+	}
+	logger.Infof("Tracing: exporting spans via %s", cfg.Tracing.Exporter)
+	return tracing.Init(tracing.Options{
+		ServiceName:    cfg.Server.Name,
+		ServiceVersion: cfg.Server.Version,
+		Exporter:       exp,
+	}), nil
+}
+
+// authenticators builds the configured authentication methods.
+func authenticators(c config.Auth) ([]auth.Authenticator, error) {
+	var out []auth.Authenticator
+	if len(c.APIKeys) > 0 {
+		keys := make([]auth.APIKey, len(c.APIKeys))
+		for i, k := range c.APIKeys {
+			keys[i] = auth.APIKey{Key: k.Key, Subject: k.Subject, Roles: k.Roles}
+		}
+		out = append(out, auth.NewStaticKeys(keys))
+	}
+	if c.HMAC.Secret != "" {
+		out = append(out, auth.NewHMACTokens(c.HMAC.Secret, c.HMAC.Issuer))
+	}
+	if c.JWT.JWKSFile != "" {
+		v, err := auth.NewJWTVerifier(auth.JWTOptions{
+			JWKSFile:   c.JWT.JWKSFile,
+			Issuer:     c.JWT.Issuer,
+			Audience:   c.JWT.Audience,
+			RolesClaim: c.JWT.RolesClaim,
+		})
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
 }
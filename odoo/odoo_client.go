@@ -7,23 +7,44 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"mcp-bedrock-go/internal/logging"
+	"mcp-bedrock-go/internal/metrics"
+	"mcp-bedrock-go/internal/tracing"
 )
 
-// Client is a minimal Odoo JSON-RPC client used by tools.
+var logger = logging.For("odoo")
+
+// Client is a minimal Odoo JSON-RPC client used by tools. Record calls run
+// as the client on their context when there is one (see WithClient), so the
+// shared client built at startup serves per-user sessions too.
 type Client struct {
 	URL  string
 	DB   string
 	User string
 	Key  string
-	UID  int
 	HTTP *http.Client
+
+	uid atomic.Int64 // set by Login while other goroutines make calls
+}
+
+// UID is the user ID of the last successful Login, 0 before one.
+func (c *Client) UID() int {
+	return int(c.uid.Load())
 }
 
-// New constructs a client with sensible defaults.
+// New constructs a client with sensible defaults. The API key is registered
+// with the logger so it is masked wherever it would appear.
 func New(url, db, user, key string) *Client {
+	logging.Secret(key)
+	return newClient(url, db, user, key)
+}
+
+// newClient is New without registering the key, for callers that register
+// and forget it themselves.
+func newClient(url, db, user, key string) *Client {
 	return &Client{
 		URL:  url,
 		DB:   db,
@@ -34,46 +55,87 @@ func New(url, db, user, key string) *Client {
 }
 
 // rpc posts a JSON-RPC payload and returns raw body and decoded map.
-func (c *Client) rpc(ctx context.Context, payload any) ([]byte, map[string]any, error) {
+func (c *Client) rpc(ctx context.Context, payload any) (body []byte, out map[string]any, err error) {
+	model, method := rpcTarget(payload)
+	ctx, span := tracing.Start(ctx, "odoo "+model+"."+method, tracing.KindClient)
+	span.SetAttr("rpc.system", "jsonrpc")
+	span.SetAttr("odoo.model", model)
+	span.SetAttr("odoo.method", method)
+	start := time.Now()
+	defer func() {
+		outcome := "ok"
+		if err != nil {
+			outcome = "error"
+		}
+		metrics.OdooRPCDuration.Observe(time.Since(start).Seconds(), model, method, outcome)
+		if recs, ok := out["result"].([]any); ok {
+			span.SetAttr("odoo.records", len(recs))
+		}
+		span.RecordError(err)
+		span.Finish()
+	}()
+
 	b, _ := json.Marshal(payload)
-	logging.Debugf("Odoo RPC request: %s", string(b))
+	logger.DebugContext(ctx, "odoo rpc request", "model", model, "method", method, "payload", payload)
 	req, err := http.NewRequestWithContext(ctx, "POST", c.URL, bytes.NewReader(b))
 	if err != nil {
-		logging.Errorf("Odoo new request error: %v", err)
+		logger.ErrorCtxf(ctx, "Odoo new request error: %v", err)
 		return nil, nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
+	tracing.Inject(ctx, req.Header)
 
 	resp, err := c.HTTP.Do(req)
 	if err != nil {
 		return nil, nil, err
 	}
 	defer resp.Body.Close()
-	body, _ := ioutil.ReadAll(resp.Body)
-	logging.Debugf("Odoo RPC response status=%d body=%s", resp.StatusCode, string(body))
+	body, _ = ioutil.ReadAll(resp.Body)
+	span.SetAttr("http.status_code", resp.StatusCode)
+	logger.DebugContext(ctx, "odoo rpc response", "model", model, "method", method, "status", resp.StatusCode, "body", string(body))
 
-	var out map[string]any
 	_ = json.Unmarshal(body, &out)
 
 	// If HTTP-level error
 	if resp.StatusCode >= 400 {
-		logging.Errorf("Odoo http error %d: %s", resp.StatusCode, string(body))
+		logger.ErrorCtxf(ctx, "Odoo http error %d: %s", resp.StatusCode, string(body))
 		return body, out, fmt.Errorf("http %d: %s", resp.StatusCode, string(body))
 	}
 
 	// If JSON-RPC returned an error object, surface it as Go error with details
 	if rpcErr, ok := out["error"]; ok {
 		// try to extract message/data
-		logging.Errorf("Odoo rpc error: %v", rpcErr)
+		logger.ErrorCtxf(ctx, "Odoo rpc error: %v", rpcErr)
 		return body, out, fmt.Errorf("odoo rpc error: %v", rpcErr)
 	}
 
 	return body, out, nil
 }
 
+// rpcTarget names an RPC for metrics: the model and method of execute_kw
+// calls, or the service ("common") and method otherwise.
+func rpcTarget(payload any) (model, method string) {
+	p, _ := payload.(map[string]any)
+	params, _ := p["params"].(map[string]any)
+	service, _ := params["service"].(string)
+	method, _ = params["method"].(string)
+	args, _ := params["args"].([]any)
+	if method == "execute_kw" && len(args) >= 5 {
+		model, _ = args[3].(string)
+		method, _ = args[4].(string)
+		return model, method
+	}
+	return service, method
+}
+
 // Login authenticates and stores the returned UID. On failure returns error.
 func (c *Client) Login() error {
-	ctx := context.Background()
+	return c.LoginContext(context.Background())
+}
+
+// LoginContext is Login bound to ctx. It may run while the client is in use,
+// e.g. a health probe re-logging in after the session expired.
+func (c *Client) LoginContext(ctx context.Context) error {
 	payload := map[string]any{
 		"jsonrpc": "2.0",
 		"method":  "call",
@@ -86,7 +148,7 @@ func (c *Client) Login() error {
 
 	_, out, err := c.rpc(ctx, payload)
 	if err != nil {
-		logging.Errorf("Odoo login rpc failed: %v", err)
+		logger.ErrorCtxf(ctx, "Odoo login rpc failed: %v", err)
 		return err
 	}
 
@@ -94,8 +156,8 @@ func (c *Client) Login() error {
 	if res, ok := out["result"]; ok {
 		switch v := res.(type) {
 		case float64:
-			c.UID = int(v)
-			logging.Debugf("Odoo login uid=%d", c.UID)
+			c.uid.Store(int64(v))
+			logger.DebugCtxf(ctx, "Odoo login uid=%d", int(v))
 			return nil
 		case bool:
 			if v == false {
@@ -110,7 +172,12 @@ func (c *Client) Login() error {
 
 // SearchRead performs a search_read RPC and returns the slice of records.
 func (c *Client) SearchRead(model string, fields []string, domain []any) ([]map[string]any, error) {
-	ctx := context.Background()
+	return c.SearchReadContext(context.Background(), model, fields, domain)
+}
+
+// SearchReadContext is SearchRead bound to ctx, for cancellation and tracing.
+func (c *Client) SearchReadContext(ctx context.Context, model string, fields []string, domain []any) ([]map[string]any, error) {
+	c = c.as(ctx)
 	var args []any
 	if len(domain) == 0 {
 		// Odoo may reject a domain list containing an empty item ([]). If the
@@ -127,13 +194,13 @@ func (c *Client) SearchRead(model string, fields []string, domain []any) ([]map[
 		"params": map[string]any{
 			"service": "object",
 			"method":  "execute_kw",
-			"args":    []any{c.DB, c.UID, c.Key, model, "search_read", args, params},
+			"args":    []any{c.DB, c.UID(), c.Key, model, "search_read", args, params},
 		},
 	}
 
 	body, out, err := c.rpc(ctx, payload)
 	if err != nil {
-		logging.Errorf("Odoo SearchRead error model=%s err=%v", model, err)
+		logger.ErrorCtxf(ctx, "Odoo SearchRead error model=%s err=%v", model, err)
 		return nil, err
 	}
 
@@ -160,7 +227,12 @@ func (c *Client) SearchRead(model string, fields []string, domain []any) ([]map[
 // Create creates a record in the given model with the provided values map
 // and returns the created record id (int) or error.
 func (c *Client) Create(model string, vals map[string]any) (int, error) {
-	ctx := context.Background()
+	return c.CreateContext(context.Background(), model, vals)
+}
+
+// CreateContext is Create bound to ctx, for cancellation and tracing.
+func (c *Client) CreateContext(ctx context.Context, model string, vals map[string]any) (int, error) {
+	c = c.as(ctx)
 	// build payload: execute_kw(db, uid, key, model, 'create', [vals])
 	payload := map[string]any{
 		"jsonrpc": "2.0",
@@ -168,13 +240,13 @@ func (c *Client) Create(model string, vals map[string]any) (int, error) {
 		"params": map[string]any{
 			"service": "object",
 			"method":  "execute_kw",
-			"args":    []any{c.DB, c.UID, c.Key, model, "create", []any{vals}},
+			"args":    []any{c.DB, c.UID(), c.Key, model, "create", []any{vals}},
 		},
 	}
 
 	_, out, err := c.rpc(ctx, payload)
 	if err != nil {
-		logging.Errorf("Odoo Create error model=%s err=%v vals=%v", model, err, vals)
+		logger.ErrorCtxf(ctx, "Odoo Create error model=%s err=%v vals=%v", model, err, vals)
 		return 0, err
 	}
 
@@ -193,3 +265,56 @@ func (c *Client) Create(model string, vals map[string]any) (int, error) {
 		return 0, fmt.Errorf("unexpected create result type %T value=%v", v, v)
 	}
 }
+
+// Version returns the server_version reported by the common service. It
+// needs no credentials, so it tells whether Odoo is reachable at all.
+func (c *Client) Version(ctx context.Context) (string, error) {
+	payload := map[string]any{
+		"jsonrpc": "2.0",
+		"method":  "call",
+		"params": map[string]any{
+			"service": "common",
+			"method":  "version",
+			"args":    []any{},
+		},
+	}
+	_, out, err := c.rpc(ctx, payload)
+	if err != nil {
+		return "", err
+	}
+	res, _ := out["result"].(map[string]any)
+	v, _ := res["server_version"].(string)
+	if v == "" {
+		return "", fmt.Errorf("no server_version in version response")
+	}
+	return v, nil
+}
+
+// CheckUID reads the logged-in user back from res.users, proving the UID and
+// API key are still accepted. It returns the user's login.
+func (c *Client) CheckUID(ctx context.Context) (string, error) {
+	uid := c.UID()
+	if uid == 0 {
+		return "", fmt.Errorf("not logged in")
+	}
+	payload := map[string]any{
+		"jsonrpc": "2.0",
+		"method":  "call",
+		"params": map[string]any{
+			"service": "object",
+			"method":  "execute_kw",
+			"args":    []any{c.DB, uid, c.Key, "res.users", "read", []any{[]any{uid}}, map[string]any{"fields": []string{"login"}}},
+		},
+	}
+	_, out, err := c.rpc(ctx, payload)
+	if err != nil {
+		return "", err
+	}
+	users, _ := out["result"].([]any)
+	if len(users) == 0 {
+		return "", fmt.Errorf("uid %d not found", uid)
+	}
+	u, _ := users[0].(map[string]any)
+	login, _ := u["login"].(string)
+	return login, nil
+}
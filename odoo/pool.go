@@ -0,0 +1,149 @@
+package odoo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"mcp-bedrock-go/internal/logging"
+	"mcp-bedrock-go/internal/metrics"
+)
+
+// Credentials are an Odoo login and its API key.
+type Credentials struct {
+	User string
+	Key  string
+}
+
+// Pool keeps one logged-in Client per Odoo user, so calls made on behalf of
+// different people carry their own UID and Odoo applies their access rules
+// and records them in the chatter. Clients unused for IdleTTL are dropped.
+type Pool struct {
+	URL     string
+	DB      string
+	Timeout time.Duration // per RPC
+	IdleTTL time.Duration
+
+	mu      sync.Mutex
+	clients map[string]*pooled
+}
+
+type pooled struct {
+	once     sync.Once
+	c        *Client
+	err      error
+	lastUsed time.Time
+}
+
+// NewPool creates an empty pool for one Odoo database.
+func NewPool(url, db string, timeout, idleTTL time.Duration) *Pool {
+	return &Pool{URL: url, DB: db, Timeout: timeout, IdleTTL: idleTTL, clients: map[string]*pooled{}}
+}
+
+// Get returns the client for cred, logging in on first use. A failed login
+// is not kept, so the next call tries again. The API key is masked in logs
+// while its client is pooled; a failed login forgets it again.
+func (p *Pool) Get(ctx context.Context, cred Credentials) (*Client, error) {
+	key := poolKey(cred)
+	p.mu.Lock()
+	e, ok := p.clients[key]
+	if !ok {
+		e = &pooled{}
+		p.clients[key] = e
+		metrics.OdooClients.Set(float64(len(p.clients)))
+	}
+	e.lastUsed = time.Now()
+	p.mu.Unlock()
+
+	e.once.Do(func() {
+		c := newClient(p.URL, p.DB, cred.User, cred.Key)
+		c.HTTP.Timeout = p.Timeout
+		logging.Secret(cred.Key) // the login request carries it
+		if err := c.Login(); err != nil {
+			logging.Forget(cred.Key)
+			e.err = fmt.Errorf("Odoo login as %s: %w", cred.User, err)
+			return
+		}
+		logger.InfoCtxf(ctx, "odoo: logged in as %s (uid %d)", cred.User, c.UID())
+		p.mu.Lock() // Sweep reads e.c
+		e.c = c
+		p.mu.Unlock()
+	})
+	if e.err != nil {
+		p.mu.Lock()
+		if p.clients[key] == e {
+			delete(p.clients, key)
+			metrics.OdooClients.Set(float64(len(p.clients)))
+		}
+		p.mu.Unlock()
+		return nil, e.err
+	}
+	return e.c, nil
+}
+
+// Sweep drops clients idle for longer than IdleTTL, forgetting their API
+// keys, and returns how many.
+func (p *Pool) Sweep() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := 0
+	for k, e := range p.clients {
+		if time.Since(e.lastUsed) > p.IdleTTL {
+			delete(p.clients, k)
+			if e.c != nil {
+				logging.Forget(e.c.Key)
+			}
+			n++
+		}
+	}
+	metrics.OdooClients.Set(float64(len(p.clients)))
+	return n
+}
+
+// Run sweeps the pool every interval until ctx is cancelled.
+func (p *Pool) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if n := p.Sweep(); n > 0 {
+				logger.Debugf("odoo: dropped %d idle user clients", n)
+			}
+		}
+	}
+}
+
+// poolKey identifies a login without keeping the key itself as a map key.
+func poolKey(cred Credentials) string {
+	sum := sha256.Sum256([]byte(cred.User + "\x00" + cred.Key))
+	return hex.EncodeToString(sum[:])
+}
+
+type clientKey struct{}
+
+// WithClient returns ctx carrying c. Record calls (SearchReadContext,
+// CreateContext) made with such a context run as c, whichever client they
+// are called on.
+func WithClient(ctx context.Context, c *Client) context.Context {
+	return context.WithValue(ctx, clientKey{}, c)
+}
+
+// ClientFromContext returns the client set by WithClient, or nil.
+func ClientFromContext(ctx context.Context) *Client {
+	c, _ := ctx.Value(clientKey{}).(*Client)
+	return c
+}
+
+// as returns the client carried by ctx, if any, else c.
+func (c *Client) as(ctx context.Context) *Client {
+	if u := ClientFromContext(ctx); u != nil {
+		return u
+	}
+	return c
+}
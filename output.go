@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+
+	"mcp-bedrock-go/format"
+)
+
+// printValue writes v to stdout as JSON, YAML or a table. columns orders the
+// table columns that are present; the others follow alphabetically.
+func printValue(as string, v any, columns []string) error {
+	generic, err := format.Normalize(v)
+	if err != nil {
+		return err
+	}
+
+	switch as {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(generic)
+	case "yaml":
+		enc := yaml.NewEncoder(os.Stdout)
+		enc.SetIndent(2)
+		defer enc.Close()
+		return enc.Encode(generic)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+	switch t := generic.(type) {
+	case []any:
+		rows := format.Rows(t)
+		if len(rows) == 0 {
+			fmt.Fprintln(w, "(none)")
+			return nil
+		}
+		cols := format.Columns(rows, columns)
+		fmt.Fprintln(w, strings.ToUpper(strings.Join(cols, "\t")))
+		for _, row := range rows {
+			cells := make([]string, len(cols))
+			for i, c := range cols {
+				cells[i] = format.Cell(row[c])
+			}
+			fmt.Fprintln(w, strings.Join(cells, "\t"))
+		}
+	case map[string]any:
+		// an object wrapping a single list (orders, products) prints its
+		// scalar fields, then the list as a table
+		if list, key := format.OnlyList(t); list != nil {
+			for k, v := range t {
+				if k != key {
+					fmt.Fprintf(w, "%s:\t%s\n", k, format.Cell(v))
+				}
+			}
+			w.Flush()
+			return printValue(as, list, columns)
+		}
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		fmt.Fprintln(w, "KEY\tVALUE")
+		for _, k := range keys {
+			fmt.Fprintf(w, "%s\t%s\n", k, format.Cell(t[k]))
+		}
+	default:
+		fmt.Fprintln(w, format.Cell(t))
+	}
+	return nil
+}
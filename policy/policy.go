@@ -0,0 +1,367 @@
+// Package policy authorizes tool calls and resource reads by role: which
+// tools a role may call, the argument ranges it may use and the Odoo models
+// it may read or write. The same rules filter tools/list and guard
+// tools/call and resources/read.
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mcp-bedrock-go/auth"
+	"mcp-bedrock-go/internal/logging"
+	"mcp-bedrock-go/tools"
+)
+
+var logger = logging.For("policy")
+
+// Any matches every tool or model.
+const Any = "*"
+
+// Range bounds a numeric argument. Nil ends are open.
+type Range struct {
+	Min *float64 `yaml:"min" toml:"min"`
+	Max *float64 `yaml:"max" toml:"max"`
+}
+
+// Role is a named set of permissions. Inherited roles are merged first; the
+// role's own lists extend them and its argument ranges override theirs.
+type Role struct {
+	Inherits    []string                    `yaml:"inherits" toml:"inherits"`
+	Tools       []string                    `yaml:"tools" toml:"tools"`
+	Args        map[string]map[string]Range `yaml:"args" toml:"args"` // tool → argument → range
+	ReadModels  []string                    `yaml:"read_models" toml:"read_models"`
+	WriteModels []string                    `yaml:"write_models" toml:"write_models"`
+}
+
+func ptr(f float64) *float64 { return &f }
+
+// Defaults returns the built-in viewer, planner, supervisor and admin roles.
+// Approving queued writes is a supervisor task.
+func Defaults() map[string]Role {
+	return map[string]Role{
+		"viewer": {
+			Tools: []string{
+				"list_all_orders", "list_active_products", "order_priority", "order_risk",
+				"capacity_check", "material_availability", "list_product_meta",
+				"find_product", "search_docs", "list_pending_actions",
+			},
+			ReadModels: []string{Any},
+		},
+		"planner": {
+			Inherits:    []string{"viewer"},
+			Tools:       []string{"schedule_analysis", "production_planner", "create_mo"},
+			Args:        map[string]map[string]Range{"create_mo": {"qty": {Min: ptr(1), Max: ptr(1000)}}},
+			WriteModels: []string{"mrp.production"},
+		},
+		"supervisor": {
+			Inherits:    []string{"planner"},
+			Tools:       []string{"add_product", "approve_action", "reject_action", "query_audit"},
+			Args:        map[string]map[string]Range{"create_mo": {"qty": {Min: ptr(1), Max: ptr(10000)}}},
+			WriteModels: []string{"product.product"},
+		},
+		"admin": {
+			Tools:       []string{Any},
+			ReadModels:  []string{Any},
+			WriteModels: []string{Any},
+		},
+	}
+}
+
+// Merge overlays configured roles on base; a configured role replaces the
+// built-in one of the same name.
+func Merge(base, over map[string]Role) map[string]Role {
+	out := make(map[string]Role, len(base)+len(over))
+	for n, r := range base {
+		out[n] = r
+	}
+	for n, r := range over {
+		out[n] = r
+	}
+	return out
+}
+
+// Engine evaluates the resolved roles.
+type Engine struct {
+	roles     map[string]Role // inheritance already applied
+	anonymous string
+}
+
+// New resolves inheritance and returns an engine. Callers without a
+// principal get the anonymous role; "" denies them everything.
+func New(roles map[string]Role, anonymous string) (*Engine, error) {
+	e := &Engine{roles: map[string]Role{}, anonymous: anonymous}
+	for name := range roles {
+		r, err := resolve(roles, name, nil)
+		if err != nil {
+			return nil, err
+		}
+		e.roles[name] = r
+	}
+	if anonymous != "" {
+		if _, ok := e.roles[anonymous]; !ok {
+			return nil, fmt.Errorf("anonymous role %q is not defined", anonymous)
+		}
+	}
+	return e, nil
+}
+
+func resolve(roles map[string]Role, name string, seen []string) (Role, error) {
+	for _, s := range seen {
+		if s == name {
+			return Role{}, fmt.Errorf("role inheritance cycle: %s → %s", strings.Join(seen, " → "), name)
+		}
+	}
+	r, ok := roles[name]
+	if !ok {
+		return Role{}, fmt.Errorf("role %q is not defined", name)
+	}
+	var out Role
+	for _, parent := range r.Inherits {
+		p, err := resolve(roles, parent, append(seen, name))
+		if err != nil {
+			return Role{}, err
+		}
+		out.Tools = append(out.Tools, p.Tools...)
+		out.ReadModels = append(out.ReadModels, p.ReadModels...)
+		out.WriteModels = append(out.WriteModels, p.WriteModels...)
+		out.Args = mergeArgs(out.Args, p.Args)
+	}
+	out.Tools = append(out.Tools, r.Tools...)
+	out.ReadModels = append(out.ReadModels, r.ReadModels...)
+	out.WriteModels = append(out.WriteModels, r.WriteModels...)
+	out.Args = mergeArgs(out.Args, r.Args)
+	return out, nil
+}
+
+func mergeArgs(dst, src map[string]map[string]Range) map[string]map[string]Range {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = map[string]map[string]Range{}
+	}
+	for tool, args := range src {
+		if dst[tool] == nil {
+			dst[tool] = map[string]Range{}
+		}
+		for a, rg := range args {
+			dst[tool][a] = rg
+		}
+	}
+	return dst
+}
+
+// Roles returns the role names the engine knows, sorted.
+func (e *Engine) Roles() []string {
+	names := make([]string, 0, len(e.roles))
+	for n := range e.roles {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (e *Engine) rolesOf(p *auth.Principal) []string {
+	if p == nil {
+		if e.anonymous == "" {
+			return nil
+		}
+		return []string{e.anonymous}
+	}
+	return p.Roles
+}
+
+// CanList reports whether the principal may see the tool at all.
+func (e *Engine) CanList(p *auth.Principal, tool string) bool {
+	for _, name := range e.rolesOf(p) {
+		if r, ok := e.roles[name]; ok && r.allowsTool(tool) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check decides a call. It passes when any of the principal's roles allows
+// the tool, its Odoo models and every bounded argument.
+func (e *Engine) Check(p *auth.Principal, tool string, args map[string]any) error {
+	names := e.rolesOf(p)
+	if len(names) == 0 {
+		return fmt.Errorf("no role grants access to %s", tool)
+	}
+	var reasons []string
+	for _, name := range names {
+		r, ok := e.roles[name]
+		if !ok {
+			reasons = append(reasons, fmt.Sprintf("%s: unknown role", name))
+			continue
+		}
+		err := r.check(tool, args)
+		if err == nil {
+			return nil
+		}
+		reasons = append(reasons, fmt.Sprintf("%s: %v", name, err))
+	}
+	return fmt.Errorf("%s denied (%s)", tool, strings.Join(reasons, "; "))
+}
+
+func (r Role) allowsTool(tool string) bool {
+	return match(r.Tools, tool)
+}
+
+func (r Role) check(tool string, args map[string]any) error {
+	if !r.allowsTool(tool) {
+		return fmt.Errorf("tool not allowed")
+	}
+	access := tools.OdooModels[tool]
+	for _, m := range access.Read {
+		if !match(r.ReadModels, m) && !match(r.WriteModels, m) {
+			return fmt.Errorf("may not read %s", m)
+		}
+	}
+	for _, m := range access.Write {
+		if !match(r.WriteModels, m) {
+			return fmt.Errorf("may not write %s", m)
+		}
+	}
+	for arg, rg := range r.Args[tool] {
+		v, present, err := number(args[arg])
+		if err != nil {
+			return fmt.Errorf("%s: %v", arg, err)
+		}
+		if !present {
+			continue
+		}
+		if rg.Min != nil && v < *rg.Min {
+			return fmt.Errorf("%s=%g is below the minimum %g", arg, v, *rg.Min)
+		}
+		if rg.Max != nil && v > *rg.Max {
+			return fmt.Errorf("%s=%g exceeds the maximum %g", arg, v, *rg.Max)
+		}
+	}
+	return nil
+}
+
+func match(list []string, s string) bool {
+	for _, v := range list {
+		if v == Any || v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// number reads a numeric argument sent as a JSON number or a string.
+func number(v any) (float64, bool, error) {
+	switch t := v.(type) {
+	case nil:
+		return 0, false, nil
+	case float64:
+		return t, true, nil
+	case int:
+		return float64(t), true, nil
+	case string:
+		if strings.TrimSpace(t) == "" {
+			return 0, false, nil
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(t), 64)
+		if err != nil {
+			return 0, false, fmt.Errorf("%q is not a number", t)
+		}
+		return f, true, nil
+	}
+	return 0, false, fmt.Errorf("unexpected type %T", v)
+}
+
+// Filter hides tools the caller may not use from tools/list.
+func (e *Engine) Filter(ctx context.Context, list []mcp.Tool) []mcp.Tool {
+	p := auth.FromContext(ctx)
+	out := list[:0:0]
+	for _, t := range list {
+		if e.CanList(p, t.Name) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// Middleware enforces Check on every tool call.
+func (e *Engine) Middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		p := auth.FromContext(ctx)
+		if err := e.Check(p, req.Params.Name, req.GetArguments()); err != nil {
+			who := "anonymous"
+			if p != nil {
+				who = p.Subject
+			}
+			logger.InfoCtxf(ctx, "policy: %s: %v", who, err)
+			return mcp.NewToolResultError("forbidden: " + err.Error()), nil
+		}
+		return next(ctx, req)
+	}
+}
+
+// CanRead reports whether any of the principal's roles may read every model.
+func (e *Engine) CanRead(p *auth.Principal, models ...string) bool {
+	for _, name := range e.rolesOf(p) {
+		r, ok := e.roles[name]
+		if !ok {
+			continue
+		}
+		all := true
+		for _, m := range models {
+			if !match(r.ReadModels, m) && !match(r.WriteModels, m) {
+				all = false
+				break
+			}
+		}
+		if all {
+			return true
+		}
+	}
+	return false
+}
+
+// ResourceMiddleware guards resource reads by the Odoo models behind the
+// URI; modelsOf maps a URI to those models.
+func (e *Engine) ResourceMiddleware(modelsOf func(uri string) []string) server.ResourceHandlerMiddleware {
+	check := e.ResourceCheck(modelsOf)
+	return func(next server.ResourceHandlerFunc) server.ResourceHandlerFunc {
+		return func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			if err := check(ctx, req.Params.URI); err != nil {
+				return nil, err
+			}
+			return next(ctx, req)
+		}
+	}
+}
+
+// PromptMiddleware guards prompts by the Odoo models they gather context
+// from; modelsOf maps a prompt name to those models.
+func (e *Engine) PromptMiddleware(modelsOf func(name string) []string) func(server.PromptHandlerFunc) server.PromptHandlerFunc {
+	return func(next server.PromptHandlerFunc) server.PromptHandlerFunc {
+		return func(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			if !e.CanRead(auth.FromContext(ctx), modelsOf(req.Params.Name)...) {
+				return nil, fmt.Errorf("forbidden: prompt %s", req.Params.Name)
+			}
+			return next(ctx, req)
+		}
+	}
+}
+
+// ResourceCheck is ResourceMiddleware's check on its own, for requests that
+// do not reach a resource handler, such as subscriptions.
+func (e *Engine) ResourceCheck(modelsOf func(uri string) []string) func(ctx context.Context, uri string) error {
+	return func(ctx context.Context, uri string) error {
+		if !e.CanRead(auth.FromContext(ctx), modelsOf(uri)...) {
+			return fmt.Errorf("forbidden: %s", uri)
+		}
+		return nil
+	}
+}
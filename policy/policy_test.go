@@ -0,0 +1,206 @@
+package policy
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"mcp-bedrock-go/auth"
+	"mcp-bedrock-go/tools"
+)
+
+func engine(t *testing.T, anonymous string) *Engine {
+	t.Helper()
+	roles := Merge(Defaults(), map[string]Role{
+		// may call create_mo but not write manufacturing orders
+		"readonly_mo": {Tools: []string{"create_mo"}, ReadModels: []string{Any}},
+		// may read products only
+		"catalogue": {Tools: []string{"find_product", "list_all_orders"}, ReadModels: []string{"product.product"}},
+	})
+	e, err := New(roles, anonymous)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func principal(roles ...string) *auth.Principal {
+	return &auth.Principal{Subject: "someone", Roles: roles, Method: "api_key"}
+}
+
+func TestCheck(t *testing.T) {
+	e := engine(t, "")
+	tests := []struct {
+		name    string
+		p       *auth.Principal
+		tool    string
+		args    map[string]any
+		wantErr string // "" when allowed
+	}{
+		{"inherited tool", principal("planner"), "list_all_orders", nil, ""},
+		{"inherited twice", principal("supervisor"), "order_risk", nil, ""},
+		{"own tool", principal("planner"), "create_mo", map[string]any{"qty": 500.0}, ""},
+		{"tool not granted", principal("viewer"), "create_mo", map[string]any{"qty": 1.0}, "tool not allowed"},
+		{"at the limit", principal("planner"), "create_mo", map[string]any{"qty": 1000.0}, ""},
+		{"above the limit", principal("planner"), "create_mo", map[string]any{"qty": 1500.0}, "exceeds the maximum 1000"},
+		{"below the minimum", principal("planner"), "create_mo", map[string]any{"qty": 0.5}, "below the minimum 1"},
+		{"limit as string", principal("planner"), "create_mo", map[string]any{"qty": "1500"}, "exceeds the maximum"},
+		{"unparsed string", principal("planner"), "create_mo", map[string]any{"qty": "1,500"}, "is not a number"},
+		{"override of inherited limit", principal("supervisor"), "create_mo", map[string]any{"qty": 5000.0}, ""},
+		{"above the overridden limit", principal("supervisor"), "create_mo", map[string]any{"qty": 20000.0}, "exceeds the maximum 10000"},
+		{"any role suffices", principal("viewer", "supervisor"), "create_mo", map[string]any{"qty": 5000.0}, ""},
+		{"denied model write", principal("readonly_mo"), "create_mo", map[string]any{"qty": 1.0}, "may not write mrp.production"},
+		{"allowed model read", principal("catalogue"), "find_product", nil, ""},
+		{"denied model read", principal("catalogue"), "list_all_orders", nil, "may not read mrp.production"},
+		{"admin", principal("admin"), "add_product", nil, ""},
+		{"unknown role", principal("ghost"), "list_all_orders", nil, "unknown role"},
+		{"no roles", principal(), "list_all_orders", nil, "no role grants access"},
+		{"no principal", nil, "list_all_orders", nil, "no role grants access"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := e.Check(tt.p, tt.tool, tt.args)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("denied: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestMiddlewareAfterValidate runs a call through argument validation and
+// then the policy, as the server does, so "1,500" is judged as 1500.
+func TestMiddlewareAfterValidate(t *testing.T) {
+	e := engine(t, "")
+	handler := tools.Validate(e.Middleware(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("created"), nil
+	}))
+	ctx := auth.WithPrincipal(context.Background(), principal("planner"))
+	for qty, allowed := range map[string]bool{"1,000": true, "1,500": false, "999": true} {
+		var req mcp.CallToolRequest
+		req.Params.Name = "create_mo"
+		req.Params.Arguments = map[string]any{"product_code": "A100", "qty": qty}
+		res, err := handler(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		text := res.Content[0].(mcp.TextContent).Text
+		if allowed && res.IsError {
+			t.Errorf("qty %q denied: %s", qty, text)
+		}
+		if !allowed && (!res.IsError || !strings.Contains(text, "forbidden")) {
+			t.Errorf("qty %q allowed: %s", qty, text)
+		}
+	}
+}
+
+func TestAnonymous(t *testing.T) {
+	deny := engine(t, "")
+	if deny.CanList(nil, "list_all_orders") || deny.CanRead(nil, "mrp.production") {
+		t.Fatal("no anonymous role, yet a caller without principal is allowed")
+	}
+	if got := deny.Filter(context.Background(), []mcp.Tool{{Name: "list_all_orders"}}); len(got) != 0 {
+		t.Fatalf("Filter without principal kept %v", got)
+	}
+
+	viewer := engine(t, "viewer")
+	if err := viewer.Check(nil, "list_all_orders", nil); err != nil {
+		t.Fatalf("anonymous viewer denied: %v", err)
+	}
+	if err := viewer.Check(nil, "create_mo", map[string]any{"qty": 1.0}); err == nil {
+		t.Fatal("anonymous viewer may create orders")
+	}
+	got := viewer.Filter(context.Background(), []mcp.Tool{{Name: "list_all_orders"}, {Name: "create_mo"}})
+	if len(got) != 1 || got[0].Name != "list_all_orders" {
+		t.Fatalf("Filter for anonymous viewer: %v", got)
+	}
+
+	if _, err := New(Defaults(), "ghost"); err == nil {
+		t.Fatal("undefined anonymous role accepted")
+	}
+}
+
+func TestFilter(t *testing.T) {
+	e := engine(t, "")
+	ctx := auth.WithPrincipal(context.Background(), principal("planner"))
+	var names []string
+	for _, tl := range e.Filter(ctx, []mcp.Tool{{Name: "create_mo"}, {Name: "add_product"}, {Name: "search_docs"}}) {
+		names = append(names, tl.Name)
+	}
+	if strings.Join(names, ",") != "create_mo,search_docs" {
+		t.Fatalf("planner sees %v", names)
+	}
+}
+
+func TestResourceCheck(t *testing.T) {
+	e := engine(t, "")
+	modelsOf := func(uri string) []string {
+		return []string{strings.SplitN(uri, "/", 4)[2]} // odoo://<model>/<id>
+	}
+	check := e.ResourceCheck(modelsOf)
+	tests := []struct {
+		p    *auth.Principal
+		uri  string
+		want bool
+	}{
+		{principal("viewer"), "odoo://mrp.production/1", true},
+		{principal("catalogue"), "odoo://product.product/1", true},
+		{principal("catalogue"), "odoo://mrp.production/1", false},
+		{nil, "odoo://product.product/1", false},
+	}
+	for _, tt := range tests {
+		ctx := context.Background()
+		if tt.p != nil {
+			ctx = auth.WithPrincipal(ctx, tt.p)
+		}
+		if err := check(ctx, tt.uri); (err == nil) != tt.want {
+			t.Errorf("%v reading %s: got %v, want allowed=%v", tt.p, tt.uri, err, tt.want)
+		}
+	}
+}
+
+func TestInheritanceErrors(t *testing.T) {
+	if _, err := New(map[string]Role{"a": {Inherits: []string{"b"}}, "b": {Inherits: []string{"a"}}}, ""); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Fatalf("cycle: got %v", err)
+	}
+	if _, err := New(map[string]Role{"a": {Inherits: []string{"missing"}}}, ""); err == nil || !strings.Contains(err.Error(), "not defined") {
+		t.Fatalf("missing parent: got %v", err)
+	}
+}
+
+func TestPromptMiddleware(t *testing.T) {
+	e := engine(t, "")
+	models := map[string][]string{"briefing": {"mrp.workorder"}, "catalogue": {"product.product"}}
+	handler := e.PromptMiddleware(func(name string) []string { return models[name] })(
+		func(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			return mcp.NewGetPromptResult("ok", nil), nil
+		})
+	tests := []struct {
+		p      *auth.Principal
+		prompt string
+		want   bool
+	}{
+		{principal("viewer"), "briefing", true},
+		{principal("catalogue"), "catalogue", true},
+		{principal("catalogue"), "briefing", false},
+		{nil, "catalogue", false},
+	}
+	for _, tt := range tests {
+		ctx := context.Background()
+		if tt.p != nil {
+			ctx = auth.WithPrincipal(ctx, tt.p)
+		}
+		var req mcp.GetPromptRequest
+		req.Params.Name = tt.prompt
+		if _, err := handler(ctx, req); (err == nil) != tt.want {
+			t.Errorf("%v getting %s: got %v, want allowed=%v", tt.p, tt.prompt, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+// Package productsearch resolves fuzzy product references ("the fragile custom
+// box", "tea box") to product.product records using trigram matching and,
+// optionally, embedding similarity.
+package productsearch
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"mcp-bedrock-go/internal/logging"
+	"mcp-bedrock-go/internal/metrics"
+	odoolib "mcp-bedrock-go/odoo"
+	"mcp-bedrock-go/retrieval"
+)
+
+var logger = logging.For("productsearch")
+
+// Product is the subset of product.product used for matching.
+type Product struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	DefaultCode string `json:"default_code,omitempty"`
+	Category    string `json:"category,omitempty"`
+}
+
+// Candidate is a ranked match.
+type Candidate struct {
+	Product
+	Score  float64 `json:"score"`
+	Reason string  `json:"reason"`
+}
+
+// stopwords are dropped from queries before matching.
+var stopwords = map[string]bool{"the": true, "a": true, "an": true, "of": true, "for": true, "product": true}
+
+// Index caches the product catalogue and refreshes it after TTL.
+type Index struct {
+	oclient  *odoolib.Client
+	embedder retrieval.Embedder
+	ttl      time.Duration
+
+	mu       sync.RWMutex
+	products []Product
+	tokens   [][]string
+	grams    []map[string]bool
+	vectors  [][]float32
+	loaded   time.Time
+}
+
+// New creates an index over oclient's products. embedder may be nil.
+func New(oclient *odoolib.Client, embedder retrieval.Embedder, ttl time.Duration) *Index {
+	return &Index{oclient: oclient, embedder: embedder, ttl: ttl}
+}
+
+// Refresh reloads products from Odoo. The catalogue is shared by every
+// caller, so it is read as the service account rather than as a delegated
+// user whose access rules would decide what everyone else finds.
+func (ix *Index) Refresh(ctx context.Context) error {
+	ctx = odoolib.WithClient(ctx, nil)
+	recs, err := ix.oclient.SearchReadContext(ctx, "product.product", []string{"id", "name", "default_code", "categ_id"}, []any{})
+	if err != nil {
+		return err
+	}
+	products := make([]Product, 0, len(recs))
+	for _, r := range recs {
+		p := Product{Name: str(r["name"]), DefaultCode: str(r["default_code"])}
+		if id, ok := r["id"].(float64); ok {
+			p.ID = int(id)
+		}
+		if c, ok := r["categ_id"].([]any); ok && len(c) > 1 {
+			p.Category = str(c[1])
+		}
+		products = append(products, p)
+	}
+	ix.Set(ctx, products)
+	return nil
+}
+
+// Set replaces the catalogue, e.g. from Refresh or from a fixture.
+func (ix *Index) Set(ctx context.Context, products []Product) {
+	tokens := make([][]string, len(products))
+	grams := make([]map[string]bool, len(products))
+	texts := make([]string, len(products))
+	for i, p := range products {
+		text := p.Name + " " + p.DefaultCode + " " + p.Category
+		tokens[i] = tokenize(text)
+		grams[i] = trigrams(strings.Join(tokens[i], " "))
+		texts[i] = text
+	}
+
+	var vectors [][]float32
+	if ix.embedder != nil && len(products) > 0 {
+		v, err := ix.embedder.Embed(ctx, texts)
+		if err != nil {
+			logger.ErrorCtxf(ctx, "productsearch: embedding %d products failed, using trigram only: %v", len(products), err)
+		} else {
+			vectors = v
+		}
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.products = products
+	ix.tokens = tokens
+	ix.grams = grams
+	ix.vectors = vectors
+	ix.loaded = time.Now()
+}
+
+// Search ranks products against a free-text query and returns the best k.
+func (ix *Index) Search(ctx context.Context, query string, k int) ([]Candidate, error) {
+	if k <= 0 {
+		k = 5
+	}
+	ix.mu.RLock()
+	stale := ix.loaded.IsZero() || (ix.ttl > 0 && time.Since(ix.loaded) > ix.ttl)
+	ix.mu.RUnlock()
+	metrics.CacheLookup("product_catalogue", !stale)
+	if stale {
+		if err := ix.Refresh(ctx); err != nil {
+			return nil, fmt.Errorf("load products: %w", err)
+		}
+	}
+
+	qTokens := tokenize(query)
+	if len(qTokens) == 0 {
+		return nil, nil
+	}
+	qGrams := trigrams(strings.Join(qTokens, " "))
+	qCode := strings.ToLower(strings.TrimSpace(query))
+
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	var qVec []float32
+	if ix.embedder != nil && len(ix.vectors) == len(ix.products) {
+		if v, err := ix.embedder.Embed(ctx, []string{query}); err == nil && len(v) > 0 {
+			qVec = v[0]
+		}
+	}
+
+	out := make([]Candidate, 0, len(ix.products))
+	for i, p := range ix.products {
+		c := Candidate{Product: p}
+		switch {
+		case p.DefaultCode != "" && strings.ToLower(p.DefaultCode) == qCode:
+			c.Score, c.Reason = 1, "exact code"
+		case strings.ToLower(p.Name) == qCode:
+			c.Score, c.Reason = 1, "exact name"
+		default:
+			cov := coverage(qTokens, ix.tokens[i])
+			tri := containment(qGrams, ix.grams[i])
+			c.Score = 0.6*cov + 0.4*tri
+			c.Reason = fmt.Sprintf("tokens %.2f, trigrams %.2f", cov, tri)
+			if qVec != nil {
+				sim := retrieval.Cosine(qVec, ix.vectors[i])
+				c.Score = 0.5*c.Score + 0.5*sim
+				c.Reason += fmt.Sprintf(", embedding %.2f", sim)
+			}
+		}
+		if c.Score > 0.2 {
+			out = append(out, c)
+		}
+	}
+	sort.SliceStable(out, func(a, b int) bool { return out[a].Score > out[b].Score })
+	if len(out) > k {
+		out = out[:k]
+	}
+	return out, nil
+}
+
+// Confident reports whether the top candidate is a clear winner: a high score
+// and a margin over the runner-up.
+func Confident(cands []Candidate) bool {
+	if len(cands) == 0 || cands[0].Score < 0.75 {
+		return false
+	}
+	return len(cands) == 1 || cands[0].Score-cands[1].Score >= 0.15
+}
+
+// coverage is the share of query tokens that fuzzy-match some product token.
+func coverage(query, product []string) float64 {
+	total := 0.0
+	for _, q := range query {
+		best := 0.0
+		for _, p := range product {
+			if s := tokenSim(q, p); s > best {
+				best = s
+			}
+		}
+		total += best
+	}
+	return total / float64(len(query))
+}
+
+func tokenSim(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	if len(a) >= 3 && len(b) >= 3 && (strings.HasPrefix(a, b) || strings.HasPrefix(b, a)) {
+		return 0.8
+	}
+	s := jaccard(trigrams(a), trigrams(b))
+	if s < 0.3 {
+		return 0
+	}
+	return s
+}
+
+func tokenize(s string) []string {
+	var out []string
+	for _, f := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if !stopwords[f] {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// trigrams returns the padded character trigrams of each word in s.
+func trigrams(s string) map[string]bool {
+	out := map[string]bool{}
+	for _, w := range strings.Fields(s) {
+		r := []rune("  " + w + " ")
+		for i := 0; i+3 <= len(r); i++ {
+			out[string(r[i:i+3])] = true
+		}
+	}
+	return out
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	inter := 0
+	for g := range a {
+		if b[g] {
+			inter++
+		}
+	}
+	return float64(inter) / float64(len(a)+len(b)-inter)
+}
+
+// containment is the share of query trigrams present in the product.
+func containment(q, p map[string]bool) float64 {
+	if len(q) == 0 {
+		return 0
+	}
+	inter := 0
+	for g := range q {
+		if p[g] {
+			inter++
+		}
+	}
+	return float64(inter) / float64(len(q))
+}
+
+func str(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return ""
+}
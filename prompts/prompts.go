@@ -0,0 +1,299 @@
+// Package prompts publishes reusable MCP prompts for recurring planning
+// questions. Each prompt gathers live Odoo context with the same builders as
+// schedule_analysis and production_planner, links the relevant odoo://
+// resources and names the tools worth calling next.
+package prompts
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	odoolib "mcp-bedrock-go/odoo"
+	"mcp-bedrock-go/retrieval"
+	"mcp-bedrock-go/tools"
+)
+
+// Set holds what the prompt handlers read from.
+type Set struct {
+	odoo *odoolib.Client
+	docs *retrieval.Index
+	now  func() time.Time
+}
+
+// models lists the Odoo models each prompt reads for its context.
+var models = map[string][]string{
+	"rush_order_impact":        {"mrp.production", "product.product"},
+	"daily_shift_briefing":     {"mrp.workorder", "mrp.production", "product.product"},
+	"late_order_triage":        {"mrp.production", "product.product"},
+	"material_shortage_review": {"mrp.production", "product.product"},
+}
+
+// Models returns the Odoo models a prompt reads, for authorization.
+func Models(name string) []string { return models[name] }
+
+// Middleware wraps a prompt handler, like the server's tool and resource
+// middlewares.
+type Middleware func(server.PromptHandlerFunc) server.PromptHandlerFunc
+
+// Register adds the planning prompts to the MCP server. docs may be nil.
+// The middlewares wrap every prompt, the first outermost; mcp-go has no
+// prompt middleware of its own.
+func Register(s *server.MCPServer, oclient *odoolib.Client, docs *retrieval.Index, mws ...Middleware) {
+	p := &Set{odoo: oclient, docs: docs, now: time.Now}
+	wrap := func(h server.PromptHandlerFunc) server.PromptHandlerFunc {
+		for i := len(mws) - 1; i >= 0; i-- {
+			h = mws[i](h)
+		}
+		return h
+	}
+	s.AddPrompt(mcp.NewPrompt("rush_order_impact",
+		mcp.WithPromptDescription("Assess how a rush order affects the current schedule"),
+		mcp.WithArgument("product_code", mcp.RequiredArgument(), mcp.ArgumentDescription("Internal reference of the rush product")),
+		mcp.WithArgument("qty", mcp.RequiredArgument(), mcp.ArgumentDescription("Quantity ordered")),
+		mcp.WithArgument("deadline", mcp.ArgumentDescription("Requested delivery date, YYYY-MM-DD")),
+		mcp.WithArgument("profile", mcp.ArgumentDescription("Planning profile, e.g. Cost-Aware or Throughput (default Balanced)")),
+	), wrap(p.rushOrderImpact))
+	s.AddPrompt(mcp.NewPrompt("daily_shift_briefing",
+		mcp.WithPromptDescription("Brief a shift on today's orders, work centers and risks"),
+		mcp.WithArgument("date", mcp.ArgumentDescription("Day to brief, YYYY-MM-DD (default today)")),
+		mcp.WithArgument("shift", mcp.ArgumentDescription("Shift name, e.g. morning or night")),
+	), wrap(p.dailyShiftBriefing))
+	s.AddPrompt(mcp.NewPrompt("late_order_triage",
+		mcp.WithPromptDescription("Rank overdue manufacturing orders and propose recovery actions"),
+		mcp.WithArgument("as_of", mcp.ArgumentDescription("Reference date, YYYY-MM-DD (default today)")),
+	), wrap(p.lateOrderTriage))
+	s.AddPrompt(mcp.NewPrompt("material_shortage_review",
+		mcp.WithPromptDescription("Check whether components are short for an MO or product"),
+		mcp.WithArgument("mo_id", mcp.ArgumentDescription("Manufacturing order to review")),
+		mcp.WithArgument("product_code", mcp.ArgumentDescription("Product to review when no MO is given")),
+	), wrap(p.materialShortageReview))
+}
+
+func (p *Set) rushOrderImpact(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	args := req.Params.Arguments
+	code, qty := args["product_code"], args["qty"]
+	if code == "" || qty == "" {
+		return nil, fmt.Errorf("product_code and qty are required")
+	}
+	profile := orDefault(args["profile"], "Balanced")
+	question := fmt.Sprintf("A new RUSH order (Product Code: %s, Qty:%s) has arrived.", code, qty)
+	if d := args["deadline"]; d != "" {
+		question += " It is due " + d + "."
+	}
+	question += " Assess the impact on the orders in progress, which orders slip and by how much, and give a concise recommendation and rationale."
+
+	text := p.withDocs(ctx, profile+" "+question, tools.SchedulePrompt(profile, tools.ScheduleContext(ctx, p.odoo), "", question))
+	text += hints(
+		"material_availability (product_id, mo_id) to confirm components",
+		"capacity_check (workcenter_id, date) for the bottleneck work centers",
+		"schedule_analysis (profile="+profile+") to compare planning profiles",
+		"create_mo (product_code="+code+", qty="+qty+", dry_run=true) to rehearse the order before creating it",
+	)
+	return result("Rush order impact for "+code, text,
+		link("odoo://product/"+url.PathEscape(code), "Product "+code),
+		link("odoo://bom/"+url.PathEscape(code), "BOM of "+code),
+	), nil
+}
+
+func (p *Set) dailyShiftBriefing(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	day := orDefault(req.Params.Arguments["date"], p.now().In(tools.Location).Format("2006-01-02"))
+	shift := req.Params.Arguments["shift"]
+	start, err := time.ParseInLocation("2006-01-02", day, tools.Location)
+	if err != nil {
+		return nil, fmt.Errorf("date: %q is not YYYY-MM-DD", day)
+	}
+
+	// date_planned_start is a UTC datetime: the day runs from the plant's midnight to the next
+	wos, err := p.odoo.SearchReadContext(ctx, "mrp.workorder",
+		[]string{"id", "name", "workcenter_id", "production_id", "state", "duration", "date_planned_start"},
+		[]any{
+			[]any{"date_planned_start", ">=", start.UTC().Format(time.DateTime)},
+			[]any{"date_planned_start", "<", start.AddDate(0, 0, 1).UTC().Format(time.DateTime)},
+			[]any{"state", "not in", []any{"done", "cancel"}},
+		})
+	if err != nil {
+		return nil, fmt.Errorf("odoo: %w", err)
+	}
+	ctxObj := tools.ScheduleContext(ctx, p.odoo)
+	ctxObj["work_orders_today"] = wos
+
+	who := "the shift"
+	if shift != "" {
+		who = "the " + shift + " shift"
+	}
+	question := fmt.Sprintf("Prepare a briefing for %s on %s: which orders run where, expected finish times, bottlenecks, and anything at risk of missing its deadline. Keep it short enough to read aloud.", who, day)
+	text := p.withDocs(ctx, "shift briefing safety changeover "+day, tools.SchedulePrompt("Balanced", ctxObj, "", question))
+	text += hints(
+		"list_active_products for the latest MO states",
+		"capacity_check (workcenter_id, date="+day+") per busy work center",
+		"order_priority to confirm the running order",
+	)
+
+	var links []mcp.Content
+	seen := map[int]bool{}
+	for _, wo := range wos {
+		if id := many2oneID(wo["workcenter_id"]); id != 0 && !seen[id] {
+			seen[id] = true
+			links = append(links, link(fmt.Sprintf("odoo://workcenter/%d", id), fmt.Sprintf("Work center %v", name(wo["workcenter_id"]))))
+		}
+	}
+	return result("Shift briefing for "+day, text, links...), nil
+}
+
+func (p *Set) lateOrderTriage(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	asOf := orDefault(req.Params.Arguments["as_of"], p.now().Format("2006-01-02"))
+	late, err := p.odoo.SearchReadContext(ctx, "mrp.production",
+		[]string{"id", "name", "product_id", "product_qty", "date_deadline", "state"},
+		[]any{[]any{"date_deadline", "<", asOf}, []any{"state", "not in", []any{"done", "cancel"}}})
+	if err != nil {
+		return nil, fmt.Errorf("odoo: %w", err)
+	}
+
+	var b strings.Builder
+	if len(late) == 0 {
+		fmt.Fprintf(&b, "No manufacturing orders are overdue as of %s. Confirm with order_priority and flag any at risk of slipping.\n", asOf)
+	} else {
+		ctxObj := tools.ScheduleContext(ctx, p.odoo)
+		ctxObj["late_orders"] = late
+		question := fmt.Sprintf("%d manufacturing orders are past their deadline as of %s. Rank them by customer and cost impact, and for each propose a recovery action (resequence, overtime, split, or renegotiate the date).", len(late), asOf)
+		b.WriteString(p.withDocs(ctx, "late order recovery overtime resequence", tools.SchedulePrompt("Balanced", ctxObj, "", question)))
+		// The most overdue order gets a ready-made plan request.
+		b.WriteString("\n\nFor the most overdue order, also draft a recovery plan:\n")
+		b.WriteString(tools.PlannerPrompt(mostOverdue(late)))
+	}
+	b.WriteString(hints(
+		"order_risk (mo_id) per late order",
+		"production_planner (mo_id) for a recovery plan",
+		"order_priority for the overall ranking",
+	))
+
+	var links []mcp.Content
+	for _, mo := range late {
+		links = append(links, link(fmt.Sprintf("odoo://mrp.production/%d", many2oneID(mo["id"])), fmt.Sprintf("MO %v", mo["name"])))
+	}
+	return result("Late order triage as of "+asOf, b.String(), links...), nil
+}
+
+func (p *Set) materialShortageReview(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	moArg, code := req.Params.Arguments["mo_id"], req.Params.Arguments["product_code"]
+	if moArg == "" && code == "" {
+		return nil, fmt.Errorf("mo_id or product_code is required")
+	}
+
+	var links []mcp.Content
+	var mo map[string]any
+	if moArg != "" {
+		id, err := strconv.Atoi(moArg)
+		if err != nil {
+			return nil, fmt.Errorf("mo_id %q is not a number", moArg)
+		}
+		mos, err := p.odoo.SearchReadContext(ctx, "mrp.production",
+			[]string{"id", "name", "product_id", "product_qty", "date_deadline", "state"},
+			[]any{[]any{"id", "=", id}})
+		if err != nil {
+			return nil, fmt.Errorf("odoo: %w", err)
+		}
+		if len(mos) == 0 {
+			return nil, fmt.Errorf("MO %d not found", id)
+		}
+		mo = mos[0]
+		links = append(links, link("odoo://mrp.production/"+moArg, fmt.Sprintf("MO %v", mo["name"])))
+		if code == "" {
+			prods, _ := p.odoo.SearchReadContext(ctx, "product.product", []string{"id", "default_code"},
+				[]any{[]any{"id", "=", many2oneID(mo["product_id"])}})
+			if len(prods) > 0 {
+				code, _ = prods[0]["default_code"].(string)
+			}
+		}
+	}
+	if code != "" {
+		links = append(links,
+			link("odoo://bom/"+url.PathEscape(code), "BOM of "+code),
+			link("odoo://product/"+url.PathEscape(code), "Product "+code))
+	}
+
+	var b strings.Builder
+	b.WriteString("Review material availability")
+	if mo != nil {
+		fmt.Fprintf(&b, " for MO %v (%v × %v, due %v)", mo["name"], mo["product_qty"], name(mo["product_id"]), mo["date_deadline"])
+	} else {
+		fmt.Fprintf(&b, " for product %s", code)
+	}
+	b.WriteString(". List each BOM component, the quantity required, what is on hand, and the shortfall. Suggest substitutes or expediting where a component is short, and say whether the order can start on time.")
+	text := p.withDocs(ctx, "material shortage substitute component "+code, b.String())
+	text += hints(
+		"material_availability (product_id, mo_id) for BOM and stock figures",
+		"find_product (query) to look for substitute components",
+	)
+	return result("Material shortage review", text, links...), nil
+}
+
+// withDocs prefixes the prompt with matching plant documents.
+func (p *Set) withDocs(ctx context.Context, query, prompt string) string {
+	if sops := tools.DocsContext(ctx, p.docs, query); sops != "" {
+		return sops + "\n\n" + prompt
+	}
+	return prompt
+}
+
+func hints(lines ...string) string {
+	return "\n\nTools you can call:\n- " + strings.Join(lines, "\n- ")
+}
+
+func link(uri, title string) mcp.Content {
+	return mcp.NewResourceLink(uri, title, "Add ?format=markdown for a readable view", "application/json")
+}
+
+// result puts the task text and each resource link in user messages.
+func result(desc, text string, links ...mcp.Content) *mcp.GetPromptResult {
+	msgs := []mcp.PromptMessage{mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text))}
+	for _, l := range links {
+		msgs = append(msgs, mcp.NewPromptMessage(mcp.RoleUser, l))
+	}
+	return mcp.NewGetPromptResult(desc, msgs)
+}
+
+func mostOverdue(mos []map[string]any) map[string]any {
+	best := mos[0]
+	for _, mo := range mos[1:] {
+		if fmt.Sprint(mo["date_deadline"]) < fmt.Sprint(best["date_deadline"]) {
+			best = mo
+		}
+	}
+	return best
+}
+
+func orDefault(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
+
+func many2oneID(v any) int {
+	switch t := v.(type) {
+	case float64:
+		return int(t)
+	case int:
+		return t
+	case []any:
+		if len(t) > 0 {
+			return many2oneID(t[0])
+		}
+	}
+	return 0
+}
+
+func name(v any) any {
+	if t, ok := v.([]any); ok && len(t) == 2 {
+		return t[1]
+	}
+	return v
+}
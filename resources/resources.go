@@ -0,0 +1,333 @@
+// Package resources exposes Odoo manufacturing records as MCP resources so
+// clients can browse them and attach them as context. Every template accepts
+// ?format=markdown; the default is JSON.
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	odoolib "mcp-bedrock-go/odoo"
+)
+
+// URI templates served by Register.
+const (
+	ProductionURI = "odoo://mrp.production/{id}{?format}"
+	ProductURI    = "odoo://product/{default_code}{?format}"
+	WorkcenterURI = "odoo://workcenter/{id}{?format}"
+	BOMURI        = "odoo://bom/{product_code}{?format}"
+)
+
+// ErrNotFound is returned when the record behind a URI does not exist.
+var ErrNotFound = errors.New("record not found")
+
+// Doc is a rendered resource: a title, the record data and optional tables
+// of related records for the Markdown view.
+type Doc struct {
+	Title  string
+	Fields map[string]any
+	Tables []Table
+}
+
+// Table is a list of related records rendered as a Markdown table.
+type Table struct {
+	Title   string
+	Columns []string
+	Rows    []map[string]any
+}
+
+type loader func(ctx context.Context, vars map[string]string) (*Doc, error)
+
+type entry struct {
+	tmpl   mcp.ResourceTemplate
+	models []string // Odoo models read, for authorization
+	load   loader
+}
+
+// Registry holds the templates and reads resources by URI.
+type Registry struct {
+	odoo    *odoolib.Client
+	entries []entry
+}
+
+// New builds the registry backed by the Odoo client.
+func New(oclient *odoolib.Client) *Registry {
+	r := &Registry{odoo: oclient}
+	r.add(ProductionURI, "Manufacturing order", "An MO with its work orders", []string{"mrp.production", "mrp.workorder"}, r.production)
+	r.add(ProductURI, "Product", "A product looked up by internal reference", []string{"product.product"}, r.product)
+	r.add(WorkcenterURI, "Work center", "A work center with its open work orders", []string{"mrp.workcenter", "mrp.workorder"}, r.workcenter)
+	r.add(BOMURI, "Bill of materials", "The BOM of a product and its component lines", []string{"product.product", "mrp.bom", "mrp.bom.line"}, r.bom)
+	return r
+}
+
+func (r *Registry) add(uri, name, desc string, models []string, load loader) {
+	r.entries = append(r.entries, entry{
+		tmpl: mcp.NewResourceTemplate(uri, name,
+			mcp.WithTemplateDescription(desc+". Add ?format=markdown for a readable view."),
+			mcp.WithTemplateMIMEType("application/json")),
+		models: models,
+		load:   load,
+	})
+}
+
+// Register adds the templates to the MCP server.
+func (r *Registry) Register(s *server.MCPServer) {
+	for _, e := range r.entries {
+		s.AddResourceTemplate(e.tmpl, func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			text, mime, err := r.Read(ctx, req.Params.URI)
+			if err != nil {
+				return nil, err
+			}
+			return []mcp.ResourceContents{mcp.TextResourceContents{URI: req.Params.URI, MIMEType: mime, Text: text}}, nil
+		})
+	}
+}
+
+// Models returns the Odoo models read to serve uri, or nil if no template
+// matches.
+func (r *Registry) Models(uri string) []string {
+	if e, _ := r.match(uri); e != nil {
+		return e.models
+	}
+	return nil
+}
+
+// Read renders the resource at uri and returns the text and MIME type.
+func (r *Registry) Read(ctx context.Context, uri string) (string, string, error) {
+	e, vars := r.match(uri)
+	if e == nil {
+		return "", "", fmt.Errorf("no resource template matches %s", uri)
+	}
+	doc, err := e.load(ctx, vars)
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", uri, err)
+	}
+	if strings.EqualFold(vars["format"], "markdown") || strings.EqualFold(vars["format"], "md") {
+		return doc.Markdown(), "text/markdown", nil
+	}
+	b, err := json.MarshalIndent(doc.JSON(), "", "  ")
+	if err != nil {
+		return "", "", err
+	}
+	return string(b), "application/json", nil
+}
+
+func (r *Registry) match(uri string) (*entry, map[string]string) {
+	for i := range r.entries {
+		t := r.entries[i].tmpl.URITemplate
+		if !t.Regexp().MatchString(uri) {
+			continue
+		}
+		vars := map[string]string{}
+		for name, v := range t.Match(uri) {
+			if len(v.V) > 0 {
+				vars[name] = v.V[0]
+			}
+		}
+		return &r.entries[i], vars
+	}
+	return nil, nil
+}
+
+func (r *Registry) production(ctx context.Context, vars map[string]string) (*Doc, error) {
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		return nil, fmt.Errorf("id %q is not a number", vars["id"])
+	}
+	mos, err := r.odoo.SearchReadContext(ctx, "mrp.production",
+		[]string{"id", "name", "product_id", "product_qty", "date_deadline", "date_planned_start", "state"},
+		[]any{[]any{"id", "=", id}})
+	if err != nil {
+		return nil, err
+	}
+	if len(mos) == 0 {
+		return nil, ErrNotFound
+	}
+	wos, err := r.odoo.SearchReadContext(ctx, "mrp.workorder",
+		[]string{"id", "name", "workcenter_id", "state", "duration", "date_planned_start"},
+		[]any{[]any{"production_id", "=", id}})
+	if err != nil {
+		return nil, err
+	}
+	return &Doc{
+		Title:  fmt.Sprintf("Manufacturing order %v", mos[0]["name"]),
+		Fields: mos[0],
+		Tables: []Table{{Title: "Work orders", Columns: []string{"id", "name", "workcenter_id", "state", "duration"}, Rows: wos}},
+	}, nil
+}
+
+func (r *Registry) product(ctx context.Context, vars map[string]string) (*Doc, error) {
+	p, err := r.productByCode(ctx, vars["default_code"])
+	if err != nil {
+		return nil, err
+	}
+	return &Doc{Title: fmt.Sprintf("Product %v", p["name"]), Fields: p}, nil
+}
+
+func (r *Registry) productByCode(ctx context.Context, code string) (map[string]any, error) {
+	if code == "" {
+		return nil, errors.New("default_code is required")
+	}
+	prods, err := r.odoo.SearchReadContext(ctx, "product.product",
+		[]string{"id", "name", "default_code", "categ_id", "list_price", "product_tmpl_id"},
+		[]any{[]any{"default_code", "=", code}})
+	if err != nil {
+		return nil, err
+	}
+	if len(prods) == 0 {
+		return nil, ErrNotFound
+	}
+	return prods[0], nil
+}
+
+func (r *Registry) workcenter(ctx context.Context, vars map[string]string) (*Doc, error) {
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		return nil, fmt.Errorf("id %q is not a number", vars["id"])
+	}
+	wcs, err := r.odoo.SearchReadContext(ctx, "mrp.workcenter",
+		[]string{"id", "name", "capacity", "costs_hour", "time_efficiency"},
+		[]any{[]any{"id", "=", id}})
+	if err != nil {
+		return nil, err
+	}
+	if len(wcs) == 0 {
+		return nil, ErrNotFound
+	}
+	wos, err := r.odoo.SearchReadContext(ctx, "mrp.workorder",
+		[]string{"id", "name", "production_id", "state", "duration", "date_planned_start"},
+		[]any{[]any{"workcenter_id", "=", id}, []any{"state", "not in", []any{"done", "cancel"}}})
+	if err != nil {
+		return nil, err
+	}
+	return &Doc{
+		Title:  fmt.Sprintf("Work center %v", wcs[0]["name"]),
+		Fields: wcs[0],
+		Tables: []Table{{Title: "Open work orders", Columns: []string{"id", "name", "production_id", "state", "duration", "date_planned_start"}, Rows: wos}},
+	}, nil
+}
+
+func (r *Registry) bom(ctx context.Context, vars map[string]string) (*Doc, error) {
+	p, err := r.productByCode(ctx, vars["product_code"])
+	if err != nil {
+		return nil, err
+	}
+	tmplID := many2oneID(p["product_tmpl_id"])
+	boms, err := r.odoo.SearchReadContext(ctx, "mrp.bom", []string{"id", "product_tmpl_id", "product_qty"},
+		[]any{[]any{"product_tmpl_id", "=", tmplID}})
+	if err != nil {
+		return nil, err
+	}
+	if len(boms) == 0 {
+		return nil, ErrNotFound
+	}
+	lines, err := r.odoo.SearchReadContext(ctx, "mrp.bom.line", []string{"id", "product_id", "product_qty"},
+		[]any{[]any{"bom_id", "=", many2oneID(boms[0]["id"])}})
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]any{"bom_id": boms[0]["id"], "product": p["name"], "default_code": p["default_code"]}
+	return &Doc{
+		Title:  fmt.Sprintf("Bill of materials for %v", p["name"]),
+		Fields: fields,
+		Tables: []Table{{Title: "Components", Columns: []string{"product_id", "product_qty"}, Rows: lines}},
+	}, nil
+}
+
+// many2oneID reads an id from a plain number or an Odoo [id, name] pair.
+func many2oneID(v any) int {
+	switch t := v.(type) {
+	case float64:
+		return int(t)
+	case int:
+		return t
+	case []any:
+		if len(t) > 0 {
+			return many2oneID(t[0])
+		}
+	}
+	return 0
+}
+
+// JSON returns the record with its related tables as one object.
+func (d *Doc) JSON() map[string]any {
+	out := map[string]any{}
+	for k, v := range d.Fields {
+		out[k] = v
+	}
+	for _, t := range d.Tables {
+		rows := t.Rows
+		if rows == nil {
+			rows = []map[string]any{}
+		}
+		out[strings.ReplaceAll(strings.ToLower(t.Title), " ", "_")] = rows
+	}
+	return out
+}
+
+// Markdown renders the record as a heading, a field list and tables.
+func (d *Doc) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", d.Title)
+	keys := make([]string, 0, len(d.Fields))
+	for k := range d.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "- **%s**: %s\n", k, cell(d.Fields[k]))
+	}
+	for _, t := range d.Tables {
+		fmt.Fprintf(&b, "\n## %s\n\n", t.Title)
+		if len(t.Rows) == 0 {
+			b.WriteString("_none_\n")
+			continue
+		}
+		fmt.Fprintf(&b, "| %s |\n|%s\n", strings.Join(t.Columns, " | "), strings.Repeat(" --- |", len(t.Columns)))
+		for _, row := range t.Rows {
+			cells := make([]string, len(t.Columns))
+			for i, c := range t.Columns {
+				cells[i] = cell(row[c])
+			}
+			fmt.Fprintf(&b, "| %s |\n", strings.Join(cells, " | "))
+		}
+	}
+	return b.String()
+}
+
+// cell formats a value for Markdown: many2one pairs show their name, false
+// (Odoo's empty) shows blank.
+func cell(v any) string {
+	switch t := v.(type) {
+	case nil, bool:
+		if t == true {
+			return "yes"
+		}
+		return ""
+	case []any:
+		if len(t) == 2 {
+			if name, ok := t[1].(string); ok {
+				if name == "" {
+					return cell(t[0])
+				}
+				return name
+			}
+		}
+		parts := make([]string, len(t))
+		for i, x := range t {
+			parts[i] = cell(x)
+		}
+		return strings.Join(parts, ", ")
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	}
+	return strings.ReplaceAll(fmt.Sprint(v), "|", "\\|")
+}
@@ -0,0 +1,131 @@
+package resources
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mcp-bedrock-go/internal/logging"
+)
+
+var logger = logging.For("resources")
+
+// Subscriptions tracks which sessions watch which resource URIs and polls
+// Odoo for changes, sending notifications/resources/updated when the
+// rendered record changes.
+type Subscriptions struct {
+	reg *Registry
+	// Allow, when set, vets each subscription as a read of the URI would be
+	// (authorization); ctx carries the caller's principal.
+	Allow func(ctx context.Context, uri string) error
+
+	mu   sync.Mutex
+	subs map[string]map[string]bool // uri → session IDs
+	last map[string][32]byte        // uri → hash of the last rendering
+}
+
+// NewSubscriptions creates an empty subscription set over reg.
+func NewSubscriptions(reg *Registry) *Subscriptions {
+	return &Subscriptions{reg: reg, subs: map[string]map[string]bool{}, last: map[string][32]byte{}}
+}
+
+// Subscribe implements transport.Subscriber.
+func (s *Subscriptions) Subscribe(ctx context.Context, sessionID, uri string) error {
+	if s.reg.Models(uri) == nil {
+		return fmt.Errorf("no resource template matches %s", uri)
+	}
+	if s.Allow != nil {
+		if err := s.Allow(ctx, uri); err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.subs[uri] == nil {
+		s.subs[uri] = map[string]bool{}
+	}
+	s.subs[uri][sessionID] = true
+	logger.DebugCtxf(ctx, "resources: session %s subscribed to %s", sessionID, uri)
+	return nil
+}
+
+// Unsubscribe implements transport.Subscriber.
+func (s *Subscriptions) Unsubscribe(sessionID, uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.drop(sessionID, uri)
+}
+
+func (s *Subscriptions) drop(sessionID, uri string) {
+	delete(s.subs[uri], sessionID)
+	if len(s.subs[uri]) == 0 {
+		delete(s.subs, uri)
+		delete(s.last, uri)
+	}
+}
+
+// Hooks forgets a session's subscriptions when it ends.
+func (s *Subscriptions) Hooks(h *server.Hooks) {
+	h.AddOnUnregisterSession(func(ctx context.Context, sess server.ClientSession) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for uri := range s.subs {
+			s.drop(sess.SessionID(), uri)
+		}
+	})
+}
+
+// Run polls the subscribed resources every interval until ctx is cancelled.
+func (s *Subscriptions) Run(ctx context.Context, srv *server.MCPServer, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			s.poll(ctx, srv)
+		}
+	}
+}
+
+func (s *Subscriptions) poll(ctx context.Context, srv *server.MCPServer) {
+	s.mu.Lock()
+	uris := make([]string, 0, len(s.subs))
+	for uri := range s.subs {
+		uris = append(uris, uri)
+	}
+	s.mu.Unlock()
+
+	for _, uri := range uris {
+		text, _, err := s.reg.Read(ctx, uri)
+		if err != nil {
+			logger.DebugCtxf(ctx, "resources: poll %s: %v", uri, err)
+			continue
+		}
+		sum := sha256.Sum256([]byte(text))
+
+		s.mu.Lock()
+		prev, seen := s.last[uri]
+		s.last[uri] = sum
+		var sessions []string
+		if seen && prev != sum {
+			for id := range s.subs[uri] {
+				sessions = append(sessions, id)
+			}
+		}
+		s.mu.Unlock()
+
+		for _, id := range sessions {
+			err := srv.SendNotificationToSpecificClient(id, string(mcp.MethodNotificationResourceUpdated), map[string]any{"uri": uri})
+			if err != nil {
+				logger.DebugCtxf(ctx, "resources: notify %s about %s: %v", id, uri, err)
+			}
+		}
+	}
+}
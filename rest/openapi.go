@@ -0,0 +1,198 @@
+package rest
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"mcp-bedrock-go/idempotency"
+	"mcp-bedrock-go/tools"
+)
+
+// openAPI serves the OpenAPI 3 document of the API.
+func (a *API) openAPI(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, a.Document())
+}
+
+// Document builds the OpenAPI 3 description of the tools registered on the
+// server, from the same schemas MCP clients see.
+func (a *API) Document() map[string]any {
+	paths := map[string]any{
+		Prefix + "/tools": map[string]any{
+			"get": map[string]any{
+				"operationId": "list_tools",
+				"summary":     "List the tools the caller may use",
+				"tags":        []string{"tools"},
+				"responses": map[string]any{
+					"200": map[string]any{"description": "MCP tools/list result", "content": jsonContent(map[string]any{"type": "object"})},
+				},
+			},
+		},
+	}
+	add := func(path, method string, op map[string]any) {
+		item, _ := paths[path].(map[string]any)
+		if item == nil {
+			item = map[string]any{}
+			paths[path] = item
+		}
+		item[strings.ToLower(method)] = op
+	}
+
+	for _, name := range a.toolNames() {
+		st := a.s.GetTool(name)
+		if st == nil {
+			continue
+		}
+		tool := st.Tool
+		add(Prefix+"/tools/"+name, http.MethodPost, operation(tool, "call_"+name, "tools", http.MethodPost, nil))
+		for i, rt := range tools.Routes(name) {
+			id := name
+			if i > 0 {
+				id = name + "_" + strings.ToLower(rt.Method) + strings.NewReplacer("/", "_", "{", "", "}", "").Replace(rt.Path)
+			}
+			add(Prefix+rt.Path, rt.Method, operation(tool, id, resourceTag(rt.Path), rt.Method, tools.PathParams(rt.Path)))
+		}
+	}
+
+	doc := map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":       a.opts.Title,
+			"version":     a.opts.Version,
+			"description": "HTTP access to the MCP tools. Every call runs through the same authorization, validation and approval checks as MCP tool calls.",
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": map[string]any{
+				"ToolResult": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"tool":   map[string]any{"type": "string"},
+						"result": map[string]any{"description": "Tool output; JSON when the tool returns JSON, else text"},
+						"meta":   map[string]any{"type": "object", "description": "Result metadata such as the model that answered"},
+					},
+				},
+				"ToolError": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"tool":  map[string]any{"type": "string"},
+						"error": map[string]any{"description": "Error message or JSON detail"},
+					},
+				},
+				"ValidationError": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"tool":  map[string]any{"type": "string"},
+						"error": map[string]any{"type": "string", "example": "invalid arguments"},
+						"errors": map[string]any{
+							"type": "array",
+							"items": map[string]any{
+								"type": "object",
+								"properties": map[string]any{
+									"field":   map[string]any{"type": "string"},
+									"code":    map[string]any{"type": "string", "enum": []string{"required", "type", "enum", "minimum", "maximum", "length", "pattern", "format"}},
+									"message": map[string]any{"type": "string"},
+									"value":   map[string]any{},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if a.opts.Auth {
+		comps := doc["components"].(map[string]any)
+		comps["securitySchemes"] = map[string]any{
+			"bearer": map[string]any{"type": "http", "scheme": "bearer"},
+			"apiKey": map[string]any{"type": "apiKey", "in": "header", "name": "X-API-Key"},
+		}
+		doc["security"] = []any{map[string]any{"bearer": []string{}}, map[string]any{"apiKey": []string{}}}
+	}
+	return doc
+}
+
+// operation describes one endpoint of a tool. Path parameters come from the
+// route; on GET the other arguments are query parameters, on POST a JSON body.
+func operation(tool mcp.Tool, id, tag, method string, pathParams []string) map[string]any {
+	required := map[string]bool{}
+	for _, r := range tool.InputSchema.Required {
+		required[r] = true
+	}
+	inPath := map[string]bool{}
+	var params []any
+	for _, p := range pathParams {
+		inPath[p] = true
+		params = append(params, map[string]any{"name": p, "in": "path", "required": true, "schema": tool.InputSchema.Properties[p]})
+	}
+
+	names := make([]string, 0, len(tool.InputSchema.Properties))
+	for name := range tool.InputSchema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	body := map[string]any{}
+	var bodyRequired []string
+	for _, name := range names {
+		prop := tool.InputSchema.Properties[name]
+		if inPath[name] {
+			continue
+		}
+		if method == http.MethodGet {
+			params = append(params, map[string]any{"name": name, "in": "query", "required": required[name], "schema": prop})
+			continue
+		}
+		body[name] = prop
+		if required[name] {
+			bodyRequired = append(bodyRequired, name)
+		}
+	}
+
+	op := map[string]any{
+		"operationId": id,
+		"summary":     tool.Description,
+		"tags":        []string{tag},
+		"responses": map[string]any{
+			"200": map[string]any{"description": "Tool result", "content": jsonContent(ref("ToolResult"))},
+			"202": map[string]any{"description": "Queued for approval", "content": jsonContent(ref("ToolResult"))},
+			"400": map[string]any{"description": "Invalid arguments", "content": jsonContent(ref("ValidationError"))},
+			"401": map[string]any{"description": "Missing or invalid credentials"},
+			"404": map[string]any{"description": "Unknown tool"},
+			"422": map[string]any{"description": "The tool reported an error", "content": jsonContent(ref("ToolError"))},
+		},
+	}
+	if _, ok := tool.InputSchema.Properties[idempotency.Arg]; ok {
+		params = append(params, map[string]any{
+			"name": "Idempotency-Key", "in": "header", "required": false,
+			"description": "Same as the idempotency_key argument: a retry with the same key returns the first result",
+			"schema":      map[string]any{"type": "string", "maxLength": 200},
+		})
+	}
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+	if method == http.MethodPost {
+		schema := map[string]any{"type": "object", "properties": body}
+		if len(bodyRequired) > 0 {
+			schema["required"] = bodyRequired
+		}
+		op["requestBody"] = map[string]any{"required": len(bodyRequired) > 0, "content": jsonContent(schema)}
+	}
+	return op
+}
+
+// resourceTag groups routes by their first path segment (orders, products).
+func resourceTag(path string) string {
+	seg, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	return seg
+}
+
+func jsonContent(schema any) map[string]any {
+	return map[string]any{"application/json": map[string]any{"schema": schema}}
+}
+
+func ref(name string) map[string]any {
+	return map[string]any{"$ref": "#/components/schemas/" + name}
+}
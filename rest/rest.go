@@ -0,0 +1,228 @@
+// Package rest exposes the MCP tools as a plain HTTP/JSON API for dashboards
+// and scripts that cannot speak MCP: POST /api/v1/tools/{name} for every
+// tool, resource-style routes declared next to each tool (GET
+// /api/v1/orders/{mo_id}/risk) and a generated OpenAPI 3 document. Calls go
+// through the MCP server itself, so authorization, validation, approvals and
+// the other tool middleware apply exactly as for MCP clients.
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mcp-bedrock-go/approval"
+	"mcp-bedrock-go/idempotency"
+	"mcp-bedrock-go/internal/logging"
+	"mcp-bedrock-go/tools"
+)
+
+var logger = logging.For("rest")
+
+// Prefix is where the API is mounted.
+const Prefix = "/api/v1"
+
+// Options describe the API in the OpenAPI document.
+type Options struct {
+	Title   string
+	Version string
+	Auth    bool // document the bearer and X-API-Key security schemes
+}
+
+// API serves the REST facade of one MCP server.
+type API struct {
+	s    *server.MCPServer
+	opts Options
+	ids  atomic.Int64
+}
+
+// Mount registers the API routes on mux for the tools registered on s.
+func Mount(mux *http.ServeMux, s *server.MCPServer, opts Options) *API {
+	a := &API{s: s, opts: opts}
+	mux.HandleFunc("GET "+Prefix+"/openapi.json", a.openAPI)
+	mux.HandleFunc("GET "+Prefix+"/tools", a.listTools)
+	mux.HandleFunc("POST "+Prefix+"/tools/{name}", func(w http.ResponseWriter, r *http.Request) {
+		a.call(w, r, r.PathValue("name"), nil)
+	})
+	for _, name := range a.toolNames() {
+		for _, rt := range tools.Routes(name) {
+			params := tools.PathParams(rt.Path)
+			mux.HandleFunc(rt.Method+" "+Prefix+rt.Path, func(w http.ResponseWriter, r *http.Request) {
+				path := map[string]string{}
+				for _, p := range params {
+					path[p] = r.PathValue(p)
+				}
+				a.call(w, r, name, path)
+			})
+		}
+	}
+	return a
+}
+
+// toolNames lists the tools registered on the server, sorted.
+func (a *API) toolNames() []string {
+	var out []string
+	for name := range a.s.ListTools() {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// listTools returns the tools the caller may use, as MCP tools/list does.
+func (a *API) listTools(w http.ResponseWriter, r *http.Request) {
+	resp := a.rpc(r.Context(), mcp.MethodToolsList, map[string]any{})
+	switch m := resp.(type) {
+	case mcp.JSONRPCResponse:
+		writeJSON(w, http.StatusOK, m.Result)
+	default:
+		writeRPCError(w, resp)
+	}
+}
+
+// call collects the arguments of a tool call from the JSON body, the query
+// string and the path, in increasing precedence, and runs the tool. An
+// Idempotency-Key header stands in for an idempotency_key argument.
+func (a *API) call(w http.ResponseWriter, r *http.Request, name string, path map[string]string) {
+	args := map[string]any{}
+	if r.Method == http.MethodPost && r.ContentLength != 0 {
+		dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+		dec.UseNumber()
+		if err := dec.Decode(&args); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": fmt.Sprintf("request body must be a JSON object: %v", err)})
+			return
+		}
+	}
+	for k, v := range queryArgs(r.URL.Query()) {
+		args[k] = v
+	}
+	for k, v := range path {
+		args[k] = v
+	}
+	if key := r.Header.Get("Idempotency-Key"); key != "" && tools.Idempotent(name) {
+		if _, set := args[idempotency.Arg]; !set {
+			args[idempotency.Arg] = key
+		}
+	}
+
+	resp := a.rpc(r.Context(), mcp.MethodToolsCall, map[string]any{"name": name, "arguments": args})
+	m, ok := resp.(mcp.JSONRPCResponse)
+	if !ok {
+		writeRPCError(w, resp)
+		return
+	}
+	var res mcp.CallToolResult
+	switch v := m.Result.(type) {
+	case mcp.CallToolResult:
+		res = v
+	case *mcp.CallToolResult:
+		res = *v
+	}
+	status, body := toolResponse(name, &res)
+	writeJSON(w, status, body)
+}
+
+// rpc sends one JSON-RPC request through the MCP server.
+func (a *API) rpc(ctx context.Context, method mcp.MCPMethod, params any) mcp.JSONRPCMessage {
+	msg, _ := json.Marshal(map[string]any{
+		"jsonrpc": mcp.JSONRPC_VERSION,
+		"id":      a.ids.Add(1),
+		"method":  method,
+		"params":  params,
+	})
+	return a.s.HandleMessage(ctx, msg)
+}
+
+// queryArgs turns query parameters into tool arguments; repeated parameters
+// become lists. Validation coerces the strings to the declared types.
+func queryArgs(q url.Values) map[string]any {
+	out := map[string]any{}
+	for k, vs := range q {
+		if len(vs) == 1 {
+			out[k] = vs[0]
+			continue
+		}
+		list := make([]any, len(vs))
+		for i, v := range vs {
+			list[i] = v
+		}
+		out[k] = list
+	}
+	return out
+}
+
+// toolResponse maps a tool result to an HTTP status and body. A result
+// whose text is JSON is returned as JSON; invalid arguments are 400, queued
+// approvals 202 and other tool errors 422.
+func toolResponse(name string, res *mcp.CallToolResult) (int, map[string]any) {
+	var texts []string
+	for _, c := range res.Content {
+		if tc, ok := c.(mcp.TextContent); ok {
+			texts = append(texts, tc.Text)
+		}
+	}
+	var payload any = strings.Join(texts, "\n")
+	if len(texts) > 0 {
+		var v any
+		if err := json.Unmarshal([]byte(texts[0]), &v); err == nil {
+			payload = v // trailing lines (model, conversation) are also in meta
+		}
+	}
+
+	body := map[string]any{"tool": name}
+	if res.Meta != nil && len(res.Meta.AdditionalFields) > 0 {
+		body["meta"] = res.Meta.AdditionalFields
+	}
+	obj, _ := payload.(map[string]any)
+	pending := obj != nil && obj["status"] == string(approval.Pending)
+	if sc, ok := res.StructuredContent.(map[string]any); ok {
+		// the text may be in another output_format
+		pending = sc["status"] == string(approval.Pending)
+	}
+	switch {
+	case res.IsError && obj != nil && obj["error"] == "invalid arguments":
+		body["error"] = obj["error"]
+		body["errors"] = obj["errors"]
+		return http.StatusBadRequest, body
+	case res.IsError:
+		body["error"] = payload
+		return http.StatusUnprocessableEntity, body
+	case pending:
+		body["result"] = payload
+		return http.StatusAccepted, body
+	}
+	body["result"] = payload
+	return http.StatusOK, body
+}
+
+// writeRPCError reports a JSON-RPC level failure: an unknown tool is 404,
+// anything else 500.
+func writeRPCError(w http.ResponseWriter, resp mcp.JSONRPCMessage) {
+	e, ok := resp.(mcp.JSONRPCError)
+	if !ok {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "unexpected response from the MCP server"})
+		return
+	}
+	status := http.StatusInternalServerError
+	if strings.Contains(e.Error.Message, "not found") {
+		status = http.StatusNotFound
+	}
+	logger.Debugf("rest: JSON-RPC error %d: %s", e.Error.Code, e.Error.Message)
+	writeJSON(w, status, map[string]any{"error": e.Error.Message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
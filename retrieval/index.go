@@ -0,0 +1,243 @@
+package retrieval
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"mcp-bedrock-go/internal/logging"
+)
+
+var logger = logging.For("retrieval")
+
+// Embedder turns texts into vectors. Implementations are optional; without
+// one the index ranks with BM25 only.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// Hit is a ranked passage.
+type Hit struct {
+	Passage
+	Score float64 `json:"score"`
+}
+
+// BM25 tuning constants.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Index is an in-memory BM25 index over passages with optional embeddings.
+type Index struct {
+	Dir      string
+	embedder Embedder
+
+	mu       sync.RWMutex
+	passages []Passage
+	terms    []map[string]int // term frequencies per passage
+	lengths  []int
+	avgLen   float64
+	df       map[string]int
+	vectors  [][]float32
+}
+
+// NewIndex creates an empty index for dir. embedder may be nil.
+func NewIndex(dir string, embedder Embedder) *Index {
+	return &Index{Dir: dir, embedder: embedder, df: map[string]int{}}
+}
+
+// Load (re)reads the folder and rebuilds the index.
+func (ix *Index) Load(ctx context.Context) error {
+	ps, err := LoadDir(ix.Dir)
+	if err != nil {
+		return err
+	}
+
+	terms := make([]map[string]int, len(ps))
+	lengths := make([]int, len(ps))
+	df := map[string]int{}
+	total := 0
+	for i, p := range ps {
+		tf := map[string]int{}
+		toks := Tokenize(p.Heading + " " + p.Text)
+		for _, t := range toks {
+			tf[t]++
+		}
+		for t := range tf {
+			df[t]++
+		}
+		terms[i] = tf
+		lengths[i] = len(toks)
+		total += len(toks)
+	}
+
+	var vectors [][]float32
+	if ix.embedder != nil && len(ps) > 0 {
+		texts := make([]string, len(ps))
+		for i, p := range ps {
+			texts[i] = p.Heading + "\n" + p.Text
+		}
+		vectors, err = ix.embedder.Embed(ctx, texts)
+		if err != nil {
+			// Embeddings are an enhancement; keep serving BM25 results.
+			logger.ErrorCtxf(ctx, "retrieval: embedding %d passages failed, using BM25 only: %v", len(ps), err)
+			vectors = nil
+		}
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.passages = ps
+	ix.terms = terms
+	ix.lengths = lengths
+	ix.df = df
+	ix.vectors = vectors
+	ix.avgLen = 0
+	if len(ps) > 0 {
+		ix.avgLen = float64(total) / float64(len(ps))
+	}
+	logger.InfoCtxf(ctx, "retrieval: indexed %d passages from %s", len(ps), ix.Dir)
+	return nil
+}
+
+// Len returns the number of indexed passages.
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.passages)
+}
+
+// Search returns the k best passages for query. With embeddings available the
+// BM25 and cosine scores are normalised and blended. The query is embedded
+// before taking the lock, so a slow Bedrock call never holds up a reload.
+func (ix *Index) Search(ctx context.Context, query string, k int) ([]Hit, error) {
+	if k <= 0 {
+		k = 5
+	}
+	ix.mu.RLock()
+	empty, hybrid := len(ix.passages) == 0, len(ix.vectors) > 0
+	ix.mu.RUnlock()
+	if empty {
+		return nil, nil
+	}
+	var qv []float32
+	if ix.embedder != nil && hybrid {
+		v, err := ix.embedder.Embed(ctx, []string{query})
+		if err != nil || len(v) == 0 {
+			logger.ErrorCtxf(ctx, "retrieval: query embedding failed, using BM25 only: %v", err)
+		} else {
+			qv = v[0]
+		}
+	}
+
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	scores := ix.bm25(Tokenize(query))
+	if qv != nil && len(ix.vectors) == len(ix.passages) {
+		normalize(scores)
+		for i, v := range ix.vectors {
+			scores[i] = 0.5*scores[i] + 0.5*Cosine(qv, v)
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for i, s := range scores {
+		if s > 0 {
+			hits = append(hits, Hit{Passage: ix.passages[i], Score: s})
+		}
+	}
+	sort.SliceStable(hits, func(a, b int) bool { return hits[a].Score > hits[b].Score })
+	if len(hits) > k {
+		hits = hits[:k]
+	}
+	return hits, nil
+}
+
+func (ix *Index) bm25(query []string) []float64 {
+	n := float64(len(ix.passages))
+	scores := make([]float64, len(ix.passages))
+	for _, q := range query {
+		df := float64(ix.df[q])
+		if df == 0 {
+			continue
+		}
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for i, tf := range ix.terms {
+			f := float64(tf[q])
+			if f == 0 {
+				continue
+			}
+			norm := 1 - bm25B + bm25B*float64(ix.lengths[i])/ix.avgLen
+			scores[i] += idf * f * (bm25K1 + 1) / (f + bm25K1*norm)
+		}
+	}
+	return scores
+}
+
+// Tokenize lowercases text and splits it on anything that is not a letter or
+// digit. Hyphenated product codes such as RUSH-TEA also yield the joined form.
+func Tokenize(s string) []string {
+	var out []string
+	for _, f := range strings.Fields(strings.ToLower(s)) {
+		parts := strings.FieldsFunc(f, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		out = append(out, parts...)
+		if len(parts) > 1 {
+			out = append(out, strings.Join(parts, "-"))
+		}
+	}
+	return out
+}
+
+// FormatContext renders hits as numbered passages for a prompt; the model is
+// asked to cite them as [n].
+func FormatContext(hits []Hit) string {
+	if len(hits) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("Plant documents (cite as [n] when used):\n")
+	for i, h := range hits {
+		fmt.Fprintf(&b, "[%d] %s\n%s\n\n", i+1, h.Citation(), h.Text)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func normalize(s []float64) {
+	max := 0.0
+	for _, v := range s {
+		if v > max {
+			max = v
+		}
+	}
+	if max == 0 {
+		return
+	}
+	for i := range s {
+		s[i] /= max
+	}
+}
+
+// Cosine is the cosine similarity of two embeddings, 0 when their lengths
+// differ or either is zero.
+func Cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
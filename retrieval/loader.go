@@ -0,0 +1,132 @@
+// Package retrieval indexes local plant documents (SOPs, work instructions,
+// quality specs) so LLM tools can cite them.
+package retrieval
+
+import (
+	"bufio"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Passage is a chunk of a document small enough to put into a prompt.
+type Passage struct {
+	ID        int    `json:"id"`
+	Source    string `json:"source"`            // path relative to the docs folder
+	Heading   string `json:"heading,omitempty"` // nearest Markdown heading
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+	Text      string `json:"text"`
+}
+
+// Citation renders a short reference such as "sop/changeover.md#Print station (L12-30)".
+func (p Passage) Citation() string {
+	c := p.Source
+	if p.Heading != "" {
+		c += "#" + p.Heading
+	}
+	return c + " (L" + strconv.Itoa(p.StartLine) + "-" + strconv.Itoa(p.EndLine) + ")"
+}
+
+// supported lists the file extensions the loader reads. PDFs are expected to
+// be converted to .txt beforehand.
+var supported = map[string]bool{".md": true, ".markdown": true, ".txt": true}
+
+// maxPassageChars bounds the size of a single passage.
+const maxPassageChars = 1200
+
+// LoadDir walks dir and splits every supported file into passages.
+func LoadDir(dir string) ([]Passage, error) {
+	var out []Passage
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !supported[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+		rel, _ := filepath.Rel(dir, path)
+		ps, err := loadFile(path, filepath.ToSlash(rel))
+		if err != nil {
+			return err
+		}
+		out = append(out, ps...)
+		return nil
+	})
+	for i := range out {
+		out[i].ID = i
+	}
+	return out, err
+}
+
+// loadFile chunks one file on blank lines, and Markdown files also on
+// headings, keeping line numbers for citations.
+func loadFile(path, rel string) ([]Passage, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	ext := strings.ToLower(filepath.Ext(path))
+	markdown := ext == ".md" || ext == ".markdown"
+
+	var (
+		out     []Passage
+		heading string
+		buf     []string
+		start   int
+		line    int
+	)
+	flush := func(end int) {
+		text := strings.TrimSpace(strings.Join(buf, "\n"))
+		if text != "" {
+			out = append(out, Passage{Source: rel, Heading: heading, StartLine: start, EndLine: end, Text: text})
+		}
+		buf = buf[:0]
+		start = 0
+	}
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	size := 0
+	for sc.Scan() {
+		line++
+		txt := sc.Text()
+		trimmed := strings.TrimSpace(txt)
+
+		if markdown && isHeading(trimmed) {
+			flush(line - 1)
+			size = 0
+			heading = strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+			continue
+		}
+		if trimmed == "" && size >= maxPassageChars/2 {
+			flush(line - 1)
+			size = 0
+			continue
+		}
+		if start == 0 && trimmed == "" {
+			continue
+		}
+		if start == 0 {
+			start = line
+		}
+		buf = append(buf, txt)
+		size += len(txt)
+		if size >= maxPassageChars {
+			flush(line)
+			size = 0
+		}
+	}
+	flush(line)
+	return out, sc.Err()
+}
+
+// isHeading reports whether a trimmed Markdown line is an ATX heading: one
+// to six #, then a space or the end of the line.
+func isHeading(line string) bool {
+	n := len(line) - len(strings.TrimLeft(line, "#"))
+	return n >= 1 && n <= 6 && (len(line) == n || line[n] == ' ' || line[n] == '\t')
+}
@@ -4,43 +4,84 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"net/http"
 
 	"github.com/mark3labs/mcp-go/mcp"
 
 	odoolib "mcp-bedrock-go/odoo"
 )
 
-// Input schema (strings accepted for simplicity):
+func init() {
+	Register(Def[AddProductInput]{
+		Tool: mcp.NewTool("add_product",
+			mcp.WithDescription("Add product"),
+			mcp.WithString("name", mcp.Required()),
+			mcp.WithString("default_code", mcp.Description("Product code/SKU")),
+			mcp.WithString("type", mcp.Enum("product", "consu", "service"), mcp.DefaultString("product")),
+			mcp.WithNumber("list_price", mcp.Min(0)),
+			withDryRun(),
+			withIdempotencyKey(),
+			mcp.WithOutputSchema[AddProductOutput]()),
+		Routes: []Route{{Method: http.MethodPost, Path: "/products"}},
+		Handler: func(d Deps) mcp.TypedToolHandlerFunc[AddProductInput] {
+			return AddProduct(d.Odoo, d.Writes)
+		},
+	})
+}
+
+// AddProductInput are the arguments of add_product.
+type AddProductInput struct {
+	Name           string  `json:"name"`
+	DefaultCode    string  `json:"default_code"`
+	Type           string  `json:"type"`
+	ListPrice      float64 `json:"list_price"`
+	DryRun         bool    `json:"dry_run"`
+	IdempotencyKey string  `json:"idempotency_key"`
+}
+
+// AddProductOutput is the created product, with dry_run what would be sent,
+// or the pending approval.
+type AddProductOutput struct {
+	ID      int    `json:"id,omitempty"`
+	Message string `json:"message,omitempty"`
+	DryRunOutput
+	PendingOutput
+}
+
+// Input schema:
 // - `name` (required) product name
 // - `default_code` (optional) product code/SKU
-// - `type` (optional) "product" or "service"
-// - `list_price` (optional) string numeric price
+// - `type` (optional) "product" (default), "consu" or "service"
+// - `list_price` (optional) numeric price
+// - `dry_run` (optional) validate and return the vals without creating
+// - `idempotency_key` (optional) a retry with the same key returns the first result
 // Output: JSON {"id": <created_id>} or friendly error
-func AddProduct(oclient *odoolib.Client) func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		name, err := req.RequireString("name")
-		if err != nil {
-			return mcp.NewToolResultError("'name' is required"), nil
+func AddProduct(oclient *odoolib.Client, wopts WriteOptions) mcp.TypedToolHandlerFunc[AddProductInput] {
+	return func(ctx context.Context, req mcp.CallToolRequest, in AddProductInput) (*mcp.CallToolResult, error) {
+		code := in.DefaultCode
+		vals := map[string]any{"name": in.Name, "default_code": code, "type": in.Type}
+		if in.ListPrice > 0 {
+			vals["list_price"] = in.ListPrice
 		}
-		code := req.GetString("default_code", "")
-		ptype := req.GetString("type", "product")
-		price := req.GetString("list_price", "0")
-
-		vals := map[string]any{"name": name, "default_code": code, "type": ptype}
-		// Try to parse price into float if provided
-		if price != "" && price != "0" {
-			vals["list_price"] = price
+
+		// Non-fatal findings, reported in dry-run mode
+		var warnings []string
+		if code == "" {
+			warnings = append(warnings, "no default_code; the product cannot be referenced by code in create_mo")
+		} else if dup, err := oclient.SearchReadContext(ctx, "product.product", []string{"id", "name"}, []any{[]any{"default_code", "=", code}}); err == nil && len(dup) > 0 {
+			warnings = append(warnings, fmt.Sprintf("default_code %s is already used by %v (id %v)", code, dup[0]["name"], dup[0]["id"]))
+		}
+
+		if wopts.dryRun(in.DryRun) {
+			return dryRunResult("product.product", vals, warnings, nil), nil
 		}
 
-		id, err := oclient.Create("product.product", vals)
+		id, err := oclient.CreateContext(ctx, "product.product", vals)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Odoo create error: %v", err)), nil
 		}
 
-		resp := map[string]any{"id": id}
-		b, _ := json.MarshalIndent(resp, "", "  ")
-		return mcp.NewToolResultText(string(b)), nil
+		return createdResult(map[string]any{"id": id}, "product.product", id, in.Name), nil
 	}
 }
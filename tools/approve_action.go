@@ -0,0 +1,56 @@
+// Tool: ApproveAction
+// คำอธิบาย (ไทย): อนุมัติคำสั่งเขียนข้อมูลที่รออนุมัติ (เช่น สร้าง MO จำนวนมาก) แล้วดำเนินการจริงบน Odoo
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"mcp-bedrock-go/approval"
+)
+
+func init() {
+	Register(Def[ActionInput]{
+		Tool: mcp.NewTool("approve_action",
+			mcp.WithDescription("Approve and run a pending write action"),
+			mcp.WithString("action_id", mcp.Required()),
+			mcp.WithOutputSchema[map[string]any]()),
+		Needs:  func(d Deps) bool { return d.Approvals != nil },
+		Routes: []Route{{Method: http.MethodPost, Path: "/actions/{action_id}/approve"}},
+		Handler: func(d Deps) mcp.TypedToolHandlerFunc[ActionInput] {
+			return ApproveAction(d.Approvals)
+		},
+	})
+}
+
+// ActionInput are the arguments of approve_action.
+type ActionInput struct {
+	ActionID string `json:"action_id"`
+}
+
+// Input: action_id (string, required)
+// Output: the result of the approved tool call (its structuredContent too),
+// prefixed with who approved it
+func ApproveAction(q *approval.Queue) mcp.TypedToolHandlerFunc[ActionInput] {
+	return func(ctx context.Context, req mcp.CallToolRequest, in ActionInput) (*mcp.CallToolResult, error) {
+		id := in.ActionID
+		a, res, err := q.Approve(ctx, id)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if res == nil {
+			return mcp.NewToolResultError(fmt.Sprintf("action %s approved but returned no result", id)), nil
+		}
+		note := mcp.NewTextContent(fmt.Sprintf("approved action %s (%s) by %s", a.ID, a.Summary, a.DecidedBy))
+		res.Content = append([]mcp.Content{note}, res.Content...)
+		if res.Meta == nil {
+			res.Meta = mcp.NewMetaFromMap(map[string]any{})
+		}
+		res.Meta.AdditionalFields["approved_action"] = a.ID
+		res.Meta.AdditionalFields["requester"] = a.Requester
+		return res, nil
+	}
+}
@@ -4,21 +4,48 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 
+	"mcp-bedrock-go/format"
 	odoolib "mcp-bedrock-go/odoo"
 )
 
+func init() {
+	Register(Def[CapacityCheckInput]{
+		Tool: mcp.NewTool("capacity_check",
+			mcp.WithDescription("Check capacity"),
+			withInteger("workcenter_id", mcp.Description("Work center to check; all when omitted"), mcp.Min(1)),
+			withDate("date", mcp.Description("Day to check (YYYY-MM-DD); defaults to today")),
+			mcp.WithOutputSchema[CapacityCheckOutput]()),
+		Routes: []Route{{Method: http.MethodGet, Path: "/capacity"}, {Method: http.MethodGet, Path: "/workcenters/{workcenter_id}/capacity"}},
+		Handler: func(d Deps) mcp.TypedToolHandlerFunc[CapacityCheckInput] {
+			return CapacityCheck(d.Odoo)
+		},
+	})
+}
+
+// CapacityCheckInput are the arguments of capacity_check.
+type CapacityCheckInput struct {
+	WorkcenterID int    `json:"workcenter_id"`
+	Date         string `json:"date"`
+}
+
+// CapacityCheckOutput lists the work orders planned on a day.
+type CapacityCheckOutput struct {
+	Date       string      `json:"date"`
+	Workorders []Workorder `json:"workorders"`
+}
+
 // Input: workcenter_id (int, optional), date (string, optional, YYYY-MM-DD)
 // Output: JSON summary of capacity usage
-func CapacityCheck(oclient *odoolib.Client) func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		wcID := req.GetInt("workcenter_id", 0)
-		dateStr := req.GetString("date", "")
+func CapacityCheck(oclient *odoolib.Client) mcp.TypedToolHandlerFunc[CapacityCheckInput] {
+	return func(ctx context.Context, req mcp.CallToolRequest, in CapacityCheckInput) (*mcp.CallToolResult, error) {
+		wcID := in.WorkcenterID
+		dateStr := in.Date
 		if dateStr == "" {
 			dateStr = time.Now().Format("2006-01-02")
 		}
@@ -30,12 +57,11 @@ func CapacityCheck(oclient *odoolib.Client) func(ctx context.Context, req mcp.Ca
 			domain = append(domain, []any{"workcenter_id", "=", wcID})
 		}
 
-		items, err := oclient.SearchRead("mrp.workorder", fields, domain)
+		items, err := oclient.SearchReadContext(ctx, "mrp.workorder", fields, domain)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Odoo error: %v", err)), nil
 		}
 
-		b, _ := json.MarshalIndent(map[string]any{"date": dateStr, "workorders": items}, "", "  ")
-		return mcp.NewToolResultText(string(b)), nil
+		return format.Result(map[string]any{"date": dateStr, "workorders": items}), nil
 	}
 }
@@ -6,53 +6,130 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"strconv"
+	"net/http"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 
 	odoolib "mcp-bedrock-go/odoo"
+	"mcp-bedrock-go/productsearch"
 )
 
+func init() {
+	Register(Def[CreateMOInput]{
+		Tool: mcp.NewTool("create_mo",
+			mcp.WithDescription("Create manufacturing order"),
+			mcp.WithString("product_code", mcp.Description("Product default_code")),
+			withInteger("product_id", mcp.Min(1)),
+			mcp.WithString("product", mcp.Description("Free-text product description, resolved by fuzzy search")),
+			mcp.WithNumber("qty", mcp.Required(), exclusiveMin(0), mcp.Description("Quantity to produce")),
+			mcp.WithString("name", mcp.Description("MO name; Odoo assigns one when omitted")),
+			mcp.WithString("date_deadline", withFormat("date-time"), mcp.Description("Planned start, a date or date and time")),
+			withDryRun(),
+			withIdempotencyKey(),
+			mcp.WithOutputSchema[CreateMOOutput]()),
+		Routes: []Route{{Method: http.MethodPost, Path: "/orders"}},
+		Handler: func(d Deps) mcp.TypedToolHandlerFunc[CreateMOInput] {
+			return CreateMO(d.Odoo, d.Products, d.Writes)
+		},
+	})
+}
+
+// CreateMOInput are the arguments of create_mo.
+type CreateMOInput struct {
+	ProductCode    string  `json:"product_code"`
+	ProductID      int     `json:"product_id"`
+	Product        string  `json:"product"`
+	Qty            float64 `json:"qty"`
+	Name           string  `json:"name"`
+	DateDeadline   string  `json:"date_deadline"`
+	DryRun         bool    `json:"dry_run"`
+	IdempotencyKey string  `json:"idempotency_key"`
+}
+
+// CreateMOOutput is the created order, with dry_run what would be sent, or
+// the pending approval.
+type CreateMOOutput struct {
+	MOID      int    `json:"mo_id,omitempty"`
+	Name      string `json:"name,omitempty" jsonschema_description:"Reference Odoo assigned, e.g. WH/MO/00123"`
+	ProductID int    `json:"product_id,omitempty"`
+	Product   any    `json:"product,omitempty" jsonschema_description:"Name of the product"`
+	Message   string `json:"message"`
+	DryRunOutput
+	PendingOutput
+}
+
 // CreateMO tool
-// Input (strings):
-// - `product_code` (default_code) OR `product_id` (string id)
-// - `qty` (required) quantity to produce, string/number
+// Input:
+// - `product_code` (default_code) OR `product_id` (int), matched exactly, OR `product` (free text, fuzzy matched)
+// - `qty` (required) quantity to produce
 // - `name` (optional) MO name
 // - `date_deadline` (optional)
-// Output: JSON {"mo_id": <id>, "message": "..."}
-func CreateMO(oclient *odoolib.Client) func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		// Resolve inputs
-		productCode := req.GetString("product_code", "")
-		productIDStr := req.GetString("product_id", "")
-		qtyStr := req.GetString("qty", "")
-		name := req.GetString("name", "")
-		dateDeadline := req.GetString("date_deadline", "")
-
-		if qtyStr == "" {
-			return mcp.NewToolResultError("'qty' is required"), nil
-		}
+// - `dry_run` (optional) validate and return the vals without creating
+// - `idempotency_key` (optional) a retry with the same key returns the first result
+// Output: JSON {"mo_id": <id>, "name": "WH/MO/...", "message": "..."}
+func CreateMO(oclient *odoolib.Client, products *productsearch.Index, wopts WriteOptions) mcp.TypedToolHandlerFunc[CreateMOInput] {
+	return func(ctx context.Context, req mcp.CallToolRequest, in CreateMOInput) (*mcp.CallToolResult, error) {
+		productCode := in.ProductCode
+		productQuery := in.Product
+		name := in.Name
 
-		qty, err := strconv.ParseFloat(qtyStr, 64)
-		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("invalid qty: %v", err)), nil
-		}
+		qty := in.Qty
 
-		// Find product
-		var productSearchDomain []any
-		if productIDStr != "" {
-			// try direct id
-			productSearchDomain = []any{[]any{"id", "=", productIDStr}}
-		} else if productCode != "" {
-			productSearchDomain = []any{[]any{"default_code", "=", productCode}}
-		} else {
-			return mcp.NewToolResultError("product_code or product_id is required"), nil
+		// Non-fatal findings, returned with the result
+		var warnings []string
+		if qty != float64(int64(qty)) {
+			warnings = append(warnings, fmt.Sprintf("qty %g is not a whole number", qty))
 		}
 
+		// Find product: product_id and product_code must match exactly; only
+		// the free-text product is resolved by fuzzy search
 		prodFields := []string{"id", "product_tmpl_id", "default_code", "name"}
-		prods, err := oclient.SearchRead("product.product", prodFields, productSearchDomain)
-		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Odoo error finding product: %v", err)), nil
+		var (
+			prods []map[string]any
+			err   error
+		)
+		switch {
+		case in.ProductID != 0 || productCode != "":
+			domain := []any{[]any{"default_code", "=", productCode}}
+			if in.ProductID != 0 {
+				domain = []any{[]any{"id", "=", in.ProductID}}
+			}
+			prods, err = oclient.SearchReadContext(ctx, "product.product", prodFields, domain)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Odoo error finding product: %v", err)), nil
+			}
+			if len(prods) == 0 {
+				what := fmt.Sprintf("product_code %q", productCode)
+				if in.ProductID != 0 {
+					what = fmt.Sprintf("product_id %d", in.ProductID)
+				}
+				return productNotFound(ctx, products, what, productCode), nil
+			}
+		case productQuery != "":
+			if products == nil {
+				return mcp.NewToolResultError("product search is disabled; pass product_code or product_id"), nil
+			}
+			cands, err := products.Search(ctx, productQuery, 5)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("product search error: %v", err)), nil
+			}
+			if !productsearch.Confident(cands) {
+				if len(cands) == 0 {
+					return mcp.NewToolResultError("Product not found. Create product first or check code."), nil
+				}
+				return candidatesError("ambiguous product, pass product_id of one candidate", cands), nil
+			}
+			prods, err = oclient.SearchReadContext(ctx, "product.product", prodFields, []any{[]any{"id", "=", cands[0].ID}})
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Odoo error finding product: %v", err)), nil
+			}
+			warnings = append(warnings, fmt.Sprintf("product %q resolved by fuzzy search to %s (score %.2f)", productQuery, cands[0].Name, cands[0].Score))
+		default:
+			return mcp.NewToolResultError("product_code, product_id or product is required"), nil
+		}
+		if len(prods) > 1 {
+			warnings = append(warnings, fmt.Sprintf("%d products match; using the first (id %v)", len(prods), prods[0]["id"]))
 		}
 		if len(prods) == 0 {
 			return mcp.NewToolResultError("Product not found. Create product first or check code."), nil
@@ -98,10 +175,13 @@ func CreateMO(oclient *odoolib.Client) func(ctx context.Context, req mcp.CallToo
 		} else {
 			bomDomain = []any{[]any{"product_tmpl_id", "=", pid}}
 		}
-		boms, _ := oclient.SearchRead("mrp.bom", []string{"id", "product_tmpl_id"}, bomDomain)
+		boms, _ := oclient.SearchReadContext(ctx, "mrp.bom", []string{"id", "product_tmpl_id"}, bomDomain)
 		if len(boms) == 0 {
 			return mcp.NewToolResultError("No BOM found for product. Create BOM before creating MO."), nil
 		}
+		if len(boms) > 1 {
+			warnings = append(warnings, fmt.Sprintf("%d BOMs found for product; Odoo will pick its default", len(boms)))
+		}
 
 		// build create vals for mrp.production
 		vals := map[string]any{
@@ -111,17 +191,63 @@ func CreateMO(oclient *odoolib.Client) func(ctx context.Context, req mcp.CallToo
 		if name != "" {
 			vals["name"] = name
 		}
-		if dateDeadline != "" {
-			vals["date_planned_start"] = dateDeadline
+		if in.DateDeadline != "" {
+			now := time.Now().In(Location)
+			start, _, ok := parseDateTime(in.DateDeadline, now)
+			if !ok {
+				return mcp.NewToolResultError(fmt.Sprintf("date_deadline %q is not a date or date and time", in.DateDeadline)), nil
+			}
+			// a plain date is its midnight at the plant
+			vals["date_planned_start"] = start.UTC().Format(time.DateTime)
+			if day := start.In(Location).Format(time.DateOnly); day < now.Format(time.DateOnly) {
+				warnings = append(warnings, fmt.Sprintf("date_deadline %s is in the past", day))
+			}
+		}
+
+		if wopts.dryRun(in.DryRun) {
+			return dryRunResult("mrp.production", vals, warnings, map[string]any{"product": prod["name"]}), nil
 		}
 
-		moID, err := oclient.Create("mrp.production", vals)
+		moID, err := oclient.CreateContext(ctx, "mrp.production", vals)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Odoo create MO error: %v", err)), nil
 		}
 
-		resp := map[string]any{"mo_id": moID, "message": "Manufacturing Order created"}
-		b, _ := json.MarshalIndent(resp, "", "  ")
-		return mcp.NewToolResultText(string(b)), nil
+		// Odoo assigns the reference (WH/MO/00123) from a sequence on create
+		if recs, err := oclient.SearchReadContext(ctx, "mrp.production", []string{"name"}, []any{[]any{"id", "=", moID}}); err == nil && len(recs) > 0 {
+			if n, ok := recs[0]["name"].(string); ok {
+				name = n
+			}
+		}
+
+		resp := map[string]any{"mo_id": moID, "product_id": pid, "product": prod["name"], "message": "Manufacturing Order created"}
+		if name != "" {
+			resp["name"] = name
+		}
+		if len(warnings) > 0 {
+			resp["warnings"] = warnings
+		}
+		return createdResult(resp, "mrp.production", moID, name), nil
+	}
+}
+
+// productNotFound reports a product_id or product_code without an exact
+// match, with the closest products by code when product search is on. A
+// near miss is never used: a typo must not order a different product.
+func productNotFound(ctx context.Context, products *productsearch.Index, what, code string) *mcp.CallToolResult {
+	msg := what + " not found. Create product first or check code."
+	if products == nil || code == "" {
+		return mcp.NewToolResultError(msg)
+	}
+	cands, err := products.Search(ctx, code, 5)
+	if err != nil || len(cands) == 0 {
+		return mcp.NewToolResultError(msg)
 	}
+	return candidatesError(msg+" Did you mean one of these?", cands)
+}
+
+// candidatesError returns msg and the candidate products as a JSON error.
+func candidatesError(msg string, cands []productsearch.Candidate) *mcp.CallToolResult {
+	b, _ := json.MarshalIndent(map[string]any{"error": msg, "candidates": cands}, "", "  ")
+	return mcp.NewToolResultError(string(b))
 }
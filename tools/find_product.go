@@ -0,0 +1,55 @@
+// Tool: FindProduct
+// คำอธิบาย (ไทย): ค้นหาสินค้าด้วยคำบรรยายแบบไม่ตรงตัว (เช่น "tea box") โดยเทียบชื่อ รหัส และหมวดหมู่ แล้วคืนรายการสินค้าที่ใกล้เคียงเรียงตามคะแนน
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"mcp-bedrock-go/format"
+	"mcp-bedrock-go/productsearch"
+)
+
+func init() {
+	Register(Def[SearchInput]{
+		Tool: mcp.NewTool("find_product",
+			mcp.WithDescription("Find products by fuzzy name, code or category"),
+			mcp.WithString("query", mcp.Required()),
+			withInteger("limit", mcp.Description("Maximum candidates to return (default 5)"), mcp.Min(1), mcp.Max(50), mcp.DefaultNumber(5)),
+			mcp.WithOutputSchema[FindProductOutput]()),
+		Needs:  func(d Deps) bool { return d.Products != nil },
+		Routes: []Route{{Method: http.MethodGet, Path: "/products/search"}},
+		Handler: func(d Deps) mcp.TypedToolHandlerFunc[SearchInput] {
+			return FindProduct(d.Products)
+		},
+	})
+}
+
+// FindProductOutput are the ranked candidates for a product query.
+type FindProductOutput struct {
+	Query      string                    `json:"query"`
+	Candidates []productsearch.Candidate `json:"candidates"`
+	Confident  bool                      `json:"confident" jsonschema_description:"The top candidate is a clear match"`
+}
+
+// Input: query (string, required), limit (int, optional, default 5)
+// Output: JSON {"candidates": [...], "confident": bool} ranked by score
+func FindProduct(products *productsearch.Index) mcp.TypedToolHandlerFunc[SearchInput] {
+	return func(ctx context.Context, req mcp.CallToolRequest, in SearchInput) (*mcp.CallToolResult, error) {
+		query, limit := in.Query, in.Limit
+
+		cands, err := products.Search(ctx, query, limit)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("product search error: %v", err)), nil
+		}
+		if cands == nil {
+			cands = []productsearch.Candidate{}
+		}
+
+		out := FindProductOutput{Query: query, Candidates: cands, Confident: productsearch.Confident(cands)}
+		return format.Result(out), nil
+	}
+}
@@ -4,31 +4,39 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"net/http"
 
 	"github.com/mark3labs/mcp-go/mcp"
 
+	"mcp-bedrock-go/format"
 	odoolib "mcp-bedrock-go/odoo"
 )
 
+func init() {
+	Register(Def[NoInput]{
+		Tool: mcp.NewTool("list_active_products",
+			mcp.WithDescription("List active manufacturing orders"),
+			mcp.WithOutputSchema[Orders]()),
+		Routes: []Route{{Method: http.MethodGet, Path: "/orders/active"}},
+		Handler: func(d Deps) mcp.TypedToolHandlerFunc[NoInput] {
+			return ListActiveProducts(d.Odoo)
+		},
+	})
+}
+
 // Input: none
 // Output: JSON array of active manufacturing orders
-func ListActiveProducts(oclient *odoolib.Client) func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func ListActiveProducts(oclient *odoolib.Client) mcp.TypedToolHandlerFunc[NoInput] {
+	return func(ctx context.Context, req mcp.CallToolRequest, in NoInput) (*mcp.CallToolResult, error) {
 		fields := []string{"id", "name", "product_id", "product_qty", "date_deadline", "state"}
 		domain := []any{[]any{"state", "in", []string{"confirmed", "progress", "done"}}}
 
-		items, err := oclient.SearchRead("mrp.production", fields, domain)
+		items, err := oclient.SearchReadContext(ctx, "mrp.production", fields, domain)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Odoo error: %v", err)), nil
 		}
 
-		if len(items) == 0 {
-			return mcp.NewToolResultText("[]"), nil
-		}
-
-		b, _ := json.MarshalIndent(items, "", "  ")
-		return mcp.NewToolResultText(string(b)), nil
+		return format.Result(items), nil
 	}
 }
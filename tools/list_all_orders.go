@@ -4,27 +4,39 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"net/http"
 
 	"github.com/mark3labs/mcp-go/mcp"
 
+	"mcp-bedrock-go/format"
 	odoolib "mcp-bedrock-go/odoo"
 )
 
+func init() {
+	Register(Def[NoInput]{
+		Tool: mcp.NewTool("list_all_orders",
+			mcp.WithDescription("List all manufacturing orders"),
+			mcp.WithOutputSchema[Orders]()),
+		Routes: []Route{{Method: http.MethodGet, Path: "/orders"}},
+		Handler: func(d Deps) mcp.TypedToolHandlerFunc[NoInput] {
+			return ListAllOrders(d.Odoo)
+		},
+	})
+}
+
 // Input: optional filter
 // Output: JSON array of manufacturing orders (excluding done)
-func ListAllOrders(oclient *odoolib.Client) func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func ListAllOrders(oclient *odoolib.Client) mcp.TypedToolHandlerFunc[NoInput] {
+	return func(ctx context.Context, req mcp.CallToolRequest, in NoInput) (*mcp.CallToolResult, error) {
 		fields := []string{"id", "name", "product_id", "product_qty", "date_deadline", "state", "workorder_ids"}
 		domain := []any{[]any{"state", "!=", "done"}}
 
-		items, err := oclient.SearchRead("mrp.production", fields, domain)
+		items, err := oclient.SearchReadContext(ctx, "mrp.production", fields, domain)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Odoo error: %v", err)), nil
 		}
 
-		b, _ := json.MarshalIndent(items, "", "  ")
-		return mcp.NewToolResultText(string(b)), nil
+		return format.Result(items), nil
 	}
 }
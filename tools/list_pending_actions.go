@@ -0,0 +1,43 @@
+// Tool: ListPendingActions
+// คำอธิบาย (ไทย): แสดงรายการคำสั่งเขียนข้อมูลที่ยังรออนุมัติและยังไม่หมดอายุ
+package tools
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"mcp-bedrock-go/approval"
+	"mcp-bedrock-go/format"
+)
+
+func init() {
+	Register(Def[NoInput]{
+		Tool: mcp.NewTool("list_pending_actions",
+			mcp.WithDescription("List write actions waiting for approval"),
+			mcp.WithOutputSchema[PendingActions]()),
+		Needs:  func(d Deps) bool { return d.Approvals != nil },
+		Routes: []Route{{Method: http.MethodGet, Path: "/actions"}},
+		Handler: func(d Deps) mcp.TypedToolHandlerFunc[NoInput] {
+			return ListPendingActions(d.Approvals)
+		},
+	})
+}
+
+// PendingActions are the actions waiting for a decision.
+type PendingActions struct {
+	Items []approval.Action `json:"items"`
+}
+
+// Input: none
+// Output: JSON array of pending actions, oldest first
+func ListPendingActions(q *approval.Queue) mcp.TypedToolHandlerFunc[NoInput] {
+	return func(ctx context.Context, req mcp.CallToolRequest, in NoInput) (*mcp.CallToolResult, error) {
+		pending := q.Pending()
+		if pending == nil {
+			pending = []approval.Action{}
+		}
+		return format.Result(pending), nil
+	}
+}
@@ -4,19 +4,67 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
+	"net/http"
 
 	"github.com/mark3labs/mcp-go/mcp"
 
+	"mcp-bedrock-go/format"
 	odoolib "mcp-bedrock-go/odoo"
 )
 
+func init() {
+	Register(Def[ListProductMetaInput]{
+		Tool: mcp.NewTool("list_product_meta",
+			mcp.WithDescription("List product metadata"),
+			mcp.WithString("filter", mcp.Description("Only categories and templates whose name contains this")),
+			mcp.WithOutputSchema[ProductMeta]()),
+		Routes: []Route{{Method: http.MethodGet, Path: "/products/meta"}},
+		Handler: func(d Deps) mcp.TypedToolHandlerFunc[ListProductMetaInput] {
+			return ListProductMeta(d.Odoo)
+		},
+	})
+}
+
+// ListProductMetaInput are the arguments of list_product_meta.
+type ListProductMetaInput struct {
+	Filter string `json:"filter"`
+}
+
 // ListProductMeta returns common product metadata useful for creating products
+// ProductMeta is the catalogue metadata new products are described with.
+type ProductMeta struct {
+	Categories []struct {
+		ID       int    `json:"id"`
+		Name     string `json:"name"`
+		ParentID any    `json:"parent_id" jsonschema_description:"[id, name] of the parent category, or false"`
+	} `json:"categories"`
+	UoMs []struct {
+		ID         int     `json:"id"`
+		Name       string  `json:"name"`
+		Factor     float64 `json:"factor"`
+		CategoryID any     `json:"category_id" jsonschema_description:"[id, name] of the UoM category"`
+	} `json:"uoms"`
+	Attributes []struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	} `json:"attributes"`
+	AttributeValues map[string][]struct {
+		ID          int    `json:"id"`
+		Name        string `json:"name"`
+		AttributeID any    `json:"attribute_id"`
+	} `json:"attribute_values" jsonschema_description:"Values keyed by attribute id"`
+	Templates []struct {
+		ID          int    `json:"id"`
+		Name        string `json:"name"`
+		DefaultCode any    `json:"default_code" jsonschema_description:"Internal reference, or false"`
+	} `json:"templates"`
+}
+
 // Input: optional `filter` (string) to search by name
 // Output: JSON object { categories: [], uoms: [], attributes: [], templates: [] }
-func ListProductMeta(oclient *odoolib.Client) func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		filter := req.GetString("filter", "")
+func ListProductMeta(oclient *odoolib.Client) mcp.TypedToolHandlerFunc[ListProductMetaInput] {
+	return func(ctx context.Context, req mcp.CallToolRequest, in ListProductMetaInput) (*mcp.CallToolResult, error) {
+		filter := in.Filter
 
 		// categories
 		catFields := []string{"id", "name", "parent_id"}
@@ -26,7 +74,7 @@ func ListProductMeta(oclient *odoolib.Client) func(ctx context.Context, req mcp.
 		} else {
 			catDomain = []any{}
 		}
-		cats, _ := oclient.SearchRead("product.category", catFields, catDomain)
+		cats, _ := oclient.SearchReadContext(ctx, "product.category", catFields, catDomain)
 
 		// uoms
 		uomFields := []string{"id", "name", "factor", "category_id"}
@@ -36,14 +84,14 @@ func ListProductMeta(oclient *odoolib.Client) func(ctx context.Context, req mcp.
 		} else {
 			uomDomain = []any{}
 		}
-		uoms, _ := oclient.SearchRead("uom.uom", uomFields, uomDomain)
+		uoms, _ := oclient.SearchReadContext(ctx, "uom.uom", uomFields, uomDomain)
 
 		// product attributes
 		attrFields := []string{"id", "name"}
-		attrs, _ := oclient.SearchRead("product.attribute", attrFields, []any{})
+		attrs, _ := oclient.SearchReadContext(ctx, "product.attribute", attrFields, []any{})
 		// attribute values
 		avFields := []string{"id", "name", "attribute_id"}
-		avs, _ := oclient.SearchRead("product.attribute.value", avFields, []any{})
+		avs, _ := oclient.SearchReadContext(ctx, "product.attribute.value", avFields, []any{})
 
 		// product templates (key summary)
 		tmplFields := []string{"id", "name", "default_code"}
@@ -53,7 +101,7 @@ func ListProductMeta(oclient *odoolib.Client) func(ctx context.Context, req mcp.
 		} else {
 			tmplDomain = []any{}
 		}
-		tmpls, _ := oclient.SearchRead("product.template", tmplFields, tmplDomain)
+		tmpls, _ := oclient.SearchReadContext(ctx, "product.template", tmplFields, tmplDomain)
 
 		// build attribute map of values
 		attrMap := map[int][]map[string]any{}
@@ -71,8 +119,7 @@ func ListProductMeta(oclient *odoolib.Client) func(ctx context.Context, req mcp.
 			"templates":        tmpls,
 		}
 
-		b, _ := json.MarshalIndent(out, "", "  ")
-		return mcp.NewToolResultText(string(b)), nil
+		return format.Result(out), nil
 	}
 }
 
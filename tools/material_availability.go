@@ -4,26 +4,61 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
+	"net/http"
 
 	"github.com/mark3labs/mcp-go/mcp"
 
+	"mcp-bedrock-go/format"
 	odoolib "mcp-bedrock-go/odoo"
 )
 
+func init() {
+	Register(Def[MaterialAvailabilityInput]{
+		Tool: mcp.NewTool("material_availability",
+			mcp.WithDescription("Check BOM/stock"),
+			withInteger("product_id", mcp.Description("Product to check; or give mo_id")),
+			withInteger("mo_id", mcp.Description("Manufacturing order whose product to check")),
+			mcp.WithOutputSchema[MaterialAvailabilityOutput]()),
+		Routes: []Route{{Method: http.MethodGet, Path: "/orders/{mo_id}/materials"}, {Method: http.MethodGet, Path: "/materials"}},
+		Handler: func(d Deps) mcp.TypedToolHandlerFunc[MaterialAvailabilityInput] {
+			return MaterialAvailability(d.Odoo)
+		},
+	})
+}
+
+// MaterialAvailabilityInput are the arguments of material_availability.
+type MaterialAvailabilityInput struct {
+	ProductID int `json:"product_id"`
+	MOID      int `json:"mo_id"`
+}
+
+// MaterialAvailabilityOutput are a product's bills of materials and stock.
+type MaterialAvailabilityOutput struct {
+	ProductID int `json:"product_id"`
+	BOMs      []struct {
+		ID            int   `json:"id"`
+		ProductTmplID any   `json:"product_tmpl_id" jsonschema_description:"[id, name] of the product template"`
+		BOMLineIDs    []int `json:"bom_line_ids"`
+	} `json:"boms"`
+	Stock []struct {
+		ProductID any     `json:"product_id" jsonschema_description:"[id, name] of the product"`
+		Quantity  float64 `json:"quantity"`
+	} `json:"stock"`
+}
+
 // Input: product_id (int) or mo_id (int)
 // Output: JSON with BOM components and current stock levels
-func MaterialAvailability(oclient *odoolib.Client) func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		pid := req.GetInt("product_id", 0)
-		moid := req.GetInt("mo_id", 0)
+func MaterialAvailability(oclient *odoolib.Client) mcp.TypedToolHandlerFunc[MaterialAvailabilityInput] {
+	return func(ctx context.Context, req mcp.CallToolRequest, in MaterialAvailabilityInput) (*mcp.CallToolResult, error) {
+		pid := in.ProductID
+		moid := in.MOID
 
 		var productID int
 		if pid != 0 {
 			productID = pid
 		} else if moid != 0 {
 			// fetch MO to get product
-			mos, err := oclient.SearchRead("mrp.production", []string{"product_id", "product_qty"}, []any{[]any{"id", "=", moid}})
+			mos, err := oclient.SearchReadContext(ctx, "mrp.production", []string{"product_id", "product_qty"}, []any{[]any{"id", "=", moid}})
 			if err == nil && len(mos) > 0 {
 				if arr, ok := mos[0]["product_id"].([]any); ok && len(arr) > 0 {
 					if v, ok := arr[0].(float64); ok {
@@ -38,11 +73,10 @@ func MaterialAvailability(oclient *odoolib.Client) func(ctx context.Context, req
 		}
 
 		// fetch BOMs and stock quant (simplified)
-		boms, _ := oclient.SearchRead("mrp.bom", []string{"id", "product_tmpl_id", "bom_line_ids"}, []any{[]any{"product_tmpl_id", "=", productID}})
-		stock, _ := oclient.SearchRead("stock.quant", []string{"product_id", "quantity"}, []any{[]any{"product_id", "=", productID}})
+		boms, _ := oclient.SearchReadContext(ctx, "mrp.bom", []string{"id", "product_tmpl_id", "bom_line_ids"}, []any{[]any{"product_tmpl_id", "=", productID}})
+		stock, _ := oclient.SearchReadContext(ctx, "stock.quant", []string{"product_id", "quantity"}, []any{[]any{"product_id", "=", productID}})
 
 		out := map[string]any{"product_id": productID, "boms": boms, "stock": stock}
-		b, _ := json.MarshalIndent(out, "", "  ")
-		return mcp.NewToolResultText(string(b)), nil
+		return format.Result(out), nil
 	}
 }
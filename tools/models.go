@@ -0,0 +1,33 @@
+package tools
+
+// ModelAccess lists the Odoo models a tool reads and writes.
+type ModelAccess struct {
+	Read  []string
+	Write []string
+}
+
+// OdooModels declares which Odoo models each tool touches, so authorization
+// can allow or deny tools by model. Keep it in step with the handlers.
+var OdooModels = map[string]ModelAccess{
+	"list_all_orders":       {Read: []string{"mrp.production"}},
+	"list_active_products":  {Read: []string{"mrp.production"}},
+	"schedule_analysis":     {Read: []string{"mrp.production", "product.product"}},
+	"production_planner":    {Read: []string{"mrp.production"}},
+	"capacity_check":        {Read: []string{"mrp.workorder"}},
+	"order_priority":        {Read: []string{"mrp.production"}},
+	"order_risk":            {Read: []string{"mrp.production"}},
+	"material_availability": {Read: []string{"mrp.production", "mrp.bom", "stock.quant"}},
+	"add_product":           {Read: []string{"product.product"}, Write: []string{"product.product"}},
+	"list_product_meta":     {Read: []string{"product.category", "uom.uom", "product.attribute", "product.attribute.value", "product.template"}},
+	"find_product":          {Read: []string{"product.product"}},
+	"create_mo":             {Read: []string{"product.product", "mrp.bom"}, Write: []string{"mrp.production"}},
+	// search_docs reads the local document index only.
+	"search_docs": {},
+	// approve_action writes through the approved tool, which was checked
+	// when it was queued.
+	"approve_action":       {},
+	"reject_action":        {},
+	"list_pending_actions": {},
+	// query_audit reads the local audit log only.
+	"query_audit": {},
+}
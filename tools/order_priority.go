@@ -4,25 +4,37 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"net/http"
 
 	"github.com/mark3labs/mcp-go/mcp"
 
+	"mcp-bedrock-go/format"
 	odoolib "mcp-bedrock-go/odoo"
 )
 
+func init() {
+	Register(Def[NoInput]{
+		Tool: mcp.NewTool("order_priority",
+			mcp.WithDescription("Rank MOs"),
+			mcp.WithOutputSchema[Orders]()),
+		Routes: []Route{{Method: http.MethodGet, Path: "/orders/priority"}},
+		Handler: func(d Deps) mcp.TypedToolHandlerFunc[NoInput] {
+			return OrderPriority(d.Odoo)
+		},
+	})
+}
+
 // Input: none or optional list of mo_ids
 // Output: JSON ranked list of orders with score and reason
-func OrderPriority(oclient *odoolib.Client) func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func OrderPriority(oclient *odoolib.Client) mcp.TypedToolHandlerFunc[NoInput] {
+	return func(ctx context.Context, req mcp.CallToolRequest, in NoInput) (*mcp.CallToolResult, error) {
 		// For demo: rank by product_qty descending
-		items, err := oclient.SearchRead("mrp.production", []string{"id", "name", "product_qty", "date_deadline", "state"}, []any{[]any{}})
+		items, err := oclient.SearchReadContext(ctx, "mrp.production", []string{"id", "name", "product_qty", "date_deadline", "state"}, []any{[]any{}})
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Odoo error: %v", err)), nil
 		}
 		// return raw items — consumer can compute ranking client-side or we could score
-		b, _ := json.MarshalIndent(items, "", "  ")
-		return mcp.NewToolResultText(string(b)), nil
+		return format.Result(items), nil
 	}
 }
@@ -4,31 +4,48 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"net/http"
 
 	"github.com/mark3labs/mcp-go/mcp"
 
+	"mcp-bedrock-go/format"
 	odoolib "mcp-bedrock-go/odoo"
 )
 
+func init() {
+	Register(Def[MOInput]{
+		Tool: mcp.NewTool("order_risk",
+			mcp.WithDescription("Risk assessment"),
+			withInteger("mo_id", mcp.Required(), mcp.Min(1)),
+			mcp.WithOutputSchema[OrderRiskOutput]()),
+		Routes: []Route{{Method: http.MethodGet, Path: "/orders/{mo_id}/risk"}},
+		Handler: func(d Deps) mcp.TypedToolHandlerFunc[MOInput] {
+			return OrderRisk(d.Odoo)
+		},
+	})
+}
+
+// OrderRiskOutput is the risk assessment of one manufacturing order.
+type OrderRiskOutput struct {
+	MOID      int    `json:"mo_id"`
+	RiskLevel string `json:"risk_level" jsonschema:"enum=low,enum=medium,enum=high"`
+	Notes     string `json:"notes"`
+}
+
 // Input: mo_id (int)
 // Output: JSON risk assessment for the given manufacturing order
-func OrderRisk(oclient *odoolib.Client) func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		moid := req.GetInt("mo_id", 0)
-		if moid == 0 {
-			return mcp.NewToolResultError("mo_id is required"), nil
-		}
+func OrderRisk(oclient *odoolib.Client) mcp.TypedToolHandlerFunc[MOInput] {
+	return func(ctx context.Context, req mcp.CallToolRequest, in MOInput) (*mcp.CallToolResult, error) {
+		moid := in.MOID
 
-		mos, err := oclient.SearchRead("mrp.production", []string{"id", "name", "product_id", "product_qty", "state"}, []any{[]any{"id", "=", moid}})
+		mos, err := oclient.SearchReadContext(ctx, "mrp.production", []string{"id", "name", "product_id", "product_qty", "state"}, []any{[]any{"id", "=", moid}})
 		if err != nil || len(mos) == 0 {
 			return mcp.NewToolResultError(fmt.Sprintf("MO not found or Odoo error: %v", err)), nil
 		}
 
 		// Simplified static risk assessment
-		risk := map[string]any{"mo_id": moid, "risk_level": "medium", "notes": "Material checks recommended"}
-		b, _ := json.MarshalIndent(risk, "", "  ")
-		return mcp.NewToolResultText(string(b)), nil
+		risk := OrderRiskOutput{MOID: moid, RiskLevel: "medium", Notes: "Material checks recommended"}
+		return format.Result(risk), nil
 	}
 }
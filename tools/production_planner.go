@@ -6,40 +6,75 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 
 	"github.com/mark3labs/mcp-go/mcp"
 
 	bedrocklib "mcp-bedrock-go/bedrock"
+	"mcp-bedrock-go/format"
 	odoolib "mcp-bedrock-go/odoo"
+	"mcp-bedrock-go/retrieval"
 )
 
+func init() {
+	Register(Def[MOInput]{
+		Tool: mcp.NewTool("production_planner",
+			mcp.WithDescription("Suggest a production plan for a manufacturing order"),
+			withInteger("mo_id", mcp.Required(), mcp.Min(1)),
+			mcp.WithOutputSchema[PlanOutput]()),
+		Routes: []Route{{Method: http.MethodGet, Path: "/orders/{mo_id}/plan"}},
+		Handler: func(d Deps) mcp.TypedToolHandlerFunc[MOInput] {
+			return ProductionPlanner(d.Odoo, d.LLM("production_planner"), d.Docs)
+		},
+	})
+}
+
+// PlanOutput is a suggested production plan and the order it is for.
+type PlanOutput struct {
+	PlanText string `json:"plan_text"`
+	Model    string `json:"model" jsonschema_description:"Bedrock model that wrote the plan"`
+	MO       Order  `json:"mo"`
+}
+
+// MOInput are the arguments of tools that work on one manufacturing order.
+type MOInput struct {
+	MOID int `json:"mo_id"`
+}
+
 // Input: mo_id (int)
-// Output: textual production plan suggestion (LLM-generated) and JSON plan
-func ProductionPlanner(oclient *odoolib.Client, br *bedrocklib.Client, modelID string) func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		moid := req.GetInt("mo_id", 0)
-		if moid == 0 {
-			return mcp.NewToolResultError("mo_id is required"), nil
-		}
+// Output: textual production plan suggestion (LLM-generated), the model that answered and JSON plan
+func ProductionPlanner(oclient *odoolib.Client, llm *bedrocklib.Chain, docs *retrieval.Index) mcp.TypedToolHandlerFunc[MOInput] {
+	return func(ctx context.Context, req mcp.CallToolRequest, in MOInput) (*mcp.CallToolResult, error) {
+		moid := in.MOID
 
-		mos, err := oclient.SearchRead("mrp.production", []string{"id", "name", "product_id", "product_qty", "date_deadline"}, []any{[]any{"id", "=", moid}})
+		mos, err := oclient.SearchReadContext(ctx, "mrp.production", []string{"id", "name", "product_id", "product_qty", "date_deadline"}, []any{[]any{"id", "=", moid}})
 		if err != nil || len(mos) == 0 {
 			return mcp.NewToolResultError(fmt.Sprintf("MO not found or Odoo error: %v", err)), nil
 		}
 
 		// Prepare prompt as plain string (Bedrock requires "prompt" to be a string)
-		moJson, _ := json.MarshalIndent(mos[0], "", "  ")
-		promptText := fmt.Sprintf("Generate short production plan with workcenter assignment and estimated duration.\n\nMO:\n%s", string(moJson))
+		promptText := PlannerPrompt(mos[0])
+		// Ground the plan in changeover rules and work instructions when we have them
+		if sops := DocsContext(ctx, docs, fmt.Sprintf("production plan changeover workcenter %v", mos[0]["product_id"])); sops != "" {
+			promptText = sops + "\n\n" + promptText
+		}
 		// Wrap with system prompt template required by model
 		promptText = bedrocklib.FormatSystemPrompt(promptText)
-		out, err := br.GenerateText(ctx, modelID, promptText)
+		out, model, err := llm.Generate(ctx, promptText)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("LLM error: %v", err)), nil
 		}
 
 		// Return both LLM text and structured MO for reference
-		resp := map[string]any{"plan_text": out, "mo": mos[0]}
-		b, _ := json.MarshalIndent(resp, "", "  ")
-		return mcp.NewToolResultText(string(b)), nil
+		resp := map[string]any{"plan_text": out, "model": model, "mo": mos[0]}
+		res := format.Result(resp)
+		res.Meta = mcp.NewMetaFromMap(map[string]any{"model": model, "template": "planner"})
+		return res, nil
 	}
 }
+
+// PlannerPrompt asks for a short production plan for one MO.
+func PlannerPrompt(mo map[string]any) string {
+	moJson, _ := json.MarshalIndent(mo, "", "  ")
+	return fmt.Sprintf("Generate short production plan with workcenter assignment and estimated duration.\n\nMO:\n%s", string(moJson))
+}
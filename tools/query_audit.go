@@ -0,0 +1,117 @@
+// Tool: QueryAudit
+// คำอธิบาย (ไทย): ค้นหาบันทึกการเรียกใช้เครื่องมือ (audit log) ว่าใครสั่งอะไร เมื่อไร ด้วยอาร์กิวเมนต์ใด และสร้างเรคคอร์ดใดบน Odoo พร้อมตรวจสอบว่าบันทึกไม่ถูกแก้ไข
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"mcp-bedrock-go/audit"
+	"mcp-bedrock-go/format"
+)
+
+func init() {
+	Register(Def[QueryAuditInput]{
+		Tool: mcp.NewTool("query_audit",
+			mcp.WithDescription("Search the audit log of tool calls: who called what, with which arguments, and what it created"),
+			mcp.WithString("tool", mcp.Description("Only calls of this tool")),
+			mcp.WithString("subject", mcp.Description("Only calls by this principal")),
+			mcp.WithString("session_id", mcp.Description("Only calls from this MCP session")),
+			mcp.WithString("record", mcp.Description("A created record's id or name (WH/MO/00123), or an argument value")),
+			mcp.WithString("outcome", mcp.Enum(audit.OK, audit.Error, audit.Pending, audit.Failure)),
+			mcp.WithString("since", withFormat("date-time"), mcp.Description("Calls at or after this time (plant time unless it has an offset)")),
+			mcp.WithString("until", withFormat("date-time"), mcp.Description("Calls at or before this time; a date covers the whole day")),
+			withInteger("limit", mcp.Description("Maximum entries, most recent first (default 20)"), mcp.Min(1), mcp.Max(500), mcp.DefaultNumber(20)),
+			mcp.WithBoolean("verify", mcp.Description("Also check the hash chain of the whole log")),
+			mcp.WithOutputSchema[QueryAuditOutput]()),
+		Needs:  func(d Deps) bool { return d.Audit != nil },
+		Routes: []Route{{Method: http.MethodGet, Path: "/audit"}},
+		Handler: func(d Deps) mcp.TypedToolHandlerFunc[QueryAuditInput] {
+			return QueryAudit(d.Audit)
+		},
+	})
+}
+
+// QueryAuditInput are the arguments of query_audit.
+type QueryAuditInput struct {
+	Tool      string `json:"tool"`
+	Subject   string `json:"subject"`
+	SessionID string `json:"session_id"`
+	Record    string `json:"record"`
+	Outcome   string `json:"outcome"`
+	Since     string `json:"since"`
+	Until     string `json:"until"`
+	Limit     int    `json:"limit"`
+	Verify    bool   `json:"verify"`
+}
+
+// QueryAuditOutput are the matching audit entries.
+type QueryAuditOutput struct {
+	Entries      []audit.Entry       `json:"entries"`
+	Count        int                 `json:"count"`
+	Verification *audit.Verification `json:"verification,omitempty"`
+}
+
+// Input: tool, subject, session_id, record, outcome, since, until (all
+// optional filters), limit (default 20), verify (bool)
+// Output: JSON {"entries": [...], "count": n, "verification": {...}}
+func QueryAudit(log *audit.Log) mcp.TypedToolHandlerFunc[QueryAuditInput] {
+	return func(ctx context.Context, req mcp.CallToolRequest, in QueryAuditInput) (*mcp.CallToolResult, error) {
+		f := audit.Filter{
+			Tool:      in.Tool,
+			Subject:   in.Subject,
+			SessionID: in.SessionID,
+			Record:    in.Record,
+			Outcome:   in.Outcome,
+			Limit:     in.Limit,
+		}
+		var err error
+		if f.Since, err = AuditTime(in.Since, false); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("since: %v", err)), nil
+		}
+		if f.Until, err = AuditTime(in.Until, true); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("until: %v", err)), nil
+		}
+
+		entries, err := log.Query(f)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("audit log: %v", err)), nil
+		}
+		if entries == nil {
+			entries = []audit.Entry{}
+		}
+		resp := QueryAuditOutput{Entries: entries, Count: len(entries)}
+		if in.Verify {
+			v, err := log.Verify()
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("audit log: %v", err)), nil
+			}
+			resp.Verification = &v
+		}
+		return format.Result(resp), nil
+	}
+}
+
+// AuditTime parses a since/until bound as normalised by Validate
+// ("2006-01-02 15:04:05" in UTC, or a date). A date is a day at the plant;
+// as an upper bound it covers the whole day.
+func AuditTime(s string, end bool) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.DateTime, s); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation(time.DateOnly, s, Location)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if end {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return t, nil
+}
@@ -0,0 +1,50 @@
+package tools
+
+// Output types declare the structuredContent of each tool (its MCP output
+// schema). Records read from Odoo keep Odoo's shape: many2one fields are
+// [id, "name"] pairs and empty fields are false, so those are untyped.
+
+// Order is a manufacturing order as the order tools read it.
+type Order struct {
+	ID           int     `json:"id"`
+	Name         string  `json:"name"`
+	ProductID    any     `json:"product_id,omitempty" jsonschema_description:"[id, name] of the product"`
+	ProductQty   float64 `json:"product_qty,omitempty"`
+	DateDeadline any     `json:"date_deadline,omitempty" jsonschema_description:"Deadline as YYYY-MM-DD HH:MM:SS, or false"`
+	State        string  `json:"state,omitempty" jsonschema_description:"draft, confirmed, progress, to_close, done or cancel"`
+	WorkorderIDs []int   `json:"workorder_ids,omitempty"`
+}
+
+// Orders is a list of manufacturing orders.
+type Orders struct {
+	Items []Order `json:"items"`
+}
+
+// Workorder is an operation of a manufacturing order at a work center.
+type Workorder struct {
+	ID                  int     `json:"id"`
+	Name                string  `json:"name"`
+	WorkcenterID        any     `json:"workcenter_id,omitempty" jsonschema_description:"[id, name] of the work center"`
+	State               string  `json:"state,omitempty"`
+	DatePlannedStart    any     `json:"date_planned_start,omitempty"`
+	DatePlannedFinished any     `json:"date_planned_finished,omitempty"`
+	Duration            float64 `json:"duration,omitempty" jsonschema_description:"Expected duration in minutes"`
+}
+
+// PendingOutput are the fields a write tool returns when the call was queued
+// for approval instead of run.
+type PendingOutput struct {
+	Status    string `json:"status,omitempty" jsonschema_description:"pending when the call waits for approve_action"`
+	ActionID  string `json:"action_id,omitempty"`
+	Summary   string `json:"summary,omitempty"`
+	ExpiresAt string `json:"expires_at,omitempty"`
+}
+
+// DryRunOutput are the fields a write tool returns instead of creating when
+// dry_run is set.
+type DryRunOutput struct {
+	DryRun   bool           `json:"dry_run,omitempty"`
+	Model    string         `json:"model,omitempty" jsonschema_description:"Odoo model the record would be created in"`
+	Vals     map[string]any `json:"vals,omitempty" jsonschema_description:"Values that would be sent to create"`
+	Warnings []string       `json:"warnings,omitempty"`
+}
@@ -0,0 +1,337 @@
+package tools
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mcp-bedrock-go/approval"
+	"mcp-bedrock-go/audit"
+	bedrocklib "mcp-bedrock-go/bedrock"
+	"mcp-bedrock-go/conversation"
+	"mcp-bedrock-go/format"
+	"mcp-bedrock-go/internal/logging"
+	odoolib "mcp-bedrock-go/odoo"
+	"mcp-bedrock-go/productsearch"
+	"mcp-bedrock-go/retrieval"
+)
+
+var logger = logging.For("tools")
+
+// Deps are the services tool handlers are built from. Optional subsystems
+// are nil when disabled.
+type Deps struct {
+	Odoo      *odoolib.Client
+	LLM       func(tool string) *bedrocklib.Chain // fallback chain for a tool
+	Convs     *conversation.Store
+	Docs      *retrieval.Index
+	Products  *productsearch.Index
+	Approvals *approval.Queue
+	Writes    WriteOptions
+	Audit     *audit.Log
+}
+
+// Def declares one tool next to its handler: the schema clients see, the
+// struct its arguments bind to (In, by json tag) and how to build the
+// handler from Deps. Check verifies the schema and In agree. Register adds
+// the output_format parameter, which format.Middleware handles.
+type Def[In any] struct {
+	Tool    mcp.Tool
+	Needs   func(d Deps) bool // nil: always available; false leaves the tool out
+	Handler func(d Deps) mcp.TypedToolHandlerFunc[In]
+	Routes  []Route // resource-style REST endpoints, besides /tools/{name}
+}
+
+// Route exposes a tool as a resource-style REST endpoint. Path parameters
+// ({mo_id}) name tool arguments; the others come from the query string or,
+// for POST, the JSON body.
+type Route struct {
+	Method string // GET or POST
+	Path   string // below the API prefix, e.g. /orders/{mo_id}/risk
+}
+
+type entry struct {
+	tool   mcp.Tool
+	needs  func(Deps) bool
+	build  func(Deps) server.ToolHandlerFunc
+	in     reflect.Type
+	routes []Route
+}
+
+var (
+	regMu    sync.Mutex
+	registry = map[string]entry{}
+)
+
+// Register adds a tool. Each tool file calls it from init.
+func Register[In any](d Def[In]) {
+	regMu.Lock()
+	defer regMu.Unlock()
+	if _, dup := registry[d.Tool.Name]; dup {
+		panic("tools: duplicate tool " + d.Tool.Name)
+	}
+	withOutputFormat()(&d.Tool)
+	registry[d.Tool.Name] = entry{
+		tool:  d.Tool,
+		needs: d.Needs,
+		build: func(deps Deps) server.ToolHandlerFunc {
+			return mcp.NewTypedToolHandler(d.Handler(deps))
+		},
+		in:     reflect.TypeOf((*In)(nil)).Elem(),
+		routes: d.Routes,
+	}
+}
+
+// Names lists every registered tool, sorted.
+func Names() []string {
+	regMu.Lock()
+	defer regMu.Unlock()
+	out := make([]string, 0, len(registry))
+	for name := range registry {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Definitions returns the schema of every registered tool, sorted by name.
+func Definitions() []mcp.Tool {
+	names := Names()
+	regMu.Lock()
+	defer regMu.Unlock()
+	out := make([]mcp.Tool, len(names))
+	for i, n := range names {
+		out[i] = registry[n].tool
+	}
+	return out
+}
+
+// Routes returns the REST routes of a tool.
+func Routes(name string) []Route {
+	regMu.Lock()
+	defer regMu.Unlock()
+	return registry[name].routes
+}
+
+// Build returns the handlers of the tools whose dependencies are present,
+// sorted by name.
+func Build(d Deps) []server.ServerTool {
+	var out []server.ServerTool
+	for _, name := range Names() {
+		regMu.Lock()
+		e := registry[name]
+		regMu.Unlock()
+		if e.needs != nil && !e.needs(d) {
+			continue
+		}
+		out = append(out, server.ServerTool{Tool: e.tool, Handler: e.build(d)})
+	}
+	return out
+}
+
+// Check verifies every tool at startup: each schema property binds to a
+// field of the argument struct with a matching type, each tagged field is
+// declared in the schema, defaults pass validation, and every tool has an
+// output schema and an Odoo model entry.
+func Check() error {
+	var problems []string
+	for _, name := range Names() {
+		regMu.Lock()
+		e := registry[name]
+		regMu.Unlock()
+		for _, p := range checkSchema(e.tool, e.in) {
+			problems = append(problems, name+": "+p)
+		}
+		if e.tool.OutputSchema.Type == "" {
+			problems = append(problems, name+": no output schema")
+		}
+		if _, ok := OdooModels[name]; !ok {
+			problems = append(problems, name+": no entry in OdooModels")
+		}
+		for _, r := range e.routes {
+			for _, p := range checkRoute(e.tool, r) {
+				problems = append(problems, name+": "+p)
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("tool registry:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
+
+func checkSchema(tool mcp.Tool, in reflect.Type) []string {
+	if in.Kind() != reflect.Struct {
+		return []string{fmt.Sprintf("arguments bind to %s, want a struct", in)}
+	}
+	var problems []string
+	fields := map[string]reflect.Type{}
+	for i := 0; i < in.NumField(); i++ {
+		f := in.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "" || name == "-" || !f.IsExported() {
+			continue
+		}
+		fields[name] = f.Type
+		if _, ok := tool.InputSchema.Properties[name]; !ok {
+			problems = append(problems, fmt.Sprintf("field %s (%q) is not in the schema", f.Name, name))
+		}
+	}
+	for name, raw := range tool.InputSchema.Properties {
+		prop, _ := raw.(map[string]any)
+		if name == format.Arg {
+			continue // handled by format.Middleware, not the handler
+		}
+		t, ok := fields[name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("parameter %q has no field in %s", name, in))
+			continue
+		}
+		if msg := typeMismatch(prop, t); msg != "" {
+			problems = append(problems, fmt.Sprintf("parameter %q: %s", name, msg))
+		}
+		if def, ok := prop["default"]; ok {
+			if _, errs := coerce(name, prop, def); len(errs) > 0 {
+				problems = append(problems, fmt.Sprintf("parameter %q: default %v %s", name, def, errs[0].Message))
+			}
+		}
+	}
+	for _, r := range tool.InputSchema.Required {
+		if _, ok := tool.InputSchema.Properties[r]; !ok {
+			problems = append(problems, fmt.Sprintf("required parameter %q is not declared", r))
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+// checkRoute verifies a route's method and that its path parameters are
+// declared, required scalars.
+func checkRoute(tool mcp.Tool, r Route) []string {
+	var problems []string
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		problems = append(problems, fmt.Sprintf("route %s %s: method must be GET or POST", r.Method, r.Path))
+	}
+	for _, p := range PathParams(r.Path) {
+		prop, ok := tool.InputSchema.Properties[p].(map[string]any)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("route %s: path parameter %q is not in the schema", r.Path, p))
+		} else if prop["type"] == "array" {
+			problems = append(problems, fmt.Sprintf("route %s: path parameter %q is an array", r.Path, p))
+		}
+	}
+	return problems
+}
+
+// PathParams lists the {name} parameters of a route path.
+func PathParams(path string) []string {
+	var out []string
+	for _, seg := range strings.Split(path, "/") {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			out = append(out, seg[1:len(seg)-1])
+		}
+	}
+	return out
+}
+
+// typeMismatch reports why a JSON schema property cannot bind to t.
+func typeMismatch(prop map[string]any, t reflect.Type) string {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	typ, _ := prop["type"].(string)
+	var ok bool
+	switch typ {
+	case "integer":
+		switch t.Kind() {
+		case reflect.Int, reflect.Int32, reflect.Int64:
+			ok = true
+		}
+	case "number":
+		ok = t.Kind() == reflect.Float64 || t.Kind() == reflect.Float32
+	case "string":
+		ok = t.Kind() == reflect.String
+	case "boolean":
+		ok = t.Kind() == reflect.Bool
+	case "array":
+		if t.Kind() != reflect.Slice {
+			break
+		}
+		items, _ := prop["items"].(map[string]any)
+		if items == nil {
+			return "array without items"
+		}
+		return typeMismatch(items, t.Elem())
+	default:
+		return fmt.Sprintf("unsupported schema type %q", typ)
+	}
+	if !ok {
+		return fmt.Sprintf("schema type %s cannot bind to Go %s", typ, t)
+	}
+	if _, isEnum := prop["enum"]; isEnum && t.Kind() != reflect.String {
+		return "enum on a non-string field"
+	}
+	return ""
+}
+
+// withInteger declares an integer parameter (mcp-go only offers number).
+func withInteger(name string, opts ...mcp.PropertyOption) mcp.ToolOption {
+	return func(t *mcp.Tool) {
+		mcp.WithNumber(name, opts...)(t)
+		t.InputSchema.Properties[name].(map[string]any)["type"] = "integer"
+	}
+}
+
+// withDate declares a YYYY-MM-DD string parameter.
+func withDate(name string, opts ...mcp.PropertyOption) mcp.ToolOption {
+	return mcp.WithString(name, append([]mcp.PropertyOption{
+		withFormat("date"),
+		mcp.Pattern(`^\d{4}-\d{2}-\d{2}$`),
+	}, opts...)...)
+}
+
+// withFormat sets a string parameter's format (date, date-time); Validate
+// normalises values to it.
+func withFormat(format string) mcp.PropertyOption {
+	return func(p map[string]any) { p["format"] = format }
+}
+
+// exclusiveMin requires a number strictly greater than min.
+func exclusiveMin(min float64) mcp.PropertyOption {
+	return func(p map[string]any) { p["exclusiveMinimum"] = min }
+}
+
+// withDryRun declares the dry_run flag of write tools.
+func withDryRun() mcp.ToolOption {
+	return mcp.WithBoolean("dry_run", mcp.Description("Validate and return the vals without creating"))
+}
+
+// withIdempotencyKey declares the idempotency_key of write tools; the
+// idempotency middleware reads it, handlers ignore it.
+func withIdempotencyKey() mcp.ToolOption {
+	return mcp.WithString("idempotency_key", mcp.MaxLength(200),
+		mcp.Description("Any unique string; retrying with the same key returns the first result instead of creating again"))
+}
+
+// withOutputFormat declares output_format, which every tool takes.
+func withOutputFormat() mcp.ToolOption {
+	return mcp.WithString(format.Arg, mcp.Enum(format.Names...),
+		mcp.Description("How to render the text result: json (default), compact JSON, a markdown table, csv or a plain-language summary; structuredContent is always JSON"))
+}
+
+// Idempotent reports whether a tool accepts an idempotency_key.
+func Idempotent(name string) bool {
+	regMu.Lock()
+	defer regMu.Unlock()
+	_, ok := registry[name].tool.InputSchema.Properties["idempotency_key"]
+	return ok
+}
+
+// NoInput is the argument struct of tools without parameters.
+type NoInput struct{}
@@ -0,0 +1,47 @@
+// Tool: RejectAction
+// คำอธิบาย (ไทย): ปฏิเสธคำสั่งเขียนข้อมูลที่รออนุมัติ พร้อมเหตุผล โดยไม่มีการเปลี่ยนแปลงใดๆ บน Odoo
+package tools
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"mcp-bedrock-go/approval"
+	"mcp-bedrock-go/format"
+)
+
+func init() {
+	Register(Def[RejectInput]{
+		Tool: mcp.NewTool("reject_action",
+			mcp.WithDescription("Reject a pending write action"),
+			mcp.WithString("action_id", mcp.Required()),
+			mcp.WithString("reason"),
+			mcp.WithOutputSchema[approval.Action]()),
+		Needs:  func(d Deps) bool { return d.Approvals != nil },
+		Routes: []Route{{Method: http.MethodPost, Path: "/actions/{action_id}/reject"}},
+		Handler: func(d Deps) mcp.TypedToolHandlerFunc[RejectInput] {
+			return RejectAction(d.Approvals)
+		},
+	})
+}
+
+// RejectInput are the arguments of reject_action.
+type RejectInput struct {
+	ActionID string `json:"action_id"`
+	Reason   string `json:"reason"`
+}
+
+// Input: action_id (string, required), reason (string, optional)
+// Output: JSON of the rejected action
+func RejectAction(q *approval.Queue) mcp.TypedToolHandlerFunc[RejectInput] {
+	return func(ctx context.Context, req mcp.CallToolRequest, in RejectInput) (*mcp.CallToolResult, error) {
+		id := in.ActionID
+		a, err := q.Reject(ctx, id, in.Reason)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return format.Result(a), nil
+	}
+}
@@ -6,44 +6,156 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
 
+	"mcp-bedrock-go/auth"
 	bedrocklib "mcp-bedrock-go/bedrock"
+	"mcp-bedrock-go/conversation"
 	odoolib "mcp-bedrock-go/odoo"
+	"mcp-bedrock-go/retrieval"
 )
 
-// Input: profile (string) — e.g., "Cost-Aware" or "Throughput"
-// Output: text analysis from LLM considering current Odoo context
-func ScheduleAnalysis(oclient *odoolib.Client, br *bedrocklib.Client, modelID string) func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		profile, err := req.RequireString("profile")
-		if err != nil {
-			profile = "Balanced"
-		}
+func init() {
+	Register(Def[ScheduleAnalysisInput]{
+		Tool: mcp.NewTool("schedule_analysis",
+			mcp.WithDescription("Analyze scheduling impact"),
+			mcp.WithString("profile", mcp.DefaultString("Balanced"), mcp.Description("Planning profile, e.g. Cost-Aware, Throughput or Balanced")),
+			mcp.WithString("question", mcp.Description("Follow-up question; defaults to the RUSH-TEA scenario")),
+			mcp.WithString("conversation_id", mcp.Description("Conversation to continue; defaults to the MCP session, required without one (REST)")),
+			mcp.WithOutputSchema[ScheduleAnalysisOutput]()),
+		Routes: []Route{{Method: http.MethodPost, Path: "/schedule/analysis"}},
+		Handler: func(d Deps) mcp.TypedToolHandlerFunc[ScheduleAnalysisInput] {
+			return ScheduleAnalysis(d.Odoo, d.LLM("schedule_analysis"), d.Convs, d.Docs)
+		},
+	})
+}
+
+// ScheduleAnalysisInput are the arguments of schedule_analysis.
+type ScheduleAnalysisInput struct {
+	Profile        string `json:"profile"`
+	Question       string `json:"question"`
+	ConversationID string `json:"conversation_id"`
+}
 
-		// Gather small context
-		moFields := []string{"id", "name", "product_id", "product_qty", "date_deadline", "state"}
-		mos, _ := oclient.SearchRead("mrp.production", moFields, []any{[]any{"state", "in", []string{"confirmed", "progress"}}})
+// ScheduleAnalysisOutput is the analysis and the conversation it belongs to.
+type ScheduleAnalysisOutput struct {
+	Analysis       string `json:"analysis"`
+	Model          string `json:"model" jsonschema_description:"Bedrock model that answered"`
+	ConversationID string `json:"conversation_id" jsonschema_description:"Pass back to ask a follow-up"`
+}
 
-		prodFields := []string{"id", "default_code", "name"}
-		prods, _ := oclient.SearchRead("product.product", prodFields, []any{[]any{}})
+// Input: profile (string, default "Balanced") — e.g., "Cost-Aware" or "Throughput"
+//   - question (optional) follow-up question; defaults to the RUSH-TEA scenario
+//   - conversation_id (optional) explicit conversation key; defaults to the MCP session ID
+//     and is required for calls without a session
+//
+// Output: text analysis from LLM considering current Odoo context, relevant
+// plant documents and earlier turns of the conversation, tagged with the model
+// that answered
+func ScheduleAnalysis(oclient *odoolib.Client, llm *bedrocklib.Chain, convs *conversation.Store, docs *retrieval.Index) mcp.TypedToolHandlerFunc[ScheduleAnalysisInput] {
+	return func(ctx context.Context, req mcp.CallToolRequest, in ScheduleAnalysisInput) (*mcp.CallToolResult, error) {
+		profile := in.Profile
 
-		ctxObj := map[string]any{"manufacturing_orders": mos, "products": prods}
+		ctxObj := ScheduleContext(ctx, oclient)
 
-		prompt := fmt.Sprintf("Profile: %s\nContext: %s\n\nTask: A new RUSH order (Product Code: RUSH-TEA, Qty:1000) has arrived. Provide a concise recommendation and rationale.", profile, mustJSON(ctxObj))
+		convID := conversationID(ctx, in.ConversationID)
+		if convID == "" {
+			return mcp.NewToolResultError("conversation_id is required for calls without an MCP session"), nil
+		}
+		key := conversationKey(ctx, convID)
+		question := in.Question
+		if question == "" {
+			question = DefaultScheduleQuestion
+		}
+
+		prompt := SchedulePrompt(profile, ctxObj, convs.History(key), question)
+		if sops := DocsContext(ctx, docs, profile+" "+question); sops != "" {
+			prompt = sops + "\n\n" + prompt
+		}
 
 		// Wrap prompt with system template and send as string
 		promptText := bedrocklib.FormatSystemPrompt(prompt)
-		out, err := br.GenerateText(ctx, modelID, promptText)
+		out, model, err := llm.Generate(ctx, promptText)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("LLM error: %v", err)), nil
 		}
-		return mcp.NewToolResultText(out), nil
+
+		convs.Append(ctx, key, conversation.Turn{
+			Question: question,
+			Answer:   out,
+			Model:    model,
+			Snapshot: conversation.Snapshot(ctxObj),
+		})
+
+		res := llmResult(out, model, "schedule/"+profile)
+		res.Content = append(res.Content, mcp.NewTextContent("conversation_id: "+convID))
+		res.Meta.AdditionalFields["conversation_id"] = convID
+		res.StructuredContent = ScheduleAnalysisOutput{Analysis: out, Model: model, ConversationID: convID}
+		return res, nil
 	}
 }
 
+// DefaultScheduleQuestion is asked when schedule_analysis gets no question.
+const DefaultScheduleQuestion = "A new RUSH order (Product Code: RUSH-TEA, Qty:1000) has arrived. Provide a concise recommendation and rationale."
+
+// ScheduleContext gathers the small Odoo snapshot schedule analysis works
+// from: confirmed and in-progress MOs plus the product list.
+func ScheduleContext(ctx context.Context, oclient *odoolib.Client) map[string]any {
+	moFields := []string{"id", "name", "product_id", "product_qty", "date_deadline", "state"}
+	mos, _ := oclient.SearchReadContext(ctx, "mrp.production", moFields, []any{[]any{"state", "in", []string{"confirmed", "progress"}}})
+
+	prodFields := []string{"id", "default_code", "name"}
+	prods, _ := oclient.SearchReadContext(ctx, "product.product", prodFields, []any{[]any{}})
+
+	return map[string]any{"manufacturing_orders": mos, "products": prods}
+}
+
+// SchedulePrompt builds the schedule analysis prompt for a planning profile.
+// With conversation history the question is asked as a follow-up.
+func SchedulePrompt(profile string, ctxObj map[string]any, history, question string) string {
+	if history != "" {
+		return fmt.Sprintf("Profile: %s\nContext: %s\n\nPrevious conversation:\n%s\n\nFollow-up: %s", profile, mustJSON(ctxObj), history, question)
+	}
+	return fmt.Sprintf("Profile: %s\nContext: %s\n\nTask: %s", profile, mustJSON(ctxObj), question)
+}
+
+// conversationID prefers an explicit `conversation_id` argument and falls
+// back to the MCP session ID. It is "" for a sessionless call (REST, CLI)
+// without one.
+func conversationID(ctx context.Context, id string) string {
+	if id != "" {
+		return id
+	}
+	if sess := server.ClientSessionFromContext(ctx); sess != nil {
+		return sess.SessionID()
+	}
+	return ""
+}
+
+// conversationKey is the store key for a conversation: the ID scoped to the
+// calling principal, so nobody continues (or reads) another caller's
+// conversation by guessing its ID.
+func conversationKey(ctx context.Context, id string) string {
+	subject := ""
+	if p := auth.FromContext(ctx); p != nil {
+		subject = p.Subject
+	}
+	return fmt.Sprintf("%q/%s", subject, id) // quoted so no subject can forge another's prefix
+}
+
 func mustJSON(v any) string {
 	b, _ := json.MarshalIndent(v, "", "  ")
 	return string(b)
 }
+
+// llmResult wraps LLM text and records which model in the fallback chain
+// produced it, both as a trailing line and in the result _meta.
+func llmResult(text, model, template string) *mcp.CallToolResult {
+	res := mcp.NewToolResultText(text)
+	res.Content = append(res.Content, mcp.NewTextContent("model: "+model))
+	res.Meta = mcp.NewMetaFromMap(map[string]any{"model": model, "template": template})
+	return res
+}
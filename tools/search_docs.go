@@ -0,0 +1,87 @@
+// Tool: SearchDocs
+// คำอธิบาย (ไทย): ค้นหาเอกสารมาตรฐานของโรงงาน (SOP, คู่มือการทำงาน, ข้อกำหนดคุณภาพ) จากโฟลเดอร์ภายใน และคืนข้อความที่เกี่ยวข้องพร้อมแหล่งอ้างอิง
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"mcp-bedrock-go/format"
+	"mcp-bedrock-go/retrieval"
+)
+
+func init() {
+	Register(Def[SearchInput]{
+		Tool: mcp.NewTool("search_docs",
+			mcp.WithDescription("Search plant SOPs, work instructions and quality specs"),
+			mcp.WithString("query", mcp.Required()),
+			withInteger("limit", mcp.Description("Maximum passages to return (default 5)"), mcp.Min(1), mcp.Max(50), mcp.DefaultNumber(5)),
+			mcp.WithOutputSchema[DocPassages]()),
+		Needs:  func(d Deps) bool { return d.Docs != nil },
+		Routes: []Route{{Method: http.MethodGet, Path: "/docs/search"}},
+		Handler: func(d Deps) mcp.TypedToolHandlerFunc[SearchInput] {
+			return SearchDocs(d.Docs)
+		},
+	})
+}
+
+// SearchInput are the arguments of search_docs and find_product.
+type SearchInput struct {
+	Query string `json:"query"`
+	Limit int    `json:"limit"`
+}
+
+// DocPassage is a passage of a plant document matching a query.
+type DocPassage struct {
+	Citation string  `json:"citation"`
+	Source   string  `json:"source"`
+	Heading  string  `json:"heading"`
+	Score    float64 `json:"score"`
+	Text     string  `json:"text"`
+}
+
+// DocPassages are the passages found, best first.
+type DocPassages struct {
+	Items []DocPassage `json:"items"`
+}
+
+// Input: query (string, required), limit (int, optional, default 5)
+// Output: JSON array of passages with source citation and score
+func SearchDocs(docs *retrieval.Index) mcp.TypedToolHandlerFunc[SearchInput] {
+	return func(ctx context.Context, req mcp.CallToolRequest, in SearchInput) (*mcp.CallToolResult, error) {
+		query, limit := in.Query, in.Limit
+
+		hits, err := docs.Search(ctx, query, limit)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("search error: %v", err)), nil
+		}
+
+		out := make([]DocPassage, 0, len(hits))
+		for _, h := range hits {
+			out = append(out, DocPassage{
+				Citation: h.Citation(),
+				Source:   h.Source,
+				Heading:  h.Heading,
+				Score:    h.Score,
+				Text:     h.Text,
+			})
+		}
+		return format.Result(out), nil
+	}
+}
+
+// DocsContext searches the plant documents for query and returns a prompt
+// section with numbered citations, or "" when nothing relevant is indexed.
+func DocsContext(ctx context.Context, docs *retrieval.Index, query string) string {
+	if docs == nil {
+		return ""
+	}
+	hits, err := docs.Search(ctx, query, 4)
+	if err != nil {
+		return ""
+	}
+	return retrieval.FormatContext(hits)
+}
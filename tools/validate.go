@@ -0,0 +1,351 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// FieldError is one argument that failed validation.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"` // required, type, enum, minimum, maximum, length, pattern, format
+	Message string `json:"message"`
+	Value   any    `json:"value,omitempty"`
+}
+
+// Validate is tool middleware that checks a call's arguments against the
+// registered tool's input schema before the handler runs. Values are coerced
+// to the declared type ("12" for an integer, "18/10/2026" for a date),
+// defaults are filled in, and invalid calls get one structured error listing
+// every problem. Tools not in the registry pass through untouched.
+func Validate(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		regMu.Lock()
+		e, ok := registry[req.Params.Name]
+		regMu.Unlock()
+		if !ok {
+			return next(ctx, req)
+		}
+		args, errs := ValidateArgs(e.tool, req.GetArguments())
+		if len(errs) > 0 {
+			logger.DebugContext(ctx, "invalid tool arguments", "tool", req.Params.Name, "errors", len(errs))
+			return validationResult(req.Params.Name, errs), nil
+		}
+		req.Params.Arguments = args
+		return next(ctx, req)
+	}
+}
+
+// ValidateArgs checks args against tool's input schema and returns a copy
+// with values coerced and defaults applied. Arguments the schema does not
+// declare are kept as they are.
+func ValidateArgs(tool mcp.Tool, args map[string]any) (map[string]any, []FieldError) {
+	out := make(map[string]any, len(args))
+	for k, v := range args {
+		out[k] = v
+	}
+	required := map[string]bool{}
+	for _, r := range tool.InputSchema.Required {
+		required[r] = true
+	}
+
+	var errs []FieldError
+	names := make([]string, 0, len(tool.InputSchema.Properties))
+	for name := range tool.InputSchema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		prop, _ := tool.InputSchema.Properties[name].(map[string]any)
+		v, present := out[name]
+		if present && isBlank(v) {
+			present = false
+		}
+		if !present {
+			delete(out, name)
+			if def, ok := prop["default"]; ok {
+				out[name] = def
+			} else if required[name] {
+				errs = append(errs, FieldError{Field: name, Code: "required", Message: "is required"})
+			}
+			continue
+		}
+		cv, ferrs := coerce(name, prop, v)
+		if len(ferrs) > 0 {
+			errs = append(errs, ferrs...)
+			continue
+		}
+		out[name] = cv
+	}
+	return out, errs
+}
+
+// isBlank reports whether v counts as not given: null or an empty string.
+func isBlank(v any) bool {
+	s, ok := v.(string)
+	return v == nil || ok && strings.TrimSpace(s) == ""
+}
+
+// coerce converts v to the property's type and checks its constraints.
+func coerce(field string, prop map[string]any, v any) (any, []FieldError) {
+	fail := func(code, format string, a ...any) (any, []FieldError) {
+		return nil, []FieldError{{Field: field, Code: code, Message: fmt.Sprintf(format, a...), Value: v}}
+	}
+
+	switch prop["type"] {
+	case "integer", "number":
+		f, ok := toNumber(v)
+		if !ok {
+			return fail("type", "must be a number")
+		}
+		if prop["type"] == "integer" && f != math.Trunc(f) {
+			return fail("type", "must be a whole number")
+		}
+		if min, ok := prop["minimum"].(float64); ok && f < min {
+			return fail("minimum", "must be at least %s", trimFloat(min))
+		}
+		if min, ok := prop["exclusiveMinimum"].(float64); ok && f <= min {
+			return fail("minimum", "must be greater than %s", trimFloat(min))
+		}
+		if max, ok := prop["maximum"].(float64); ok && f > max {
+			return fail("maximum", "must be at most %s", trimFloat(max))
+		}
+		return f, nil
+
+	case "boolean":
+		b, ok := toBool(v)
+		if !ok {
+			return fail("type", "must be true or false")
+		}
+		return b, nil
+
+	case "string":
+		var s string
+		switch t := v.(type) {
+		case string:
+			s = t
+		case float64:
+			s = trimFloat(t)
+		case json.Number:
+			s = t.String()
+		case bool:
+			s = strconv.FormatBool(t)
+		default:
+			return fail("type", "must be a string")
+		}
+		switch prop["format"] {
+		case "date":
+			d, ok := parseDate(s, time.Now().In(Location))
+			if !ok {
+				return fail("format", "must be a date such as 2026-10-18, 18/10/2026, 18 Oct 2026 or tomorrow")
+			}
+			s = d.Format(time.DateOnly)
+		case "date-time":
+			d, hasTime, ok := parseDateTime(s, time.Now().In(Location))
+			if !ok {
+				return fail("format", "must be a date or date and time such as 2026-10-18 14:00")
+			}
+			s = odooDateTime(d, hasTime)
+		}
+		if enum, ok := prop["enum"].([]string); ok {
+			match := ""
+			for _, e := range enum {
+				if strings.EqualFold(strings.TrimSpace(s), e) {
+					match = e
+					break
+				}
+			}
+			if match == "" {
+				return fail("enum", "must be one of %s", strings.Join(enum, ", "))
+			}
+			s = match
+		}
+		if n, ok := prop["minLength"].(int); ok && len([]rune(s)) < n {
+			return fail("length", "must be at least %d characters", n)
+		}
+		if n, ok := prop["maxLength"].(int); ok && len([]rune(s)) > n {
+			return fail("length", "must be at most %d characters", n)
+		}
+		if p, ok := prop["pattern"].(string); ok {
+			re, err := regexp.Compile(p)
+			if err == nil && !re.MatchString(s) {
+				return fail("pattern", "must match %s", p)
+			}
+		}
+		return s, nil
+
+	case "array":
+		items, ok := v.([]any)
+		if !ok {
+			items = []any{v} // a single value stands for a one-element list
+		}
+		itemProp, _ := prop["items"].(map[string]any)
+		out := make([]any, len(items))
+		var errs []FieldError
+		for i, it := range items {
+			cv, ferrs := coerce(fmt.Sprintf("%s[%d]", field, i), itemProp, it)
+			errs = append(errs, ferrs...)
+			out[i] = cv
+		}
+		if n, ok := prop["minItems"].(int); ok && len(out) < n {
+			errs = append(errs, FieldError{Field: field, Code: "length", Message: fmt.Sprintf("must have at least %d items", n)})
+		}
+		if n, ok := prop["maxItems"].(int); ok && len(out) > n {
+			errs = append(errs, FieldError{Field: field, Code: "length", Message: fmt.Sprintf("must have at most %d items", n)})
+		}
+		return out, errs
+	}
+	return v, nil
+}
+
+// toNumber accepts JSON numbers and numeric strings, with thousands
+// separators ("1,000") allowed.
+func toNumber(v any) (float64, bool) {
+	switch t := v.(type) {
+	case float64:
+		return t, true
+	case int:
+		return float64(t), true
+	case int64:
+		return float64(t), true
+	case json.Number:
+		f, err := t.Float64()
+		return f, err == nil
+	case string:
+		s := strings.NewReplacer(",", "", "_", "", " ", "").Replace(t)
+		f, err := strconv.ParseFloat(s, 64)
+		return f, err == nil && !math.IsNaN(f) && !math.IsInf(f, 0)
+	}
+	return 0, false
+}
+
+func toBool(v any) (bool, bool) {
+	switch t := v.(type) {
+	case bool:
+		return t, true
+	case float64:
+		return t != 0, t == 0 || t == 1
+	case string:
+		switch strings.ToLower(strings.TrimSpace(t)) {
+		case "true", "t", "yes", "y", "on", "1":
+			return true, true
+		case "false", "f", "no", "n", "off", "0":
+			return false, true
+		}
+	}
+	return false, false
+}
+
+func trimFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// Location is the plant's time zone. Relative dates (today, tomorrow) and
+// times of day given without an offset are read in it.
+var Location = time.Local
+
+// dateLayouts are the date spellings accepted for format "date". Numeric
+// dates with slashes or dots are day first, as written in the plant.
+var dateLayouts = []string{
+	time.DateOnly, "2006/01/02", "20060102",
+	"2/1/2006", "2.1.2006", "2-1-2006",
+	"2 Jan 2006", "2 January 2006", "Jan 2 2006", "Jan 2, 2006", "January 2 2006", "January 2, 2006",
+}
+
+// dateTimeLayouts carry a time of day; they are tried before dateLayouts
+// for format "date-time".
+var dateTimeLayouts = []string{
+	time.DateTime, "2006-01-02 15:04", "2006-01-02T15:04:05", "2006-01-02T15:04",
+	"2/1/2006 15:04", "2 Jan 2006 15:04",
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+}
+
+// parseDate reads an absolute date in one of dateLayouts or a relative one:
+// today, tomorrow, yesterday, "in 3 days", "2 days ago" or a weekday name
+// (its next occurrence after today).
+func parseDate(s string, now time.Time) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	low := strings.ToLower(s)
+	switch low {
+	case "today", "now":
+		return today, true
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), true
+	}
+	var n int
+	if _, err := fmt.Sscanf(low, "in %d days", &n); err == nil {
+		return today.AddDate(0, 0, n), true
+	}
+	if _, err := fmt.Sscanf(low, "%d days ago", &n); err == nil {
+		return today.AddDate(0, 0, -n), true
+	}
+	if wd, ok := weekdays[strings.TrimPrefix(low, "next ")]; ok {
+		days := (int(wd)-int(today.Weekday())+6)%7 + 1
+		return today.AddDate(0, 0, days), true
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.In(now.Location()), true
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseDateTime reads a date with or without a time of day. A time without
+// an offset is wall-clock time in now's location, the plant's; a plain date
+// is its midnight there. hasTime reports whether a time of day was given.
+func parseDateTime(s string, now time.Time) (t time.Time, hasTime, ok bool) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, true, true
+	}
+	for _, layout := range dateTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, true, true
+		}
+	}
+	if d, ok := parseDate(s, now); ok {
+		return d, false, true
+	}
+	return time.Time{}, false, false
+}
+
+// odooDateTime writes a time as Odoo stores datetimes, "YYYY-MM-DD HH:MM:SS"
+// in UTC. A plain date stays "YYYY-MM-DD" so tools can tell a day from an
+// instant.
+func odooDateTime(t time.Time, hasTime bool) string {
+	if !hasTime {
+		return t.Format(time.DateOnly)
+	}
+	return t.UTC().Format(time.DateTime)
+}
+
+// validationResult is the uniform error returned for invalid arguments.
+func validationResult(tool string, errs []FieldError) *mcp.CallToolResult {
+	b, _ := json.MarshalIndent(map[string]any{
+		"error":  "invalid arguments",
+		"tool":   tool,
+		"errors": errs,
+	}, "", "  ")
+	return mcp.NewToolResultError(string(b))
+}
@@ -0,0 +1,223 @@
+package tools
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata" // Europe/Berlin without the system zone database
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// inPlant sets the plant's time zone for one test.
+func inPlant(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := Location
+	Location = loc
+	t.Cleanup(func() { Location = old })
+	return loc
+}
+
+func TestParseDate(t *testing.T) {
+	loc := inPlant(t, "Europe/Berlin")
+	now := time.Date(2026, 10, 18, 23, 30, 0, 0, loc) // a Sunday, late evening
+	tests := []struct {
+		in   string
+		want string // "" when rejected
+	}{
+		{"2026-10-20", "2026-10-20"},
+		{"2026/10/20", "2026-10-20"},
+		{"20261020", "2026-10-20"},
+		{"20/10/2026", "2026-10-20"},
+		{"2/1/2026", "2026-01-02"}, // day first
+		{"02/01/2026", "2026-01-02"},
+		{"3.4.2026", "2026-04-03"},
+		{"3-4-2026", "2026-04-03"},
+		{"10/13/2026", ""}, // month first is not read
+		{"31/2/2026", ""},
+		{"20 Oct 2026", "2026-10-20"},
+		{"20 October 2026", "2026-10-20"},
+		{"Oct 20, 2026", "2026-10-20"},
+		{"January 2 2026", "2026-01-02"},
+		{"today", "2026-10-18"},
+		{"  Today ", "2026-10-18"},
+		{"now", "2026-10-18"},
+		{"tomorrow", "2026-10-19"},
+		{"yesterday", "2026-10-17"},
+		{"in 3 days", "2026-10-21"},
+		{"2 days ago", "2026-10-16"},
+		{"friday", "2026-10-23"},
+		{"next friday", "2026-10-23"},
+		{"Monday", "2026-10-19"},
+		{"sunday", "2026-10-25"},               // the next one, not today
+		{"2026-10-18T22:30:00Z", "2026-10-19"}, // already the 19th in the plant
+		{"2026-10-18T22:30:00+00:00", "2026-10-19"},
+		{"soon", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		got, ok := parseDate(tt.in, now)
+		switch {
+		case tt.want == "" && ok:
+			t.Errorf("parseDate(%q) = %s, want rejected", tt.in, got.Format(time.DateOnly))
+		case tt.want != "" && !ok:
+			t.Errorf("parseDate(%q) rejected, want %s", tt.in, tt.want)
+		case ok && got.Format(time.DateOnly) != tt.want:
+			t.Errorf("parseDate(%q) = %s, want %s", tt.in, got.Format(time.DateOnly), tt.want)
+		}
+	}
+}
+
+// TestDateTimeToOdoo checks that wall-clock times are read in the plant's
+// zone and stored in UTC, across both daylight saving changes of 2026.
+func TestDateTimeToOdoo(t *testing.T) {
+	loc := inPlant(t, "Europe/Berlin")
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, loc)
+	tests := []struct {
+		in, want string
+	}{
+		{"2026-10-18 14:00", "2026-10-18 12:00:00"}, // CEST, UTC+2
+		{"2026-12-01 14:00", "2026-12-01 13:00:00"}, // CET, UTC+1
+		{"2026-10-18T14:00:05", "2026-10-18 12:00:05"},
+		{"18/10/2026 14:00", "2026-10-18 12:00:00"},
+		{"18 Oct 2026 00:30", "2026-10-17 22:30:00"}, // the day before in UTC
+		{"2026-10-18T14:00:00Z", "2026-10-18 14:00:00"},
+		{"2026-10-18T14:00:00-05:00", "2026-10-18 19:00:00"},
+		// spring forward: 02:00-03:00 does not exist and reads as CEST
+		{"2026-03-29 01:30", "2026-03-29 00:30:00"},
+		{"2026-03-29 02:30", "2026-03-29 01:30:00"},
+		{"2026-03-29 03:30", "2026-03-29 01:30:00"},
+		// fall back: 02:00-03:00 happens twice and reads as CET, the second
+		{"2026-10-25 01:30", "2026-10-24 23:30:00"},
+		{"2026-10-25 02:30", "2026-10-25 01:30:00"},
+		{"2026-10-25 03:30", "2026-10-25 02:30:00"},
+		// a plain date stays a day
+		{"2026-10-25", "2026-10-25"},
+		{"tomorrow", "2026-10-19"},
+	}
+	for _, tt := range tests {
+		d, hasTime, ok := parseDateTime(tt.in, now)
+		if !ok {
+			t.Errorf("parseDateTime(%q) rejected", tt.in)
+			continue
+		}
+		if got := odooDateTime(d, hasTime); got != tt.want {
+			t.Errorf("%q stored as %q, want %q", tt.in, got, tt.want)
+		}
+	}
+	if _, _, ok := parseDateTime("25:00", now); ok {
+		t.Error("parseDateTime accepted 25:00")
+	}
+}
+
+func TestCoerce(t *testing.T) {
+	inPlant(t, "UTC")
+	str := func(extra map[string]any) map[string]any {
+		p := map[string]any{"type": "string"}
+		for k, v := range extra {
+			p[k] = v
+		}
+		return p
+	}
+	tests := []struct {
+		name string
+		prop map[string]any
+		in   any
+		want any
+		code string // error code, "" when valid
+	}{
+		{"integer", map[string]any{"type": "integer"}, 12.0, 12.0, ""},
+		{"padded", map[string]any{"type": "integer"}, " 12 ", 12.0, ""},
+		{"integer from string", map[string]any{"type": "integer"}, "12", 12.0, ""},
+		{"thousands", map[string]any{"type": "integer"}, "1,000", 1000.0, ""},
+		{"thousands underscore", map[string]any{"type": "integer"}, "1_000", 1000.0, ""},
+		{"thousands space", map[string]any{"type": "number"}, "12 500.5", 12500.5, ""},
+		{"fraction for integer", map[string]any{"type": "integer"}, 1.5, nil, "type"},
+		{"not a number", map[string]any{"type": "number"}, "lots", nil, "type"},
+		{"NaN", map[string]any{"type": "number"}, "NaN", nil, "type"},
+		{"int", map[string]any{"type": "number"}, 7, 7.0, ""},
+		{"minimum", map[string]any{"type": "number", "minimum": 1.0}, "0", nil, "minimum"},
+		{"exclusive minimum", map[string]any{"type": "number", "exclusiveMinimum": 0.0}, 0.0, nil, "minimum"},
+		{"maximum", map[string]any{"type": "number", "maximum": 10.0}, "10.5", nil, "maximum"},
+		{"at maximum", map[string]any{"type": "number", "maximum": 10.0}, "10", 10.0, ""},
+		{"boolean", map[string]any{"type": "boolean"}, "Yes", true, ""},
+		{"boolean off", map[string]any{"type": "boolean"}, "off", false, ""},
+		{"boolean number", map[string]any{"type": "boolean"}, 1.0, true, ""},
+		{"boolean two", map[string]any{"type": "boolean"}, 2.0, nil, "type"},
+		{"boolean word", map[string]any{"type": "boolean"}, "maybe", nil, "type"},
+		{"string from number", str(nil), 1000.0, "1000", ""},
+		{"string from bool", str(nil), true, "true", ""},
+		{"string from object", str(nil), map[string]any{}, nil, "type"},
+		{"enum case", str(map[string]any{"enum": []string{"product", "consu"}}), " Consu ", "consu", ""},
+		{"enum miss", str(map[string]any{"enum": []string{"product", "consu"}}), "service", nil, "enum"},
+		{"date", str(map[string]any{"format": "date"}), "2/1/2026", "2026-01-02", ""},
+		{"date compact", str(map[string]any{"format": "date"}), "20260102", "2026-01-02", ""},
+		{"date month first", str(map[string]any{"format": "date"}), "1/13/2026", nil, "format"},
+		{"date-time", str(map[string]any{"format": "date-time"}), "2026-01-02 08:00", "2026-01-02 08:00:00", ""},
+		{"date-time day", str(map[string]any{"format": "date-time"}), "2 Jan 2026", "2026-01-02", ""},
+		{"date-time bad", str(map[string]any{"format": "date-time"}), "noonish", nil, "format"},
+		{"min length", str(map[string]any{"minLength": 3}), "ab", nil, "length"},
+		{"max length in runes", str(map[string]any{"maxLength": 3}), "äöü", "äöü", ""},
+		{"max length", str(map[string]any{"maxLength": 3}), "abcd", nil, "length"},
+		{"pattern", str(map[string]any{"pattern": "^[A-Z]+-\\d+$"}), "MO-12", "MO-12", ""},
+		{"pattern miss", str(map[string]any{"pattern": "^[A-Z]+-\\d+$"}), "mo12", nil, "pattern"},
+		{"array", map[string]any{"type": "array", "items": map[string]any{"type": "integer"}}, []any{"1", "2,000"}, []any{1.0, 2000.0}, ""},
+		{"single value wrapped", map[string]any{"type": "array", "items": map[string]any{"type": "integer"}}, "3", []any{3.0}, ""},
+		{"array item error", map[string]any{"type": "array", "items": map[string]any{"type": "integer"}}, []any{"1", "x"}, nil, "type"},
+		{"max items", map[string]any{"type": "array", "maxItems": 1, "items": map[string]any{"type": "string"}}, []any{"a", "b"}, nil, "length"},
+		{"untyped", map[string]any{}, map[string]any{"k": 1.0}, map[string]any{"k": 1.0}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, errs := coerce("f", tt.prop, tt.in)
+			if tt.code != "" {
+				if len(errs) == 0 {
+					t.Fatalf("got %#v, want a %s error", got, tt.code)
+				}
+				if errs[0].Code != tt.code {
+					t.Fatalf("error %+v, want code %s", errs[0], tt.code)
+				}
+				return
+			}
+			if len(errs) > 0 {
+				t.Fatalf("errors %+v", errs)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateArgs(t *testing.T) {
+	tool := mcp.NewTool("t",
+		mcp.WithString("code", mcp.Required()),
+		mcp.WithNumber("qty", mcp.Required(), mcp.Min(1)),
+		mcp.WithString("kind", mcp.Enum("a", "b"), mcp.DefaultString("a")),
+		mcp.WithBoolean("flag"),
+	)
+
+	args, errs := ValidateArgs(tool, map[string]any{"code": "X", "qty": "1,000", "kind": " ", "extra": 1.0})
+	if len(errs) > 0 {
+		t.Fatalf("errors %+v", errs)
+	}
+	want := map[string]any{"code": "X", "qty": 1000.0, "kind": "a", "extra": 1.0}
+	if !reflect.DeepEqual(args, want) {
+		t.Fatalf("got %v, want %v", args, want)
+	}
+
+	// every problem is reported at once, in field order
+	_, errs = ValidateArgs(tool, map[string]any{"code": nil, "qty": "0", "flag": "perhaps"})
+	var got []string
+	for _, e := range errs {
+		got = append(got, e.Field+":"+e.Code)
+	}
+	if strings.Join(got, " ") != "code:required flag:type qty:minimum" {
+		t.Fatalf("errors %v", got)
+	}
+}
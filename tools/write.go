@@ -0,0 +1,53 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"mcp-bedrock-go/audit"
+	"mcp-bedrock-go/format"
+)
+
+// WriteOptions are the server-wide settings shared by tools that write to Odoo.
+type WriteOptions struct {
+	// DryRun makes every write tool validate and report instead of calling
+	// Create, whatever the caller passes as dry_run.
+	DryRun bool
+}
+
+// dryRun reports whether this call must not write, given its dry_run
+// argument.
+func (w WriteOptions) dryRun(requested bool) bool {
+	return w.DryRun || requested
+}
+
+// createdResult returns resp as the result and names the record created in
+// the result metadata, where the audit log picks it up.
+func createdResult(resp map[string]any, model string, id int, name string) *mcp.CallToolResult {
+	res := format.Result(resp)
+	res.Meta = mcp.NewMetaFromMap(map[string]any{
+		"records": []audit.Record{{Model: model, ID: id, Name: name}},
+	})
+	return res
+}
+
+// dryRunResult returns the exact vals a write tool would have sent to
+// Create, plus any warnings found while validating. The result metadata
+// marks it as a dry run, so it is never replayed as a real write.
+func dryRunResult(model string, vals map[string]any, warnings []string, extra map[string]any) *mcp.CallToolResult {
+	if warnings == nil {
+		warnings = []string{}
+	}
+	resp := map[string]any{
+		"dry_run":  true,
+		"model":    model,
+		"vals":     vals,
+		"warnings": warnings,
+		"message":  "Dry run: nothing was written to Odoo",
+	}
+	for k, v := range extra {
+		resp[k] = v
+	}
+	res := format.Result(resp)
+	res.Meta = mcp.NewMetaFromMap(map[string]any{"dry_run": true})
+	return res
+}
@@ -0,0 +1,141 @@
+package transport
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Subscriber handles resources/subscribe and resources/unsubscribe. The MCP
+// library advertises the subscribe capability but does not route these
+// methods, so the transports intercept them. ctx carries the caller's
+// principal, as it would for a resources/read.
+type Subscriber interface {
+	Subscribe(ctx context.Context, sessionID, uri string) error
+	Unsubscribe(sessionID, uri string)
+}
+
+const (
+	methodSubscribe   = "resources/subscribe"
+	methodUnsubscribe = "resources/unsubscribe"
+)
+
+// rewrite records a (un)subscription and turns the message into a ping with
+// the same id, whose empty result is exactly the reply the client expects.
+// A refused subscription (unknown URI, or one the caller may not read)
+// becomes a resources/read of it, so the client gets the error a read would
+// give. Each message of a batch is rewritten; other messages are returned
+// unchanged.
+func rewrite(ctx context.Context, msg []byte, sessionID string, sub Subscriber) []byte {
+	if sub == nil || !bytes.Contains(msg, []byte("resources/")) {
+		return msg
+	}
+	if trimmed := bytes.TrimSpace(msg); len(trimmed) > 0 && trimmed[0] == '[' {
+		var batch []json.RawMessage
+		if json.Unmarshal(trimmed, &batch) != nil {
+			return msg
+		}
+		for i, m := range batch {
+			batch[i] = rewrite(ctx, m, sessionID, sub)
+		}
+		out, err := json.Marshal(batch)
+		if err != nil {
+			return msg
+		}
+		return out
+	}
+
+	var req struct {
+		JSONRPC string `json:"jsonrpc"`
+		ID      any    `json:"id,omitempty"`
+		Method  string `json:"method"`
+		Params  struct {
+			URI string `json:"uri"`
+		} `json:"params"`
+	}
+	if json.Unmarshal(msg, &req) != nil {
+		return msg
+	}
+	method := string(mcp.MethodPing)
+	switch req.Method {
+	case methodSubscribe:
+		if err := sub.Subscribe(ctx, sessionID, req.Params.URI); err != nil {
+			method = string(mcp.MethodResourcesRead)
+		}
+	case methodUnsubscribe:
+		sub.Unsubscribe(sessionID, req.Params.URI)
+	default:
+		return msg
+	}
+	out, err := json.Marshal(map[string]any{
+		"jsonrpc": req.JSONRPC,
+		"id":      req.ID,
+		"method":  method,
+		"params":  map[string]any{"uri": req.Params.URI},
+	})
+	if err != nil {
+		return msg
+	}
+	return out
+}
+
+// subscribeHandler applies rewrite to POSTed JSON-RPC messages. The session
+// comes from the Streamable HTTP header or the SSE sessionId query.
+func subscribeHandler(next http.Handler, sub Subscriber) http.Handler {
+	if sub == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.Body != nil {
+			body, err := io.ReadAll(r.Body)
+			r.Body.Close()
+			if err != nil {
+				http.Error(w, "read body: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			sessionID := r.Header.Get("Mcp-Session-Id")
+			if sessionID == "" {
+				sessionID = r.URL.Query().Get("sessionId")
+			}
+			body = rewrite(r.Context(), body, sessionID, sub)
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			r.ContentLength = int64(len(body))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// stdioSessionID is the fixed session ID the library gives the stdio client.
+const stdioSessionID = "stdio"
+
+// subscribeReader applies rewrite to each line read from stdio; ctx is the
+// stdio session's.
+func subscribeReader(ctx context.Context, in io.Reader, sub Subscriber) io.Reader {
+	if sub == nil {
+		return in
+	}
+	pr, pw := io.Pipe()
+	go func() {
+		br := bufio.NewReader(in)
+		for {
+			line, err := br.ReadBytes('\n')
+			if len(line) > 0 {
+				trimmed := bytes.TrimRight(line, "\r\n")
+				out := rewrite(ctx, trimmed, stdioSessionID, sub)
+				if _, werr := pw.Write(append(out, '\n')); werr != nil {
+					return
+				}
+			}
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+	}()
+	return pr
+}
@@ -0,0 +1,80 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type ctxKey struct{}
+
+// denySubscriber refuses URIs ending in "secret" and callers without a
+// subject in ctx, and records what it accepted.
+type denySubscriber struct{ subscribed []string }
+
+func (d *denySubscriber) Subscribe(ctx context.Context, sessionID, uri string) error {
+	if ctx.Value(ctxKey{}) == nil || strings.HasSuffix(uri, "secret") {
+		return errors.New("forbidden")
+	}
+	d.subscribed = append(d.subscribed, sessionID+" "+uri)
+	return nil
+}
+
+func (d *denySubscriber) Unsubscribe(sessionID, uri string) {}
+
+func methods(t *testing.T, msg []byte) []string {
+	t.Helper()
+	var batch []struct {
+		Method string `json:"method"`
+	}
+	if msg[0] != '[' {
+		msg = append(append([]byte("["), msg...), ']')
+	}
+	if err := json.Unmarshal(msg, &batch); err != nil {
+		t.Fatalf("rewritten message is not JSON: %v: %s", err, msg)
+	}
+	out := make([]string, len(batch))
+	for i, m := range batch {
+		out[i] = m.Method
+	}
+	return out
+}
+
+func TestRewrite(t *testing.T) {
+	ctx := context.WithValue(context.Background(), ctxKey{}, "alice")
+	sub := &denySubscriber{}
+	tests := []struct {
+		name string
+		ctx  context.Context
+		msg  string
+		want []string
+	}{
+		{"subscribe", ctx, `{"jsonrpc":"2.0","id":1,"method":"resources/subscribe","params":{"uri":"odoo://a"}}`, []string{"ping"}},
+		{"refused", ctx, `{"jsonrpc":"2.0","id":2,"method":"resources/subscribe","params":{"uri":"odoo://secret"}}`, []string{"resources/read"}},
+		{"anonymous", context.Background(), `{"jsonrpc":"2.0","id":3,"method":"resources/subscribe","params":{"uri":"odoo://b"}}`, []string{"resources/read"}},
+		{"other", ctx, `{"jsonrpc":"2.0","id":4,"method":"resources/read","params":{"uri":"odoo://a"}}`, []string{"resources/read"}},
+		{"batch", ctx, `[{"jsonrpc":"2.0","id":5,"method":"resources/subscribe","params":{"uri":"odoo://secret"}},` +
+			`{"jsonrpc":"2.0","id":6,"method":"tools/list"},` +
+			`{"jsonrpc":"2.0","id":7,"method":"resources/subscribe","params":{"uri":"odoo://c"}}]`,
+			[]string{"resources/read", "tools/list", "ping"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := methods(t, rewrite(tt.ctx, []byte(tt.msg), "s1", sub))
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+	want := []string{"s1 odoo://a", "s1 odoo://c"}
+	if len(sub.subscribed) != len(want) || sub.subscribed[0] != want[0] || sub.subscribed[1] != want[1] {
+		t.Fatalf("subscribed %v, want %v", sub.subscribed, want)
+	}
+}
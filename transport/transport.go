@@ -0,0 +1,212 @@
+// Package transport exposes an MCP server over stdio, SSE and Streamable
+// HTTP, alone or in combination.
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+
+	"mcp-bedrock-go/internal/logging"
+)
+
+var logger = logging.For("transport")
+
+// Transport names as used in config and on the command line.
+const (
+	Stdio = "stdio"
+	SSE   = "sse"
+	HTTP  = "http" // Streamable HTTP
+)
+
+// Names lists every supported transport.
+var Names = []string{Stdio, SSE, HTTP}
+
+// Endpoint paths mounted on the HTTP listener.
+const (
+	SSEPath     = "/sse"
+	MessagePath = "/message"
+	HTTPPath    = "/mcp"
+)
+
+// Options selects transports and how the HTTP listener is set up.
+type Options struct {
+	Transports []string
+	Listen     string
+	// Mux receives the MCP endpoints; callers add their own routes
+	// (status, health) to it. A new mux is used when nil.
+	Mux *http.ServeMux
+	// Shutdown bounds graceful HTTP shutdown after ctx is cancelled.
+	Shutdown time.Duration
+	// Middleware wraps the whole HTTP handler (authentication).
+	Middleware func(http.Handler) http.Handler
+	// Subscriptions receives resources/subscribe and unsubscribe requests
+	// on every transport; nil leaves them to the library.
+	Subscriptions Subscriber
+	// OnState is told when a transport starts serving (up) and when it stops,
+	// with the error that stopped it if any. Used by readiness checks.
+	OnState func(name string, up bool, err error)
+	// StdioContext customises the context of the stdio session, e.g. to
+	// attach the local principal.
+	StdioContext server.StdioContextFunc
+	// Stdin/Stdout override the stdio streams (tests, embedding).
+	Stdin  io.Reader
+	Stdout io.Writer
+}
+
+// HTTPEnabled reports whether any HTTP-based transport is selected.
+func (o Options) HTTPEnabled() bool {
+	return has(o.Transports, SSE) || has(o.Transports, HTTP)
+}
+
+// Mount registers the HTTP transports selected in opts on mux.
+func Mount(mux *http.ServeMux, s *server.MCPServer, opts Options) {
+	if has(opts.Transports, SSE) {
+		sse := server.NewSSEServer(s,
+			server.WithSSEEndpoint(SSEPath),
+			server.WithMessageEndpoint(MessagePath))
+		mux.Handle(SSEPath, sse.SSEHandler())
+		mux.Handle(MessagePath, subscribeHandler(sse.MessageHandler(), opts.Subscriptions))
+	}
+	if has(opts.Transports, HTTP) {
+		h := server.NewStreamableHTTPServer(s, server.WithEndpointPath(HTTPPath))
+		mux.Handle(HTTPPath, subscribeHandler(h, opts.Subscriptions))
+	}
+}
+
+// Serve runs the selected transports until ctx is cancelled or one of them
+// fails. Stdio ending (EOF) stops Serve when it is the only transport.
+func Serve(ctx context.Context, s *server.MCPServer, opts Options) error {
+	if len(opts.Transports) == 0 {
+		return fmt.Errorf("no transport selected")
+	}
+	for _, t := range opts.Transports {
+		if !has(Names, t) {
+			return fmt.Errorf("unknown transport %q", t)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	state := func(name string, up bool, err error) {
+		if opts.OnState != nil {
+			opts.OnState(name, up, err)
+		}
+	}
+
+	var (
+		wg   sync.WaitGroup
+		once sync.Once
+		ferr error
+	)
+	fail := func(err error) {
+		once.Do(func() { ferr = err })
+		cancel()
+	}
+
+	if has(opts.Transports, Stdio) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var in io.Reader = os.Stdin
+			var out io.Writer = os.Stdout
+			if opts.Stdin != nil {
+				in = opts.Stdin
+			}
+			if opts.Stdout != nil {
+				out = opts.Stdout
+			}
+			stdio := server.NewStdioServer(s)
+			sctx := ctx
+			if opts.StdioContext != nil {
+				stdio.SetContextFunc(opts.StdioContext)
+				sctx = opts.StdioContext(ctx)
+			}
+			state(Stdio, true, nil)
+			err := stdio.Listen(ctx, subscribeReader(sctx, in, opts.Subscriptions), out)
+			state(Stdio, false, err)
+			switch {
+			case err != nil && !errors.Is(err, context.Canceled):
+				fail(fmt.Errorf("stdio: %w", err))
+			case !opts.HTTPEnabled():
+				// stdio alone: client closed stdin, we are done
+				cancel()
+			}
+		}()
+	}
+
+	if opts.HTTPEnabled() {
+		mux := opts.Mux
+		if mux == nil {
+			mux = http.NewServeMux()
+		}
+		Mount(mux, s, opts)
+
+		ln, err := net.Listen("tcp", opts.Listen)
+		if err != nil {
+			return fmt.Errorf("listen %s: %w", opts.Listen, err)
+		}
+		var h http.Handler = mux
+		if opts.Middleware != nil {
+			h = opts.Middleware(h)
+		}
+		srv := &http.Server{Handler: h}
+		logger.InfoCtxf(ctx, "MCP HTTP listening on %s (transports: %v)", ln.Addr(), opts.Transports)
+
+		httpNames := []string{}
+		for _, t := range opts.Transports {
+			if t != Stdio {
+				httpNames = append(httpNames, t)
+			}
+		}
+		for _, t := range httpNames {
+			state(t, true, nil)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := srv.Serve(ln)
+			if errors.Is(err, http.ErrServerClosed) {
+				err = nil
+			}
+			for _, t := range httpNames {
+				state(t, false, err)
+			}
+			if err != nil {
+				fail(fmt.Errorf("http: %w", err))
+			}
+		}()
+		go func() {
+			<-ctx.Done()
+			timeout := opts.Shutdown
+			if timeout <= 0 {
+				timeout = 10 * time.Second
+			}
+			sctx, scancel := context.WithTimeout(context.Background(), timeout)
+			defer scancel()
+			_ = srv.Shutdown(sctx)
+		}()
+	}
+
+	wg.Wait()
+	return ferr
+}
+
+func has(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
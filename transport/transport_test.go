@@ -0,0 +1,300 @@
+package transport
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	mcptransport "github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// newServer returns an MCP server with one stub tool, echo, that answers
+// "echo: <text>".
+func newServer() *server.MCPServer {
+	s := server.NewMCPServer("test", "1.0.0", server.WithToolCapabilities(false))
+	s.AddTool(mcp.NewTool("echo", mcp.WithString("text", mcp.Required())),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("echo: " + req.GetString("text", "")), nil
+		})
+	return s
+}
+
+// roundTrip initializes c and calls echo through it.
+func roundTrip(t *testing.T, ctx context.Context, c *client.Client) {
+	t.Helper()
+	if err := c.Start(ctx); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	var init mcp.InitializeRequest
+	init.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	init.Params.ClientInfo = mcp.Implementation{Name: "transport-test", Version: "1.0.0"}
+	if _, err := c.Initialize(ctx, init); err != nil {
+		t.Fatalf("initialize: %v", err)
+	}
+	var req mcp.CallToolRequest
+	req.Params.Name = "echo"
+	req.Params.Arguments = map[string]any{"text": "hello"}
+	res, err := c.CallTool(ctx, req)
+	if err != nil {
+		t.Fatalf("call echo: %v", err)
+	}
+	if len(res.Content) != 1 {
+		t.Fatalf("got %d content blocks, want 1", len(res.Content))
+	}
+	if tc, ok := res.Content[0].(mcp.TextContent); !ok || tc.Text != "echo: hello" {
+		t.Fatalf("got %#v, want text \"echo: hello\"", res.Content[0])
+	}
+}
+
+// serveHTTP starts Serve with the given HTTP transport on a free local port
+// and returns its base URL once the transport reports it is up.
+func serveHTTP(t *testing.T, ctx context.Context, name string, mw func(http.Handler) http.Handler) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	up := make(chan struct{}, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- Serve(ctx, newServer(), Options{
+			Transports: []string{name},
+			Listen:     addr,
+			Middleware: mw,
+			Shutdown:   time.Second,
+			OnState: func(_ string, isUp bool, _ error) {
+				if isUp {
+					up <- struct{}{}
+				}
+			},
+		})
+	}()
+	t.Cleanup(func() {
+		if err := <-done; err != nil {
+			t.Errorf("serve: %v", err)
+		}
+	})
+	select {
+	case <-up:
+	case err := <-done:
+		t.Fatalf("serve: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("transport did not come up")
+	}
+	return "http://" + addr
+}
+
+func TestStdio(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	clientIn, serverOut := io.Pipe()
+	serverIn, clientOut := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- Serve(ctx, newServer(), Options{Transports: []string{Stdio}, Stdin: serverIn, Stdout: serverOut})
+	}()
+
+	c := client.NewClient(mcptransport.NewIO(clientIn, clientOut, nil))
+	roundTrip(t, ctx, c)
+
+	// closing stdin ends a stdio-only server
+	clientOut.Close()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("serve: %v", err)
+		}
+	case <-ctx.Done():
+		t.Fatal("Serve did not return after stdin closed")
+	}
+}
+
+func TestSSE(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	base := serveHTTP(t, ctx, SSE, nil)
+
+	c, err := client.NewSSEMCPClient(base + SSEPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	roundTrip(t, ctx, c)
+}
+
+func TestStreamableHTTP(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	base := serveHTTP(t, ctx, HTTP, nil)
+
+	c, err := client.NewStreamableHttpClient(base + HTTPPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	roundTrip(t, ctx, c)
+}
+
+func TestMiddleware(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	mw := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-API-Key") != "secret" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+	base := serveHTTP(t, ctx, HTTP, mw)
+
+	resp, err := http.Post(base+HTTPPath, "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("without a key: status %d, want 401", resp.StatusCode)
+	}
+
+	c, err := client.NewStreamableHttpClient(base+HTTPPath,
+		mcptransport.WithHTTPHeaders(map[string]string{"X-API-Key": "secret"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	roundTrip(t, ctx, c)
+}
+
+// states records OnState calls.
+type states struct {
+	mu  sync.Mutex
+	log []string
+	up  map[string]bool
+}
+
+func (s *states) on(name string, up bool, _ error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.up == nil {
+		s.up = map[string]bool{}
+	}
+	s.up[name] = up
+	state := "down"
+	if up {
+		state = "up"
+	}
+	s.log = append(s.log, name+" "+state)
+}
+
+func (s *states) isUp(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.up[name]
+}
+
+// waitFor polls cond until it holds or a second passes.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); !cond(); {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestStdioAndHTTP serves stdio and Streamable HTTP together: closing stdin
+// only takes stdio down, and cancelling the context stops both.
+func TestStdioAndHTTP(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	srvCtx, stop := context.WithCancel(ctx)
+	defer stop()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	var st states
+	clientIn, serverOut := io.Pipe()
+	serverIn, clientOut := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- Serve(srvCtx, newServer(), Options{
+			Transports: []string{Stdio, HTTP},
+			Listen:     addr,
+			Stdin:      serverIn,
+			Stdout:     serverOut,
+			Shutdown:   time.Second,
+			OnState:    st.on,
+		})
+	}()
+	waitFor(t, "both transports up", func() bool { return st.isUp(Stdio) && st.isUp(HTTP) })
+
+	roundTrip(t, ctx, client.NewClient(mcptransport.NewIO(clientIn, clientOut, nil)))
+	hc, err := client.NewStreamableHttpClient("http://" + addr + HTTPPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hc.Close()
+	roundTrip(t, ctx, hc)
+
+	// the stdio client leaving does not stop HTTP
+	clientOut.Close()
+	waitFor(t, "stdio down", func() bool { return !st.isUp(Stdio) })
+	select {
+	case err := <-done:
+		t.Fatalf("Serve returned after stdin closed: %v", err)
+	default:
+	}
+	if !st.isUp(HTTP) {
+		t.Fatal("http went down with stdio")
+	}
+	hc2, err := client.NewStreamableHttpClient("http://" + addr + HTTPPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hc2.Close()
+	roundTrip(t, ctx, hc2)
+
+	stop()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("serve: %v", err)
+		}
+	case <-ctx.Done():
+		t.Fatal("Serve did not return after cancel")
+	}
+	if st.isUp(HTTP) || st.isUp(Stdio) {
+		t.Fatalf("transports still up after shutdown: %v", st.log)
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	want := map[string]int{"stdio up": 1, "stdio down": 1, "http up": 1, "http down": 1}
+	got := map[string]int{}
+	for _, e := range st.log {
+		got[e]++
+	}
+	for e, n := range want {
+		if got[e] != n {
+			t.Fatalf("OnState %q reported %d times, want %d: %v", e, got[e], n, st.log)
+		}
+	}
+}